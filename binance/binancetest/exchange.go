@@ -0,0 +1,987 @@
+package binancetest
+
+import (
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// securityType defines what an endpoint requires to be called
+type securityType int
+
+const (
+	securityNone   securityType = iota // public endpoint
+	securityAPIKey                     // X-MBX-APIKEY header only
+	securitySigned                     // X-MBX-APIKEY header and HMAC signature
+)
+
+// Order statuses
+const (
+	StatusNew             = "NEW"
+	StatusPartiallyFilled = "PARTIALLY_FILLED"
+	StatusFilled          = "FILLED"
+	StatusCanceled        = "CANCELED"
+	StatusExpired         = "EXPIRED"
+)
+
+type endpoint struct {
+	security securityType
+	handle   func(x *exchange, q query) Response
+}
+
+// endpoints built-in handling of the REST API, keyed by method and path
+var endpoints = map[string]endpoint{
+	"GET /api/v3/ping":                 {securityNone, func(x *exchange, q query) Response { return JSON(struct{}{}) }},
+	"GET /api/v3/time":                 {securityNone, (*exchange).serverTime},
+	"GET /api/v3/exchangeInfo":         {securityNone, (*exchange).filteredExchangeInfo},
+	"GET /api/v3/depth":                {securityNone, (*exchange).depth},
+	"GET /api/v3/trades":               {securityNone, (*exchange).recentTrades},
+	"GET /api/v3/historicalTrades":     {securityAPIKey, (*exchange).historicalTrades},
+	"GET /api/v3/aggTrades":            {securityNone, (*exchange).aggTrades},
+	"GET /api/v3/klines":               {securityNone, (*exchange).klines},
+	"GET /api/v3/uiKlines":             {securityNone, (*exchange).klines},
+	"GET /api/v3/avgPrice":             {securityNone, (*exchange).avgPrice},
+	"GET /api/v3/ticker/price":         {securityNone, (*exchange).tickerPrice},
+	"GET /api/v3/ticker/bookTicker":    {securityNone, (*exchange).bookTicker},
+	"POST /api/v3/order/test":          {securitySigned, (*exchange).testOrder},
+	"POST /api/v3/order":               {securitySigned, (*exchange).newOrder},
+	"GET /api/v3/order":                {securitySigned, (*exchange).getOrder},
+	"DELETE /api/v3/order":             {securitySigned, (*exchange).cancelOrder},
+	"GET /api/v3/openOrders":           {securitySigned, (*exchange).openOrders},
+	"DELETE /api/v3/openOrders":        {securitySigned, (*exchange).cancelOpenOrders},
+	"POST /api/v3/order/cancelReplace": {securitySigned, (*exchange).cancelReplace},
+	"GET /api/v3/allOrders":            {securitySigned, (*exchange).allOrders},
+	"GET /api/v3/account":              {securitySigned, (*exchange).account},
+	"GET /api/v3/myTrades":             {securitySigned, (*exchange).myTrades},
+	"GET /api/v3/rateLimit/order":      {securitySigned, (*exchange).orderRateLimit},
+	"GET /api/v3/myPreventedMatches":   {securitySigned, func(x *exchange, q query) Response { return JSON([]struct{}{}) }},
+	"GET /api/v3/myAllocations":        {securitySigned, func(x *exchange, q query) Response { return JSON([]struct{}{}) }},
+	"POST /api/v3/order/oco":           {securitySigned, (*exchange).newOCO},
+	"GET /api/v3/orderList":            {securitySigned, (*exchange).getOrderList},
+	"DELETE /api/v3/orderList":         {securitySigned, (*exchange).cancelOrderList},
+	"GET /api/v3/allOrderList":         {securitySigned, (*exchange).allOrderLists},
+	"GET /api/v3/openOrderList":        {securitySigned, (*exchange).openOrderLists},
+	"POST /api/v3/sor/order":           {securitySigned, (*exchange).newOrder},
+	"POST /api/v3/sor/order/test":      {securitySigned, (*exchange).testOrder},
+	"POST /api/v3/userDataStream":      {securityAPIKey, (*exchange).createListenKey},
+	"PUT /api/v3/userDataStream":       {securityAPIKey, (*exchange).keepAliveListenKey},
+	"DELETE /api/v3/userDataStream":    {securityAPIKey, (*exchange).keepAliveListenKey},
+}
+
+// query parameters of a request
+type query map[string]string
+
+func (q query) decimal(name string) decimal.Decimal {
+	d, _ := decimal.NewFromString(q[name])
+	return d
+}
+
+func (q query) int64(name string) int64 {
+	v, _ := strconv.ParseInt(q[name], 10, 64)
+	return v
+}
+
+// Order order kept by the server, it is marshalled as Binance order response
+type Order struct {
+	Symbol                  string          `json:"symbol"`
+	OrderID                 int64           `json:"orderId"`
+	OrderListID             int64           `json:"orderListId"`
+	ClientOrderID           string          `json:"clientOrderId"`
+	TransactTime            int64           `json:"transactTime"`
+	Price                   decimal.Decimal `json:"price"`
+	OrigQty                 decimal.Decimal `json:"origQty"`
+	ExecutedQty             decimal.Decimal `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal `json:"cummulativeQuoteQty"`
+	Status                  string          `json:"status"`
+	TimeInForce             string          `json:"timeInForce"`
+	Type                    string          `json:"type"`
+	Side                    string          `json:"side"`
+	StopPrice               decimal.Decimal `json:"stopPrice"`
+	IcebergQty              decimal.Decimal `json:"icebergQty"`
+	Time                    int64           `json:"time"`
+	UpdateTime              int64           `json:"updateTime"`
+	IsWorking               bool            `json:"isWorking"`
+	WorkingTime             int64           `json:"workingTime"`
+	OrigQuoteOrderQty       decimal.Decimal `json:"origQuoteOrderQty"`
+	SelfTradePreventionMode string          `json:"selfTradePreventionMode"`
+	Fills                   []Fill          `json:"fills"`
+}
+
+// Fill trade of an order
+type Fill struct {
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commissionAsset"`
+	TradeID         int64           `json:"tradeId"`
+}
+
+func (o *Order) open() bool {
+	return o.Status == StatusNew || o.Status == StatusPartiallyFilled
+}
+
+// canceledOrder cancel response, the order gets a new client order id
+type canceledOrder struct {
+	Order
+	OrigClientOrderID string `json:"origClientOrderId"`
+}
+
+type orderListOrder struct {
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+}
+
+type orderList struct {
+	OrderListID       int64            `json:"orderListId"`
+	ContingencyType   string           `json:"contingencyType"`
+	ListStatusType    string           `json:"listStatusType"`
+	ListOrderStatus   string           `json:"listOrderStatus"`
+	ListClientOrderID string           `json:"listClientOrderId"`
+	TransactionTime   int64            `json:"transactionTime"`
+	Symbol            string           `json:"symbol"`
+	Orders            []orderListOrder `json:"orders"`
+	OrderReports      []interface{}    `json:"orderReports,omitempty"`
+}
+
+type trade struct {
+	ID           int64           `json:"id"`
+	Price        decimal.Decimal `json:"price"`
+	Qty          decimal.Decimal `json:"qty"`
+	QuoteQty     decimal.Decimal `json:"quoteQty"`
+	Time         int64           `json:"time"`
+	IsBuyerMaker bool            `json:"isBuyerMaker"`
+	IsBestMatch  bool            `json:"isBestMatch"`
+}
+
+// exchange in-memory state of the server
+type exchange struct {
+	mu          sync.Mutex
+	info        models.ExchangeInfo
+	books       map[string]*book
+	orders      []*Order
+	lists       []*orderList
+	trades      map[string][]trade
+	listenKeys  map[string]bool
+	balances    map[string]models.Balance
+	nextOrderID int64
+	nextListID  int64
+	nextTradeID int64
+	push        func(stream string, data interface{})
+}
+
+func newExchange() *exchange {
+	x := &exchange{
+		info: models.ExchangeInfo{
+			Timezone: "UTC",
+			RateLimits: []models.RateLimit{
+				{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 6000},
+				{RateLimitType: "ORDERS", Interval: "SECOND", IntervalNum: 10, Limit: 100},
+				{RateLimitType: "ORDERS", Interval: "DAY", IntervalNum: 1, Limit: 200000},
+				{RateLimitType: "RAW_REQUESTS", Interval: "MINUTE", IntervalNum: 5, Limit: 61000},
+			},
+			Symbols: []models.SymbolInfo{Symbol("BTCUSDT", "BTC", "USDT"), Symbol("ETHUSDT", "ETH", "USDT")},
+		},
+		books:       make(map[string]*book),
+		trades:      make(map[string][]trade),
+		listenKeys:  make(map[string]bool),
+		balances:    make(map[string]models.Balance),
+		nextOrderID: 1,
+		nextListID:  1,
+		nextTradeID: 1,
+		push:        func(string, interface{}) {},
+	}
+	return x
+}
+
+// Symbol trading rules similar to a major spot pair: tickSize 0.01, stepSize 0.00001 and minNotional 5
+func Symbol(symbol, base, quote string) models.SymbolInfo {
+	d := decimal.RequireFromString
+	return models.SymbolInfo{
+		Symbol:              symbol,
+		Status:              "TRADING",
+		BaseAsset:           base,
+		BaseAssetPrecision:  8,
+		QuoteAsset:          quote,
+		QuotePrecision:      8,
+		QuoteAssetPrecision: 8,
+		OrderTypes: []models.OrderType{models.OrderTypeLimit, models.OrderTypeLimitMaker, models.OrderTypeMarket,
+			models.OrderTypeStopLoss, models.OrderTypeStopLossLimit, models.OrderTypeTakeProfit, models.OrderTypeTakeProfitLimit},
+		IcebergAllowed:             true,
+		OcoAllowed:                 true,
+		QuoteOrderQtyMarketAllowed: true,
+		CancelReplaceAllowed:       true,
+		IsSpotTradingAllowed:       true,
+		Permissions:                []string{"SPOT"},
+		Filters: models.SymbolFilters{
+			Price: &models.PriceFilter{MinPrice: d("0.01"), MaxPrice: d("1000000"), TickSize: d("0.01")},
+			PercentPriceBySide: &models.PercentPriceBySideFilter{BidMultiplierUp: d("5"), BidMultiplierDown: d("0.2"),
+				AskMultiplierUp: d("5"), AskMultiplierDown: d("0.2"), AvgPriceMins: 5},
+			LotSize:      &models.LotSizeFilter{FilterType: models.FilterLotSize, MinQty: d("0.00001"), MaxQty: d("9000"), StepSize: d("0.00001")},
+			Notional:     &models.NotionalFilter{MinNotional: d("5"), ApplyMinToMarket: true, MaxNotional: d("9000000"), AvgPriceMins: 5},
+			IcebergParts: &models.IcebergPartsFilter{Limit: 10},
+			MaxNumOrders: &models.MaxNumOrdersFilter{MaxNumOrders: 200},
+		},
+		DefaultSelfTradePreventionMode:  models.STPModeExpireMaker,
+		AllowedSelfTradePreventionModes: []models.SelfTradePreventionMode{models.STPModeExpireTaker, models.STPModeExpireMaker, models.STPModeExpireBoth},
+	}
+}
+
+func invalidSymbol() Response {
+	return Error(http.StatusBadRequest, binance.CodeInvalidSymbol, "Invalid symbol.")
+}
+
+func unknownOrder(code int64) Response {
+	if code == binance.CodeCancelRejected {
+		return Error(http.StatusBadRequest, code, "Unknown order sent.")
+	}
+	return Error(http.StatusBadRequest, code, "Order does not exist.")
+}
+
+func mandatory(name string) Response {
+	return Error(http.StatusBadRequest, binance.CodeMandatoryParamEmpty,
+		fmt.Sprintf("Mandatory parameter '%s' was not sent, was empty/null, or malformed.", name))
+}
+
+// knownSymbol must be called with the lock held
+func (x *exchange) knownSymbol(symbol string) bool {
+	for _, s := range x.info.Symbols {
+		if s.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+func (x *exchange) serverTime(q query) Response {
+	return JSON(models.ServerTimeResponse{ServerTime: time.Now().UnixMilli()})
+}
+
+func (x *exchange) exchangeInfo(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	info := x.info
+	info.ServerTime = time.Now().UnixMilli()
+	return JSON(info)
+}
+
+func (x *exchange) depth(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	limit := int(q.int64("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	return JSON(x.book(q["symbol"]).snapshot(limit))
+}
+
+func (x *exchange) recentTrades(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	trades := x.trades[q["symbol"]]
+	if limit := int(q.int64("limit")); limit > 0 && len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+	return JSON(append([]trade{}, trades...))
+}
+
+func (x *exchange) testOrder(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+	return JSON(struct{}{})
+}
+
+func (x *exchange) newOrder(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	o, err := x.placeOrder(q, q["type"], q["price"], q["newClientOrderId"], 0)
+	if err != nil {
+		return *err
+	}
+	return JSON(o)
+}
+
+// placeOrder creates a new order, MARKET orders are filled at once at the price of the book or at price 0
+func (x *exchange) placeOrder(q query, orderType, price, clientOrderID string, listID int64) (*Order, *Response) {
+	if !x.knownSymbol(q["symbol"]) {
+		response := invalidSymbol()
+		return nil, &response
+	}
+	if q["side"] == "" {
+		response := mandatory("side")
+		return nil, &response
+	}
+	if orderType == "" {
+		response := mandatory("type")
+		return nil, &response
+	}
+	if clientOrderID == "" {
+		clientOrderID = fmt.Sprintf("binancetest%d", x.nextOrderID)
+	}
+	for _, o := range x.orders {
+		if o.ClientOrderID == clientOrderID && o.open() {
+			response := Error(http.StatusBadRequest, binance.CodeNewOrderRejected, "Duplicate order sent.")
+			return nil, &response
+		}
+	}
+
+	// Binance reports GTC for orders placed without timeInForce, e.g. MARKET and OCO legs
+	timeInForce := q["timeInForce"]
+	if timeInForce == "" {
+		timeInForce = "GTC"
+	}
+
+	now := time.Now().UnixMilli()
+	priceValue, _ := decimal.NewFromString(price)
+	o := &Order{
+		Symbol:                  q["symbol"],
+		OrderID:                 x.nextOrderID,
+		OrderListID:             -1,
+		ClientOrderID:           clientOrderID,
+		TransactTime:            now,
+		Price:                   priceValue,
+		OrigQty:                 q.decimal("quantity"),
+		Status:                  StatusNew,
+		TimeInForce:             timeInForce,
+		Type:                    orderType,
+		Side:                    q["side"],
+		StopPrice:               q.decimal("stopPrice"),
+		IcebergQty:              q.decimal("icebergQty"),
+		Time:                    now,
+		UpdateTime:              now,
+		IsWorking:               !strings.HasPrefix(orderType, "STOP_LOSS") && !strings.HasPrefix(orderType, "TAKE_PROFIT"),
+		WorkingTime:             now,
+		OrigQuoteOrderQty:       q.decimal("quoteOrderQty"),
+		SelfTradePreventionMode: "NONE",
+		Fills:                   []Fill{},
+	}
+	if listID > 0 {
+		o.OrderListID = listID
+	}
+	x.nextOrderID++
+	x.orders = append(x.orders, o)
+	x.report(o, "NEW")
+
+	if orderType == "MARKET" {
+		fillPrice := x.book(o.Symbol).best(o.Side != "BUY")
+		x.fill(o, o.OrigQty, fillPrice)
+	}
+
+	return o, nil
+}
+
+// findOrder looks the order up by orderId or origClientOrderId
+func (x *exchange) findOrder(q query) *Order {
+	orderID := q.int64("orderId")
+	for _, o := range x.orders {
+		if o.Symbol != q["symbol"] {
+			continue
+		}
+		if (orderID != 0 && o.OrderID == orderID) || (orderID == 0 && o.ClientOrderID == q["origClientOrderId"]) {
+			return o
+		}
+	}
+	return nil
+}
+
+func (x *exchange) getOrder(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+	o := x.findOrder(q)
+	if o == nil {
+		return unknownOrder(binance.CodeNoSuchOrder)
+	}
+	return JSON(o)
+}
+
+func (x *exchange) cancelOrder(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+	o := x.findOrder(q)
+	if o == nil || !o.open() {
+		return unknownOrder(binance.CodeCancelRejected)
+	}
+	return JSON(x.cancel(o, q["newClientOrderId"]))
+}
+
+// cancel must be called with the lock held
+func (x *exchange) cancel(o *Order, newClientOrderID string) canceledOrder {
+	if newClientOrderID == "" {
+		newClientOrderID = fmt.Sprintf("binancetestcancel%d", o.OrderID)
+	}
+
+	o.Status = StatusCanceled
+	o.UpdateTime = time.Now().UnixMilli()
+	x.report(o, "CANCELED")
+
+	canceled := canceledOrder{Order: *o, OrigClientOrderID: o.ClientOrderID}
+	canceled.ClientOrderID = newClientOrderID
+	canceled.Fills = nil
+	return canceled
+}
+
+func (x *exchange) openOrders(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if q["symbol"] != "" && !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	orders := []*Order{}
+	for _, o := range x.orders {
+		if o.open() && (q["symbol"] == "" || o.Symbol == q["symbol"]) {
+			orders = append(orders, o)
+		}
+	}
+	return JSON(orders)
+}
+
+func (x *exchange) cancelOpenOrders(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	canceled := []canceledOrder{}
+	for _, o := range x.orders {
+		if o.open() && o.Symbol == q["symbol"] {
+			canceled = append(canceled, x.cancel(o, ""))
+		}
+	}
+	if len(canceled) == 0 {
+		return unknownOrder(binance.CodeCancelRejected)
+	}
+	return JSON(canceled)
+}
+
+func (x *exchange) cancelReplace(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	lookup := query{"symbol": q["symbol"], "orderId": q["cancelOrderId"], "origClientOrderId": q["cancelOrigClientOrderId"]}
+	o := x.findOrder(lookup)
+	if o == nil || !o.open() {
+		return Response{Status: http.StatusBadRequest, Body: map[string]interface{}{
+			"code": binance.CodeCancelReplaceFailed,
+			"msg":  "Order cancel-replace failed.",
+			"data": map[string]interface{}{
+				"cancelResult":     "FAILURE",
+				"newOrderResult":   "NOT_ATTEMPTED",
+				"cancelResponse":   map[string]interface{}{"code": binance.CodeCancelRejected, "msg": "Unknown order sent."},
+				"newOrderResponse": nil,
+			},
+		}}
+	}
+
+	canceled := x.cancel(o, q["cancelNewClientOrderId"])
+	placed, err := x.placeOrder(q, q["type"], q["price"], q["newClientOrderId"], 0)
+	if err != nil {
+		return *err
+	}
+
+	return JSON(map[string]interface{}{
+		"cancelResult":     "SUCCESS",
+		"newOrderResult":   "SUCCESS",
+		"cancelResponse":   canceled,
+		"newOrderResponse": placed,
+	})
+}
+
+func (x *exchange) allOrders(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	fromID := q.int64("orderId")
+	orders := []*Order{}
+	for _, o := range x.orders {
+		if o.Symbol == q["symbol"] && o.OrderID >= fromID {
+			orders = append(orders, o)
+		}
+	}
+	if limit := int(q.int64("limit")); limit > 0 && len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return JSON(orders)
+}
+
+func (x *exchange) newOCO(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	list := &orderList{
+		OrderListID:       x.nextListID,
+		ContingencyType:   "OCO",
+		ListStatusType:    "EXEC_STARTED",
+		ListOrderStatus:   "EXECUTING",
+		ListClientOrderID: q["listClientOrderId"],
+		TransactionTime:   time.Now().UnixMilli(),
+		Symbol:            q["symbol"],
+	}
+	if list.ListClientOrderID == "" {
+		list.ListClientOrderID = fmt.Sprintf("binancetestlist%d", list.OrderListID)
+	}
+	x.nextListID++
+
+	stopType, stopPrice := "STOP_LOSS", ""
+	if q["stopLimitPrice"] != "" {
+		stopType, stopPrice = "STOP_LOSS_LIMIT", q["stopLimitPrice"]
+	}
+
+	stop, err := x.placeOrder(q, stopType, stopPrice, q["stopClientOrderId"], list.OrderListID)
+	if err != nil {
+		return *err
+	}
+	limit, err := x.placeOrder(q, "LIMIT_MAKER", q["price"], q["limitClientOrderId"], list.OrderListID)
+	if err != nil {
+		return *err
+	}
+	stop.StopPrice = q.decimal("stopPrice")
+	limit.StopPrice = decimal.Zero
+
+	for _, o := range []*Order{stop, limit} {
+		list.Orders = append(list.Orders, orderListOrder{Symbol: o.Symbol, OrderID: o.OrderID, ClientOrderID: o.ClientOrderID})
+	}
+	x.lists = append(x.lists, list)
+
+	response := *list
+	response.OrderReports = []interface{}{stop, limit}
+	return JSON(response)
+}
+
+// findList looks the order list up by orderListId, origClientOrderId or listClientOrderId
+func (x *exchange) findList(q query) *orderList {
+	listID := q.int64("orderListId")
+	clientID := q["origClientOrderId"]
+	if clientID == "" {
+		clientID = q["listClientOrderId"]
+	}
+
+	for _, l := range x.lists {
+		if (listID != 0 && l.OrderListID == listID) || (listID == 0 && clientID != "" && l.ListClientOrderID == clientID) {
+			return l
+		}
+	}
+	return nil
+}
+
+func (x *exchange) getOrderList(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	l := x.findList(q)
+	if l == nil {
+		return Error(http.StatusBadRequest, binance.CodeNoSuchOrder, "Order list does not exist.")
+	}
+	return JSON(l)
+}
+
+func (x *exchange) cancelOrderList(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+	l := x.findList(q)
+	if l == nil || l.ListStatusType == "ALL_DONE" {
+		return Error(http.StatusBadRequest, binance.CodeCancelRejected, "Unknown order list sent.")
+	}
+
+	response := *l
+	for _, lo := range l.Orders {
+		if o := x.findOrder(query{"symbol": lo.Symbol, "orderId": strconv.FormatInt(lo.OrderID, 10)}); o != nil && o.open() {
+			response.OrderReports = append(response.OrderReports, x.cancel(o, ""))
+		}
+	}
+	l.ListStatusType, l.ListOrderStatus = "ALL_DONE", "ALL_DONE"
+	l.TransactionTime = time.Now().UnixMilli()
+	response.ListStatusType, response.ListOrderStatus = l.ListStatusType, l.ListOrderStatus
+	return JSON(response)
+}
+
+func (x *exchange) allOrderLists(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	fromID := q.int64("fromId")
+	lists := []*orderList{}
+	for _, l := range x.lists {
+		if l.OrderListID >= fromID {
+			lists = append(lists, l)
+		}
+	}
+	return JSON(lists)
+}
+
+func (x *exchange) openOrderLists(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	lists := []*orderList{}
+	for _, l := range x.lists {
+		if l.ListStatusType != "ALL_DONE" {
+			lists = append(lists, l)
+		}
+	}
+	return JSON(lists)
+}
+
+// account balances are set by Server.SetBalance, orders do not change them
+func (x *exchange) account(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	commission := decimal.RequireFromString("0.001")
+	account := models.AccountResponse{
+		MakerCommission: 10,
+		TakerCommission: 10,
+		CommissionRates: models.CommissionRates{Maker: commission, Taker: commission},
+		CanTrade:        true,
+		CanWithdraw:     true,
+		CanDeposit:      true,
+		UpdateTime:      time.Now().UnixMilli(),
+		AccountType:     "SPOT",
+		Balances:        []models.Balance{},
+		Permissions:     []string{"SPOT"},
+	}
+
+	omitZero := q["omitZeroBalances"] == "true"
+	for _, b := range x.balances {
+		if omitZero && b.Free.IsZero() && b.Locked.IsZero() {
+			continue
+		}
+		account.Balances = append(account.Balances, b)
+	}
+	sort.Slice(account.Balances, func(i, j int) bool {
+		return account.Balances[i].Asset < account.Balances[j].Asset
+	})
+	return JSON(account)
+}
+
+// myTrades fills of orders of the symbol ordered by trade id
+func (x *exchange) myTrades(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	limit := 500
+	if v := q.int64("limit"); v > 0 {
+		limit = int(v)
+	}
+
+	times := make(map[int64]int64)
+	for _, t := range x.trades[q["symbol"]] {
+		times[t.ID] = t.Time
+	}
+
+	result := []models.MyTradesResponse{}
+	for _, o := range x.orders {
+		if o.Symbol != q["symbol"] || (q["orderId"] != "" && o.OrderID != q.int64("orderId")) {
+			continue
+		}
+		for _, f := range o.Fills {
+			t := times[f.TradeID]
+			if (q["fromId"] != "" && f.TradeID < q.int64("fromId")) ||
+				(q["startTime"] != "" && t < q.int64("startTime")) ||
+				(q["endTime"] != "" && t > q.int64("endTime")) {
+				continue
+			}
+			result = append(result, models.MyTradesResponse{
+				Symbol:          o.Symbol,
+				Id:              f.TradeID,
+				OrderId:         o.OrderID,
+				OrderListId:     o.OrderListID,
+				Price:           f.Price,
+				Qty:             f.Qty,
+				QuoteQty:        f.Price.Mul(f.Qty),
+				Commission:      f.Commission,
+				CommissionAsset: f.CommissionAsset,
+				Time:            t,
+				IsBuyer:         o.Side == "BUY",
+				IsMaker:         o.Type != "MARKET",
+				IsBestMatch:     true,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return JSON(result)
+}
+
+// orderRateLimit ORDERS limits of exchange info with open orders as the count
+func (x *exchange) orderRateLimit(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	count := 0
+	for _, o := range x.orders {
+		if o.open() {
+			count++
+		}
+	}
+
+	result := []models.OrderRateLimitResponse{}
+	for _, l := range x.info.RateLimits {
+		if l.RateLimitType == "ORDERS" {
+			result = append(result, models.OrderRateLimitResponse{RateLimit: l, Count: count})
+		}
+	}
+	return JSON(result)
+}
+
+func (x *exchange) createListenKey(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	listenKey := fmt.Sprintf("binancetestlistenkey%d", len(x.listenKeys)+1)
+	x.listenKeys[listenKey] = true
+	return JSON(models.ListenKeyResponse{ListenKey: listenKey})
+}
+
+func (x *exchange) keepAliveListenKey(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.listenKeys[q["listenKey"]] {
+		return Error(http.StatusBadRequest, binance.CodeInvalidListenKey, "This listenKey does not exist.")
+	}
+	return JSON(struct{}{})
+}
+
+// fill executes quantity of the order at the price, must be called with the lock held
+func (x *exchange) fill(o *Order, quantity, price decimal.Decimal) {
+	if remaining := o.OrigQty.Sub(o.ExecutedQty); quantity.GreaterThan(remaining) {
+		quantity = remaining
+	}
+	if !quantity.IsPositive() {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	o.ExecutedQty = o.ExecutedQty.Add(quantity)
+	o.CummulativeQuoteQty = o.CummulativeQuoteQty.Add(quantity.Mul(price))
+	o.UpdateTime = now
+	o.Status = StatusPartiallyFilled
+	if o.ExecutedQty.Equal(o.OrigQty) {
+		o.Status = StatusFilled
+	}
+
+	t := trade{
+		ID:           x.nextTradeID,
+		Price:        price,
+		Qty:          quantity,
+		QuoteQty:     quantity.Mul(price),
+		Time:         now,
+		IsBuyerMaker: o.Side == "SELL",
+		IsBestMatch:  true,
+	}
+	x.nextTradeID++
+	x.trades[o.Symbol] = append(x.trades[o.Symbol], t)
+	o.Fills = append(o.Fills, Fill{Price: price, Qty: quantity, Commission: decimal.Zero, CommissionAsset: "BNB", TradeID: t.ID})
+
+	x.report(o, "TRADE", t)
+
+	// The other order of OCO expires once one of them is filled
+	if o.OrderListID > 0 {
+		for _, l := range x.lists {
+			if l.OrderListID != o.OrderListID {
+				continue
+			}
+			for _, lo := range l.Orders {
+				other := x.findOrder(query{"symbol": lo.Symbol, "orderId": strconv.FormatInt(lo.OrderID, 10)})
+				if other != nil && other != o && other.open() {
+					other.Status = StatusExpired
+					other.UpdateTime = now
+					x.report(other, "EXPIRED")
+				}
+			}
+			l.ListStatusType, l.ListOrderStatus = "ALL_DONE", "ALL_DONE"
+		}
+	}
+}
+
+// report pushes executionReport of the order to all user data streams
+func (x *exchange) report(o *Order, executionType string, trades ...trade) {
+	event := map[string]interface{}{
+		"e": "executionReport",
+		"E": time.Now().UnixMilli(),
+		"s": o.Symbol,
+		"c": o.ClientOrderID,
+		"S": o.Side,
+		"o": o.Type,
+		"f": o.TimeInForce,
+		"q": o.OrigQty,
+		"p": o.Price,
+		"P": o.StopPrice,
+		"F": o.IcebergQty,
+		"g": o.OrderListID,
+		"C": "",
+		"x": executionType,
+		"X": o.Status,
+		"r": "NONE",
+		"i": o.OrderID,
+		"l": decimal.Zero,
+		"z": o.ExecutedQty,
+		"L": decimal.Zero,
+		"n": decimal.Zero,
+		"N": nil,
+		"T": o.UpdateTime,
+		"t": -1,
+		"w": o.open() && o.IsWorking,
+		"m": false,
+		"O": o.Time,
+		"Z": o.CummulativeQuoteQty,
+		"Y": decimal.Zero,
+		"Q": o.OrigQuoteOrderQty,
+		"W": o.WorkingTime,
+		"V": o.SelfTradePreventionMode,
+	}
+	if len(trades) > 0 {
+		t := trades[0]
+		event["l"], event["L"], event["t"], event["Y"] = t.Qty, t.Price, t.ID, t.QuoteQty
+		event["N"] = "BNB"
+		event["m"] = o.Type == "LIMIT_MAKER"
+	}
+
+	for listenKey := range x.listenKeys {
+		x.push(listenKey, event)
+	}
+}
+
+// book must be called with the lock held
+func (x *exchange) book(symbol string) *book {
+	b, ok := x.books[symbol]
+	if !ok {
+		b = &book{lastUpdateID: 1, bids: make(map[string]decimal.Decimal), asks: make(map[string]decimal.Decimal)}
+		x.books[symbol] = b
+	}
+	return b
+}
+
+// book order book of a symbol, levels are keyed by price
+type book struct {
+	lastUpdateID int64
+	bids         map[string]decimal.Decimal
+	asks         map[string]decimal.Decimal
+}
+
+func (b *book) apply(bids, asks [][2]string) {
+	for _, level := range bids {
+		set(b.bids, level)
+	}
+	for _, level := range asks {
+		set(b.asks, level)
+	}
+}
+
+func set(levels map[string]decimal.Decimal, level [2]string) {
+	price, err := decimal.NewFromString(level[0])
+	if err != nil {
+		return
+	}
+	quantity, _ := decimal.NewFromString(level[1])
+	if quantity.IsZero() {
+		delete(levels, price.String())
+		return
+	}
+	levels[price.String()] = quantity
+}
+
+// best price of the side of the book, zero if it is empty
+func (b *book) best(bid bool) decimal.Decimal {
+	levels := sorted(b.asks, false)
+	if bid {
+		levels = sorted(b.bids, true)
+	}
+	if len(levels) == 0 {
+		return decimal.Zero
+	}
+	return decimal.RequireFromString(levels[0][0])
+}
+
+func (b *book) snapshot(limit int) map[string]interface{} {
+	bids, asks := sorted(b.bids, true), sorted(b.asks, false)
+	if len(bids) > limit {
+		bids = bids[:limit]
+	}
+	if len(asks) > limit {
+		asks = asks[:limit]
+	}
+	return map[string]interface{}{
+		"lastUpdateId": b.lastUpdateID,
+		"bids":         bids,
+		"asks":         asks,
+	}
+}
+
+func sorted(levels map[string]decimal.Decimal, desc bool) [][2]string {
+	prices := make([]decimal.Decimal, 0, len(levels))
+	for price := range levels {
+		prices = append(prices, decimal.RequireFromString(price))
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		if desc {
+			return prices[i].GreaterThan(prices[j])
+		}
+		return prices[i].LessThan(prices[j])
+	})
+
+	result := make([][2]string, 0, len(prices))
+	for _, price := range prices {
+		result = append(result, [2]string{price.String(), levels[price.String()].String()})
+	}
+	return result
+}
@@ -0,0 +1,345 @@
+package binancetest
+
+import (
+	"encoding/json"
+	"gateaway/binance/models"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultTradesLimit = 500
+	defaultKlinesLimit = 500
+	avgPriceMins       = 5
+)
+
+// symbols requested by symbol or symbols params, nil means all symbols
+func (q query) symbols() ([]string, bool) {
+	if symbol := q["symbol"]; symbol != "" {
+		return []string{symbol}, true
+	}
+	if q["symbols"] == "" {
+		return nil, true
+	}
+	var symbols []string
+	if err := json.Unmarshal([]byte(q["symbols"]), &symbols); err != nil {
+		return nil, false
+	}
+	return symbols, true
+}
+
+// tickers calls ticker for every requested symbol, a single symbol is returned as an object
+func (x *exchange) tickers(q query, ticker func(symbol string) interface{}) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	symbols, ok := q.symbols()
+	if !ok {
+		return mandatory("symbols")
+	}
+	if symbols == nil {
+		for _, s := range x.info.Symbols {
+			symbols = append(symbols, s.Symbol)
+		}
+	}
+
+	result := make([]interface{}, 0, len(symbols))
+	for _, symbol := range symbols {
+		if !x.knownSymbol(symbol) {
+			return invalidSymbol()
+		}
+		result = append(result, ticker(symbol))
+	}
+
+	if q["symbol"] != "" {
+		return JSON(result[0])
+	}
+	return JSON(result)
+}
+
+// filteredExchangeInfo exchange info limited to symbol or symbols
+func (x *exchange) filteredExchangeInfo(q query) Response {
+	symbols, ok := q.symbols()
+	if !ok {
+		return mandatory("symbols")
+	}
+	if symbols == nil {
+		return x.exchangeInfo(q)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	info := x.info
+	info.ServerTime = time.Now().UnixMilli()
+	info.Symbols = nil
+	for _, symbol := range symbols {
+		if !x.knownSymbol(symbol) {
+			return invalidSymbol()
+		}
+		for _, s := range x.info.Symbols {
+			if s.Symbol == symbol {
+				info.Symbols = append(info.Symbols, s)
+			}
+		}
+	}
+	return JSON(info)
+}
+
+func (x *exchange) historicalTrades(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	limit := int(q.int64("limit"))
+	if limit <= 0 {
+		limit = defaultTradesLimit
+	}
+
+	trades := x.trades[q["symbol"]]
+	if q["fromId"] == "" {
+		if len(trades) > limit {
+			trades = trades[len(trades)-limit:]
+		}
+		return JSON(append([]trade{}, trades...))
+	}
+
+	result := []trade{}
+	for _, t := range trades {
+		if t.ID >= q.int64("fromId") && len(result) < limit {
+			result = append(result, t)
+		}
+	}
+	return JSON(result)
+}
+
+// aggTrades every trade is its own aggregate trade
+func (x *exchange) aggTrades(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	limit := int(q.int64("limit"))
+	if limit <= 0 {
+		limit = defaultTradesLimit
+	}
+
+	var trades []trade
+	for _, t := range x.trades[q["symbol"]] {
+		if (q["fromId"] != "" && t.ID < q.int64("fromId")) ||
+			(q["startTime"] != "" && t.Time < q.int64("startTime")) ||
+			(q["endTime"] != "" && t.Time > q.int64("endTime")) {
+			continue
+		}
+		trades = append(trades, t)
+	}
+
+	// Most recent trades are returned without fromId and startTime
+	if len(trades) > limit {
+		if q["fromId"] == "" && q["startTime"] == "" {
+			trades = trades[len(trades)-limit:]
+		} else {
+			trades = trades[:limit]
+		}
+	}
+
+	result := make([]models.AggTradesResponse, 0, len(trades))
+	for _, t := range trades {
+		result = append(result, models.AggTradesResponse{
+			AggTradeId:   t.ID,
+			Price:        t.Price,
+			Qty:          t.Qty,
+			FirstTradeId: t.ID,
+			LastTradeId:  t.ID,
+			Time:         t.Time,
+			IsBuyerMaker: t.IsBuyerMaker,
+			IsBestMatch:  t.IsBestMatch,
+		})
+	}
+	return JSON(result)
+}
+
+// klineOpen open and close time of the kline containing t, weeks start on Monday and months on the 1st
+func klineOpen(t time.Time, interval string) (time.Time, time.Time, bool) {
+	t = t.UTC()
+	if len(interval) < 2 {
+		return time.Time{}, time.Time{}, false
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	var unit time.Duration
+	switch interval[len(interval)-1] {
+	case 's':
+		unit = time.Second
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		monday := time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+		week := time.Duration(n) * 7 * 24 * time.Hour
+		open := monday.Add(t.Sub(monday) / week * week)
+		return open, open.Add(week), true
+	case 'M':
+		open := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return open, open.AddDate(0, n, 0), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	open := t.Truncate(time.Duration(n) * unit)
+	return open, open.Add(time.Duration(n) * unit), true
+}
+
+// klines aggregates trades into klines, intervals without trades are skipped
+func (x *exchange) klines(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+	if _, _, ok := klineOpen(time.Now(), q["interval"]); !ok {
+		return mandatory("interval")
+	}
+
+	limit := int(q.int64("limit"))
+	if limit <= 0 {
+		limit = defaultKlinesLimit
+	}
+
+	var klines []models.Kline
+	for _, t := range x.trades[q["symbol"]] {
+		open, closeTime, _ := klineOpen(time.UnixMilli(t.Time), q["interval"])
+		if (q["startTime"] != "" && open.UnixMilli() < q.int64("startTime")) ||
+			(q["endTime"] != "" && open.UnixMilli() > q.int64("endTime")) {
+			continue
+		}
+
+		if n := len(klines); n == 0 || klines[n-1].OpenTime != open.UnixMilli() {
+			klines = append(klines, models.Kline{
+				OpenTime:  open.UnixMilli(),
+				Open:      t.Price,
+				High:      t.Price,
+				Low:       t.Price,
+				CloseTime: closeTime.UnixMilli() - 1,
+			})
+		}
+
+		k := &klines[len(klines)-1]
+		if t.Price.GreaterThan(k.High) {
+			k.High = t.Price
+		}
+		if t.Price.LessThan(k.Low) {
+			k.Low = t.Price
+		}
+		k.Close = t.Price
+		k.Volume = k.Volume.Add(t.Qty)
+		k.QuoteAssetVolume = k.QuoteAssetVolume.Add(t.QuoteQty)
+		k.NumberOfTrades++
+		if !t.IsBuyerMaker {
+			k.TakerBuyBaseAssetVolume = k.TakerBuyBaseAssetVolume.Add(t.Qty)
+			k.TakerBuyQuoteAssetVolume = k.TakerBuyQuoteAssetVolume.Add(t.QuoteQty)
+		}
+	}
+
+	if len(klines) > limit {
+		if q["startTime"] == "" {
+			klines = klines[len(klines)-limit:]
+		} else {
+			klines = klines[:limit]
+		}
+	}
+	if klines == nil {
+		klines = []models.Kline{}
+	}
+	return JSON(klines)
+}
+
+// avgPrice volume weighted price of trades of the last 5 minutes, mid price of the book without trades
+func (x *exchange) avgPrice(q query) Response {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.knownSymbol(q["symbol"]) {
+		return invalidSymbol()
+	}
+
+	now := time.Now()
+	since := now.Add(-avgPriceMins * time.Minute).UnixMilli()
+	var qty, quoteQty decimal.Decimal
+	for _, t := range x.trades[q["symbol"]] {
+		if t.Time >= since {
+			qty = qty.Add(t.Qty)
+			quoteQty = quoteQty.Add(t.QuoteQty)
+		}
+	}
+
+	price := decimal.Zero
+	if qty.IsPositive() {
+		price = quoteQty.Div(qty)
+	} else {
+		b := x.book(q["symbol"])
+		price = b.best(true).Add(b.best(false)).Div(decimal.NewFromInt(2))
+	}
+	return JSON(models.AvgPriceResponse{Mins: avgPriceMins, Price: price, CloseTime: now.UnixMilli()})
+}
+
+// tickerPrice price of the last trade
+func (x *exchange) tickerPrice(q query) Response {
+	return x.tickers(q, func(symbol string) interface{} {
+		price := decimal.Zero
+		if trades := x.trades[symbol]; len(trades) > 0 {
+			price = trades[len(trades)-1].Price
+		}
+		return models.PriceTickerResponse{Symbol: symbol, Price: price}
+	})
+}
+
+func (x *exchange) bookTicker(q query) Response {
+	return x.tickers(q, func(symbol string) interface{} {
+		b := x.book(symbol)
+		bid, ask := b.best(true), b.best(false)
+		return models.BookTickerResponse{
+			Symbol:   symbol,
+			BidPrice: bid,
+			BidQty:   b.bids[bid.String()],
+			AskPrice: ask,
+			AskQty:   b.asks[ask.String()],
+		}
+	})
+}
+
+// AddTrade records a public trade of the symbol returned by trades, aggTrades and klines endpoints.
+// Trades have to be added in chronological order.
+func (s *Server) AddTrade(symbol string, price, quantity decimal.Decimal, at time.Time, buyerMaker bool) int64 {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	t := trade{
+		ID:           x.nextTradeID,
+		Price:        price,
+		Qty:          quantity,
+		QuoteQty:     price.Mul(quantity),
+		Time:         at.UnixMilli(),
+		IsBuyerMaker: buyerMaker,
+		IsBestMatch:  true,
+	}
+	x.nextTradeID++
+	x.trades[symbol] = append(x.trades[symbol], t)
+	return t.ID
+}
@@ -0,0 +1,321 @@
+// Package binancetest provides an offline Binance spot server for integration tests.
+//
+// Server implements the REST endpoints used by v3.BinanceClient on top of an in-memory
+// exchange and the combined stream endpoint used by ws.BinanceWsClient:
+//
+//	s := binancetest.NewServer("key", "secret")
+//	defer s.Close()
+//
+//	client := v3.NewBinanceClient("key", "secret", v3.WithBaseURL(s.URL()))
+//	stream := ws.NewBinanceWsClient("key", "secret", ws.WithBaseURL(s.WsURL()))
+//
+// WebSocket API methods are served at WsAPIURL by the same exchange, their requests are recorded
+// with method MethodWsAPI and the API method as path, e.g. Script(binancetest.MethodWsAPI, "order.place", ...).
+//
+// Responses of any endpoint can be scripted with Script and Handle.
+package binancetest
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gateaway/binance"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Response scripted response of an endpoint
+type Response struct {
+	Status int
+	Header http.Header
+	Body   interface{} // marshalled to JSON, []byte and string are written as is
+}
+
+// JSON successful response with the body
+func JSON(body interface{}) Response {
+	return Response{Status: http.StatusOK, Body: body}
+}
+
+// Error Binance error response
+func Error(status int, code int64, msg string) Response {
+	return Response{Status: status, Body: binance.APIError{Code: code, Msg: msg}}
+}
+
+// HandlerFunc computes the response of a request, it overrides the built-in endpoint
+type HandlerFunc func(r *http.Request) Response
+
+// Request recorded request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Query  map[string]string
+	APIKey string
+	Time   time.Time
+}
+
+type Server struct {
+	APIKey    string
+	Secret    string
+	PublicKey crypto.PublicKey // *rsa.PublicKey or ed25519.PublicKey verifies signatures instead of HMAC with Secret
+
+	rest *httptest.Server
+	ws   *httptest.Server
+
+	mu       sync.Mutex
+	scripts  map[string][]Response
+	handlers map[string]HandlerFunc
+	requests []Request
+	exchange *exchange
+
+	streamsMu sync.Mutex
+	conns     map[*streamConn]struct{}
+	upgrader  websocket.Upgrader
+}
+
+// NewServer starts REST and websocket servers accepting requests signed with the key and secret
+func NewServer(apiKey, secret string) *Server {
+	s := &Server{
+		APIKey:   apiKey,
+		Secret:   secret,
+		scripts:  make(map[string][]Response),
+		handlers: make(map[string]HandlerFunc),
+		exchange: newExchange(),
+		conns:    make(map[*streamConn]struct{}),
+	}
+	s.exchange.push = s.Push
+	s.rest = httptest.NewServer(http.HandlerFunc(s.serveREST))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", s.serveStream)
+	mux.HandleFunc(wsAPIPath, s.serveWsAPI)
+	s.ws = httptest.NewServer(mux)
+	return s
+}
+
+// URL base URL of REST API, to be passed to v3.WithBaseURL
+func (s *Server) URL() string {
+	return s.rest.URL
+}
+
+// WsURL base URL of streams, to be passed to ws.WithBaseURL
+func (s *Server) WsURL() string {
+	return "ws" + strings.TrimPrefix(s.ws.URL, "http")
+}
+
+// WsAPIURL URL of WebSocket API, to be passed to wsapi.WithBaseURL
+func (s *Server) WsAPIURL() string {
+	return s.WsURL() + wsAPIPath
+}
+
+// Close stops both servers and drops open stream and WebSocket API connections
+func (s *Server) Close() {
+	s.DropConnections()
+	s.rest.Close()
+	s.ws.Close()
+}
+
+// Script queues one-off responses of the endpoint, they are returned in order before the default handling
+func (s *Server) Script(method, path string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := method + " " + path
+	s.scripts[key] = append(s.scripts[key], responses...)
+}
+
+// Handle overrides the endpoint with the handler, nil restores the built-in one
+func (s *Server) Handle(method, path string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := method + " " + path
+	if handler == nil {
+		delete(s.handlers, key)
+		return
+	}
+	s.handlers[key] = handler
+}
+
+// Requests returns requests received by REST server
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeResponse(w, Error(http.StatusBadRequest, binance.CodeIllegalChars, err.Error()))
+		return
+	}
+
+	query := make(map[string]string, len(r.Form))
+	for name := range r.Form {
+		query[name] = r.Form.Get(name)
+	}
+
+	key := r.Method + " " + r.URL.Path
+	scripted, handler := s.record(Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  query,
+		APIKey: r.Header.Get("X-MBX-APIKEY"),
+		Time:   time.Now(),
+	})
+
+	e, ok := endpoints[key]
+	if ok {
+		if err := s.authorize(r, e.security); err != nil {
+			writeResponse(w, *err)
+			return
+		}
+	}
+
+	switch {
+	case scripted != nil:
+		writeResponse(w, *scripted)
+	case handler != nil:
+		writeResponse(w, handler(r))
+	case ok:
+		writeResponse(w, e.handle(s.exchange, query))
+	default:
+		writeResponse(w, Error(http.StatusNotFound, binance.CodeUnknown, fmt.Sprintf("unknown endpoint %s", key)))
+	}
+}
+
+// record stores the request and returns its next scripted response and the handler overriding its endpoint
+func (s *Server) record(r Request) (*Response, HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r)
+
+	key := r.Method + " " + r.Path
+	var scripted *Response
+	if queue := s.scripts[key]; len(queue) > 0 {
+		scripted = &queue[0]
+		s.scripts[key] = queue[1:]
+	}
+	return scripted, s.handlers[key]
+}
+
+// authorize checks API key, signature and timestamp the way Binance does
+func (s *Server) authorize(r *http.Request, security securityType) *Response {
+	if security == securityNone {
+		return nil
+	}
+
+	if r.Header.Get("X-MBX-APIKEY") != s.APIKey {
+		response := Error(http.StatusUnauthorized, binance.CodeRejectedMbxKey, "Invalid API-key, IP, or permissions for action.")
+		return &response
+	}
+	if security != securitySigned {
+		return nil
+	}
+
+	// Signature is computed over the query string without the signature itself
+	var params []string
+	var sig string
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		if strings.HasPrefix(param, "signature=") {
+			sig = strings.TrimPrefix(param, "signature=")
+			continue
+		}
+		params = append(params, param)
+	}
+	message := strings.Join(params, "&")
+	if body := r.PostForm.Encode(); body != "" {
+		message += body
+	}
+
+	sig, err := url.QueryUnescape(sig)
+	if err != nil || !s.verify(message, sig) {
+		response := Error(http.StatusBadRequest, binance.CodeInvalidSignature, "Signature for this request is not valid.")
+		return &response
+	}
+
+	return checkTimestamp(r.Form.Get("timestamp"), r.Form.Get("recvWindow"))
+}
+
+// checkTimestamp rejects requests older than recvWindow or from the future
+func checkTimestamp(timestampParam, recvWindowParam string) *Response {
+	timestamp, err := strconv.ParseInt(timestampParam, 10, 64)
+	if err != nil {
+		response := mandatory("timestamp")
+		return &response
+	}
+	recvWindow := int64(5000)
+	if recvWindowParam != "" {
+		recvWindow, _ = strconv.ParseInt(recvWindowParam, 10, 64)
+	}
+	now := time.Now().UnixMilli()
+	if timestamp > now+1000 || now-timestamp > recvWindow {
+		response := Error(http.StatusBadRequest, binance.CodeInvalidTimestamp, "Timestamp for this request is outside of the recvWindow.")
+		return &response
+	}
+
+	return nil
+}
+
+// verify checks the signature of the message with PublicKey or HMAC with Secret
+func (s *Server) verify(message, sig string) bool {
+	if sig == "" {
+		return false
+	}
+
+	switch key := s.PublicKey.(type) {
+	case ed25519.PublicKey:
+		data, err := base64.StdEncoding.DecodeString(sig)
+		return err == nil && ed25519.Verify(key, []byte(message), data)
+	case *rsa.PublicKey:
+		data, err := base64.StdEncoding.DecodeString(sig)
+		hash := sha256.Sum256([]byte(message))
+		return err == nil && rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], data) == nil
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(message))
+	return hmac.Equal([]byte(sig), []byte(fmt.Sprintf("%x", mac.Sum(nil))))
+}
+
+func writeResponse(w http.ResponseWriter, response Response) {
+	for name, values := range response.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	var data []byte
+	switch body := response.Body.(type) {
+	case []byte:
+		data = body
+	case string:
+		data = []byte(body)
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			response.Status = http.StatusInternalServerError
+			data = []byte(err.Error())
+		}
+	}
+
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,203 @@
+package binancetest_test
+
+import (
+	"context"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	wsmodels "gateaway/binance/ws/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func newTestServer(t *testing.T) (*binancetest.Server, *v3.BinanceClient) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+	s.SetDepth("BTCUSDT", 1, [][2]string{{"99", "10"}}, [][2]string{{"101", "10"}})
+
+	client := v3.NewBinanceClient("key", "secret", v3.WithBaseURL(s.URL()), v3.WithRateLimiter(nil))
+	return s, client
+}
+
+func order(side models.Side, orderType models.OrderType, quantity string) models.OrderRequest {
+	r := models.OrderRequest{Symbol: "BTCUSDT", Side: side, Type: orderType, Quantity: d(quantity)}
+	if orderType == models.OrderTypeLimit {
+		r.TimeInForce = models.TimeInForceGTC
+		r.Price = d("100")
+	}
+	return r
+}
+
+func TestScriptedResponsesAreUsedOnce(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+	s.Script(http.MethodGet, "/api/v3/avgPrice",
+		binancetest.Error(http.StatusTooManyRequests, binance.CodeTooManyRequests, "Too many requests."),
+		binancetest.JSON(models.AvgPriceResponse{Mins: 5, Price: d("42")}))
+
+	if _, err := client.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: "BTCUSDT"}); err == nil {
+		t.Fatal("first scripted response is not an error")
+	}
+	if r, err := client.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: "BTCUSDT"}); err != nil || !r.Price.Equal(d("42")) {
+		t.Fatalf("second scripted response = %+v, %v", r, err)
+	}
+	// Without trades the average price is the mid of the book
+	if r, err := client.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: "BTCUSDT"}); err != nil || !r.Price.Equal(d("100")) {
+		t.Fatalf("built-in response = %+v, %v", r, err)
+	}
+}
+
+func TestHandlerReplacesEndpointUntilRemoved(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+	s.Handle(http.MethodGet, "/api/v3/avgPrice", func(r *http.Request) binancetest.Response {
+		if r.FormValue("symbol") != "BTCUSDT" {
+			return binancetest.Error(http.StatusBadRequest, binance.CodeInvalidSymbol, "Invalid symbol.")
+		}
+		return binancetest.JSON(models.AvgPriceResponse{Mins: 5, Price: d("7")})
+	})
+
+	for i := 0; i < 2; i++ {
+		if r, err := client.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: "BTCUSDT"}); err != nil || !r.Price.Equal(d("7")) {
+			t.Fatalf("handled response = %+v, %v", r, err)
+		}
+	}
+
+	s.Handle(http.MethodGet, "/api/v3/avgPrice", nil)
+	if r, _ := client.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: "BTCUSDT"}); !r.Price.Equal(d("100")) {
+		t.Fatalf("price = %s, want the built-in response", r.Price)
+	}
+}
+
+func TestRequestsAreRecorded(t *testing.T) {
+	s, client := newTestServer(t)
+
+	if _, err := client.GetAvgPrice(context.Background(), models.AvgPriceRequest{Symbol: "BTCUSDT"}); err != nil {
+		t.Fatal(err)
+	}
+	requests := s.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %+v, want one", requests)
+	}
+	r := requests[0]
+	if r.Method != http.MethodGet || r.Path != "/api/v3/avgPrice" || r.Query["symbol"] != "BTCUSDT" || r.Time.IsZero() {
+		t.Fatalf("request = %+v", r)
+	}
+}
+
+func TestUnknownSymbolIsRejected(t *testing.T) {
+	_, client := newTestServer(t)
+
+	_, err := client.NewOrder(context.Background(), models.OrderRequest{Symbol: "ABCXYZ", Side: models.SideBuy,
+		Type: models.OrderTypeMarket, Quantity: d("1")})
+	if apiErr, ok := binance.AsAPIError(err); !ok || apiErr.Code != binance.CodeInvalidSymbol {
+		t.Fatalf("error = %v, want invalid symbol", err)
+	}
+}
+
+func TestOrdersAreFilledByTheTest(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx := context.Background()
+
+	market, err := client.NewOrder(ctx, order(models.SideBuy, models.OrderTypeMarket, "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if market.Status != models.OrderStatusFilled || len(market.Fills) != 1 || !market.Fills[0].Price.Equal(d("101")) {
+		t.Fatalf("market order = %+v, want filled at the best ask", market)
+	}
+
+	limit, err := client.NewOrder(ctx, order(models.SideBuy, models.OrderTypeLimit, "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit.Status != models.OrderStatusNew {
+		t.Fatalf("limit order status = %s, want NEW", limit.Status)
+	}
+
+	if err := s.Fill("BTCUSDT", limit.OrderId, d("0.5"), d("100")); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := s.Order("BTCUSDT", limit.OrderId); o.Status != binancetest.StatusPartiallyFilled {
+		t.Fatalf("status after partial fill = %s", o.Status)
+	}
+	if err := s.Expire("BTCUSDT", limit.OrderId); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Fill("BTCUSDT", limit.OrderId, d("1"), d("100")); err == nil {
+		t.Fatal("expired order is filled")
+	}
+
+	o, err := client.GetOrder(ctx, models.GetOrderRequest{Symbol: "BTCUSDT", OrderID: limit.OrderId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != models.OrderStatusExpired || !o.ExecutedQty.Equal(d("0.5")) {
+		t.Fatalf("order = %+v, want expired after 0.5 filled", o)
+	}
+}
+
+func TestAllOrdersPagesFromOrderID(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		o, err := client.NewOrder(ctx, order(models.SideBuy, models.OrderTypeLimit, "1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, o.OrderId)
+	}
+
+	limit := 2
+	page, err := client.GetAllOrders(ctx, models.AllOpenOrdersRequest{Symbol: "BTCUSDT", OrderID: &ids[1], Limit: &limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*page) != 2 || int64((*page)[0].OrderId) != ids[1] || int64((*page)[1].OrderId) != ids[2] {
+		t.Fatalf("page = %+v, want orders %d and %d", *page, ids[1], ids[2])
+	}
+}
+
+func TestDepthIsPushedToSubscribedStreams(t *testing.T) {
+	s, client := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := ws.NewBinanceWsClient("key", "secret", ws.WithBaseURL(s.WsURL()))
+	events := make(chan *wsmodels.DepthEvent, 1)
+	if err, _ := stream.SubscribeDepth(ctx, "BTCUSDT", func(e *wsmodels.DepthEvent) { events <- e }); err != nil {
+		t.Fatal(err)
+	}
+	if streams := s.Subscriptions(); len(streams) != 1 || streams[0] != "btcusdt@depth" {
+		t.Fatalf("subscriptions = %v", streams)
+	}
+
+	s.UpdateDepth("BTCUSDT", [][2]string{{"100", "3"}}, nil)
+	select {
+	case e := <-events:
+		if e.FirstUpdateID != 2 || e.LastUpdateID != 2 {
+			t.Fatalf("event U %d u %d, want 2", e.FirstUpdateID, e.LastUpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("depth update is not pushed")
+	}
+
+	// The snapshot has the update applied
+	snapshot, err := client.GetDepth(ctx, models.DepthRequest{Symbol: "BTCUSDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.LastUpdateId != 2 || len(snapshot.Bids) != 2 || !snapshot.Bids[0].Price.Equal(d("100")) {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+}
@@ -0,0 +1,279 @@
+package binancetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateaway/binance/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// streamConn websocket connection of a client with its subscribed streams
+type streamConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	streams map[string]bool
+}
+
+type streamRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+func (c *streamConn) write(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+func (c *streamConn) subscribed(stream string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.streams[stream]
+}
+
+// serveStream combined stream endpoint /stream?streams=a/b supporting live SUBSCRIBE and UNSUBSCRIBE
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/stream" {
+		http.NotFound(w, r)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &streamConn{conn: conn, streams: make(map[string]bool)}
+	if streams := r.URL.Query().Get("streams"); streams != "" {
+		for _, stream := range strings.Split(streams, "/") {
+			c.streams[stream] = true
+		}
+	}
+
+	s.streamsMu.Lock()
+	s.conns[c] = struct{}{}
+	s.streamsMu.Unlock()
+
+	defer func() {
+		s.streamsMu.Lock()
+		delete(s.conns, c)
+		s.streamsMu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req streamRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.write(map[string]interface{}{"error": map[string]interface{}{"code": 2, "msg": "Invalid request"}})
+			continue
+		}
+
+		var result interface{}
+		c.mu.Lock()
+		switch req.Method {
+		case "SUBSCRIBE":
+			for _, stream := range req.Params {
+				c.streams[stream] = true
+			}
+		case "UNSUBSCRIBE":
+			for _, stream := range req.Params {
+				delete(c.streams, stream)
+			}
+		case "LIST_SUBSCRIPTIONS":
+			streams := []string{}
+			for stream := range c.streams {
+				streams = append(streams, stream)
+			}
+			sort.Strings(streams)
+			result = streams
+		default:
+			c.mu.Unlock()
+			c.write(map[string]interface{}{"id": req.ID, "error": map[string]interface{}{"code": 1, "msg": fmt.Sprintf("Unknown method %s", req.Method)}})
+			continue
+		}
+		c.mu.Unlock()
+
+		c.write(map[string]interface{}{"result": result, "id": req.ID})
+	}
+}
+
+// Push sends the event to every connection subscribed to the stream
+func (s *Server) Push(stream string, data interface{}) {
+	s.streamsMu.Lock()
+	conns := make([]*streamConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.streamsMu.Unlock()
+
+	for _, c := range conns {
+		if c.subscribed(stream) {
+			c.write(map[string]interface{}{"stream": stream, "data": data})
+		}
+	}
+}
+
+// Subscriptions streams subscribed by all connections
+func (s *Server) Subscriptions() []string {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	unique := make(map[string]bool)
+	for c := range s.conns {
+		c.mu.Lock()
+		for stream := range c.streams {
+			unique[stream] = true
+		}
+		c.mu.Unlock()
+	}
+
+	streams := make([]string, 0, len(unique))
+	for stream := range unique {
+		streams = append(streams, stream)
+	}
+	sort.Strings(streams)
+	return streams
+}
+
+// DropConnections closes all stream connections, clients see it as a network failure and reconnect
+func (s *Server) DropConnections() {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	for c := range s.conns {
+		c.conn.Close()
+	}
+}
+
+// SetDepth replaces the book of the symbol returned by /api/v3/depth, levels are [price, quantity]
+func (s *Server) SetDepth(symbol string, lastUpdateID int64, bids, asks [][2]string) {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	b := x.book(symbol)
+	b.lastUpdateID = lastUpdateID
+	b.bids = make(map[string]decimal.Decimal)
+	b.asks = make(map[string]decimal.Decimal)
+	b.apply(bids, asks)
+}
+
+// UpdateDepth applies levels to the book of the symbol and pushes the diff to its depth streams.
+// Quantity 0 removes the level.
+func (s *Server) UpdateDepth(symbol string, bids, asks [][2]string) {
+	x := s.exchange
+	x.mu.Lock()
+	b := x.book(symbol)
+	b.lastUpdateID++
+	b.apply(bids, asks)
+	updateID := b.lastUpdateID
+	x.mu.Unlock()
+
+	s.PushDepth(symbol, updateID, updateID, bids, asks)
+}
+
+// PushDepth pushes a diff depth event without changing the book, to script gaps and stale events
+func (s *Server) PushDepth(symbol string, firstUpdateID, lastUpdateID int64, bids, asks [][2]string) {
+	if bids == nil {
+		bids = [][2]string{}
+	}
+	if asks == nil {
+		asks = [][2]string{}
+	}
+
+	event := map[string]interface{}{
+		"e": "depthUpdate",
+		"E": time.Now().UnixMilli(),
+		"s": symbol,
+		"U": firstUpdateID,
+		"u": lastUpdateID,
+		"b": bids,
+		"a": asks,
+	}
+
+	stream := strings.ToLower(symbol) + "@depth"
+	s.Push(stream, event)
+	s.Push(stream+"@100ms", event)
+}
+
+// AddSymbol adds or replaces trading rules of the symbol in exchange info
+func (s *Server) AddSymbol(info models.SymbolInfo) {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for i := range x.info.Symbols {
+		if x.info.Symbols[i].Symbol == info.Symbol {
+			x.info.Symbols[i] = info
+			return
+		}
+	}
+	x.info.Symbols = append(x.info.Symbols, info)
+}
+
+// SetBalance sets free and locked amount of the asset returned by account endpoints
+func (s *Server) SetBalance(asset string, free, locked decimal.Decimal) {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.balances[asset] = models.Balance{Asset: asset, Free: free, Locked: locked}
+}
+
+// Order returns a copy of the order kept by the server
+func (s *Server) Order(symbol string, orderID int64) (Order, bool) {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	o := x.findOrder(query{"symbol": symbol, "orderId": strconv.FormatInt(orderID, 10)})
+	if o == nil {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// Fill executes quantity of the open order at the price and pushes executionReport to user data streams
+func (s *Server) Fill(symbol string, orderID int64, quantity, price decimal.Decimal) error {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	o := x.findOrder(query{"symbol": symbol, "orderId": strconv.FormatInt(orderID, 10)})
+	if o == nil || !o.open() {
+		return fmt.Errorf("order %d of %s is not open", orderID, symbol)
+	}
+	x.fill(o, quantity, price)
+	return nil
+}
+
+// Expire expires the open order as if its time in force ended
+func (s *Server) Expire(symbol string, orderID int64) error {
+	x := s.exchange
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	o := x.findOrder(query{"symbol": symbol, "orderId": strconv.FormatInt(orderID, 10)})
+	if o == nil || !o.open() {
+		return fmt.Errorf("order %d of %s is not open", orderID, symbol)
+	}
+	o.Status = StatusExpired
+	o.UpdateTime = time.Now().UnixMilli()
+	x.report(o, "EXPIRED")
+	return nil
+}
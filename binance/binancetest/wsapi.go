@@ -0,0 +1,212 @@
+package binancetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gateaway/binance"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MethodWsAPI method of recorded WebSocket API requests, their path is the API method
+const MethodWsAPI = "WS"
+
+const wsAPIPath = "/ws-api/v3"
+
+// wsAPIMethods REST endpoints implementing WebSocket API methods
+var wsAPIMethods = map[string]string{
+	"ping":                 "GET /api/v3/ping",
+	"time":                 "GET /api/v3/time",
+	"exchangeInfo":         "GET /api/v3/exchangeInfo",
+	"depth":                "GET /api/v3/depth",
+	"trades.recent":        "GET /api/v3/trades",
+	"order.test":           "POST /api/v3/order/test",
+	"order.place":          "POST /api/v3/order",
+	"order.status":         "GET /api/v3/order",
+	"order.cancel":         "DELETE /api/v3/order",
+	"order.cancelReplace":  "POST /api/v3/order/cancelReplace",
+	"openOrders.status":    "GET /api/v3/openOrders",
+	"openOrders.cancelAll": "DELETE /api/v3/openOrders",
+	"allOrders":            "GET /api/v3/allOrders",
+	"account.status":       "GET /api/v3/account",
+}
+
+type wsAPIRequest struct {
+	ID     interface{}            `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// wsAPISession state of a WebSocket API connection
+type wsAPISession struct {
+	connectedSince  int64
+	authorizedSince int64
+}
+
+// serveWsAPI WebSocket API endpoint answering requests with the built-in REST endpoints
+func (s *Server) serveWsAPI(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	// Registered as a stream connection without streams, so that DropConnections closes it as well
+	c := &streamConn{conn: conn, streams: make(map[string]bool)}
+	s.streamsMu.Lock()
+	s.conns[c] = struct{}{}
+	s.streamsMu.Unlock()
+
+	defer func() {
+		s.streamsMu.Lock()
+		delete(s.conns, c)
+		s.streamsMu.Unlock()
+		conn.Close()
+	}()
+
+	session := &wsAPISession{connectedSince: time.Now().UnixMilli()}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsAPIRequest
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req); err != nil {
+			c.write(wsAPIResponse(nil, Error(http.StatusBadRequest, binance.CodeIllegalChars, "Malformed request.")))
+			continue
+		}
+
+		c.write(wsAPIResponse(req.ID, s.callWsAPI(req, session)))
+	}
+}
+
+func (s *Server) callWsAPI(req wsAPIRequest, session *wsAPISession) Response {
+	query := make(map[string]string, len(req.Params))
+	for name, value := range req.Params {
+		query[name] = fmt.Sprint(value)
+	}
+
+	scripted, handler := s.record(Request{
+		Method: MethodWsAPI,
+		Path:   req.Method,
+		Query:  query,
+		APIKey: query["apiKey"],
+		Time:   time.Now(),
+	})
+
+	if req.Method == "session.logon" {
+		if err := s.authorizeWsAPI(query, securitySigned, false); err != nil {
+			return *err
+		}
+		session.authorizedSince = time.Now().UnixMilli()
+	}
+
+	e, ok := endpoints[wsAPIMethods[req.Method]]
+	if ok {
+		if err := s.authorizeWsAPI(query, e.security, session.authorizedSince > 0); err != nil {
+			return *err
+		}
+	}
+
+	switch {
+	case scripted != nil:
+		return *scripted
+	case handler != nil:
+		values := url.Values{}
+		for name, value := range query {
+			values.Set(name, value)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/"+req.Method+"?"+values.Encode(), nil)
+		r.Form = values
+		return handler(r)
+	case req.Method == "session.logon":
+		return JSON(map[string]interface{}{
+			"apiKey":           s.APIKey,
+			"authorizedSince":  session.authorizedSince,
+			"connectedSince":   session.connectedSince,
+			"returnRateLimits": false,
+			"serverTime":       time.Now().UnixMilli(),
+		})
+	case ok:
+		return e.handle(s.exchange, query)
+	default:
+		return Error(http.StatusBadRequest, binance.CodeUnknown, fmt.Sprintf("Unknown method %s", req.Method))
+	}
+}
+
+// authorizeWsAPI checks apiKey and signature of params sorted by name unless the session is logged on
+func (s *Server) authorizeWsAPI(query map[string]string, security securityType, loggedOn bool) *Response {
+	if security == securityNone {
+		return nil
+	}
+
+	if !loggedOn {
+		if query["apiKey"] != s.APIKey {
+			response := Error(http.StatusUnauthorized, binance.CodeRejectedMbxKey, "Invalid API-key, IP, or permissions for action.")
+			return &response
+		}
+
+		if security == securitySigned {
+			names := make([]string, 0, len(query))
+			for name := range query {
+				if name != "signature" {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			pairs := make([]string, len(names))
+			for i, name := range names {
+				pairs[i] = name + "=" + query[name]
+			}
+
+			if !s.verify(strings.Join(pairs, "&"), query["signature"]) {
+				response := Error(http.StatusBadRequest, binance.CodeInvalidSignature, "Signature for this request is not valid.")
+				return &response
+			}
+		}
+	}
+
+	if security != securitySigned {
+		return nil
+	}
+	return checkTimestamp(query["timestamp"], query["recvWindow"])
+}
+
+// wsAPIResponse wraps the response as result or error of the request, Retry-After becomes retryAfter of the error
+func wsAPIResponse(id interface{}, response Response) map[string]interface{} {
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	body := response.Body
+	switch raw := body.(type) {
+	case []byte:
+		body = json.RawMessage(raw)
+	case string:
+		body = json.RawMessage(raw)
+	}
+
+	if status == http.StatusOK {
+		return map[string]interface{}{"id": id, "status": status, "result": body}
+	}
+
+	if apiErr, ok := body.(binance.APIError); ok {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			body = map[string]interface{}{
+				"code": apiErr.Code,
+				"msg":  apiErr.Msg,
+				"data": map[string]interface{}{"retryAfter": time.Now().Add(time.Duration(seconds) * time.Second).UnixMilli()},
+			}
+		}
+	}
+	return map[string]interface{}{"id": id, "status": status, "error": body}
+}
@@ -0,0 +1,116 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// MaxClientOrderIDLength Binance limit of newClientOrderId and listClientOrderId
+const MaxClientOrderIDLength = 36
+
+const (
+	// clientOrderIDSeparator joins prefix, session and sequence number
+	clientOrderIDSeparator = "-"
+	// maxSequenceLength of the highest sequence number in base 36
+	maxSequenceLength = 13
+	// sessionRandomLength of the random base 36 digits after the session start time
+	sessionRandomLength = 3
+)
+
+// ValidateClientOrderID checks the id against Binance format ^[\.A-Z\:/a-z0-9_-]{1,36}$
+func ValidateClientOrderID(id string) error {
+	if id == "" || len(id) > MaxClientOrderIDLength {
+		return fmt.Errorf("client order id %q must have 1 to %d characters", id, MaxClientOrderIDLength)
+	}
+	for _, r := range id {
+		if !validClientOrderIDChar(r) {
+			return fmt.Errorf("client order id %q contains %q, only letters, digits and .:/_- are allowed", id, r)
+		}
+	}
+	return nil
+}
+
+func validClientOrderIDChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(".:/_-", r)
+}
+
+// ClientOrderIDGenerator generates client order ids <prefix>-<session>-<sequence>.
+// The prefix names the strategy placing the order, the session is the start time of the generator in milliseconds
+// followed by random digits, so ids stay unique across restarts and instances started at the same time,
+// and the sequence number counts orders of the session.
+// Session and sequence are base 36 to fit the Binance length limit.
+type ClientOrderIDGenerator struct {
+	prefix  string
+	session string
+	seq     atomic.Uint64
+}
+
+// NewClientOrderIDGenerator fails when the prefix has characters Binance does not accept
+// or generated ids could exceed MaxClientOrderIDLength
+func NewClientOrderIDGenerator(prefix string) (*ClientOrderIDGenerator, error) {
+	g := &ClientOrderIDGenerator{
+		prefix:  prefix,
+		session: newSession(time.Now()),
+	}
+
+	if prefix == "" {
+		return nil, errors.New("client order id prefix is required")
+	}
+	if err := ValidateClientOrderID(prefix); err != nil {
+		return nil, err
+	}
+	if n := len(prefix) + len(g.session) + 2*len(clientOrderIDSeparator) + maxSequenceLength; n > MaxClientOrderIDLength {
+		return nil, fmt.Errorf("client order id prefix %q is too long, ids would have %d characters, Binance allows %d",
+			prefix, n, MaxClientOrderIDLength)
+	}
+	return g, nil
+}
+
+// newSession start time in milliseconds and random digits, both base 36
+func newSession(start time.Time) string {
+	random := strconv.FormatInt(rand.Int63n(36*36*36), 36)
+	return strconv.FormatInt(start.UnixMilli(), 36) + strings.Repeat("0", sessionRandomLength-len(random)) + random
+}
+
+// Next returns a new id, it is safe for concurrent use
+func (g *ClientOrderIDGenerator) Next() string {
+	seq := g.seq.Add(1)
+	return g.prefix + clientOrderIDSeparator + g.session + clientOrderIDSeparator + strconv.FormatUint(seq, 36)
+}
+
+// Prefix the strategy prefix of generated ids
+func (g *ClientOrderIDGenerator) Prefix() string {
+	return g.prefix
+}
+
+// ParseClientOrderID splits an id of ClientOrderIDGenerator into its prefix, session start time and sequence number.
+// It returns false for ids generated elsewhere.
+func ParseClientOrderID(id string) (prefix string, started time.Time, seq uint64, ok bool) {
+	last := strings.LastIndex(id, clientOrderIDSeparator)
+	if last <= 0 {
+		return "", time.Time{}, 0, false
+	}
+	middle := strings.LastIndex(id[:last], clientOrderIDSeparator)
+	if middle <= 0 {
+		return "", time.Time{}, 0, false
+	}
+
+	session := id[middle+1 : last]
+	if len(session) <= sessionRandomLength {
+		return "", time.Time{}, 0, false
+	}
+	start, err := strconv.ParseInt(session[:len(session)-sessionRandomLength], 36, 64)
+	if err != nil {
+		return "", time.Time{}, 0, false
+	}
+	seq, err = strconv.ParseUint(id[last+1:], 36, 64)
+	if err != nil {
+		return "", time.Time{}, 0, false
+	}
+	return id[:middle], time.UnixMilli(start), seq, true
+}
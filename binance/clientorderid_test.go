@@ -0,0 +1,58 @@
+package binance
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientOrderIDsAreParsed(t *testing.T) {
+	start := time.Now().Truncate(time.Millisecond)
+	g, err := NewClientOrderIDGenerator("grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for want := uint64(1); want <= 2; want++ {
+		id := g.Next()
+		if err := ValidateClientOrderID(id); err != nil {
+			t.Fatal(err)
+		}
+		prefix, started, seq, ok := ParseClientOrderID(id)
+		if !ok || prefix != g.Prefix() || seq != want {
+			t.Fatalf("ParseClientOrderID(%q) = %q, %d, %v", id, prefix, seq, ok)
+		}
+		if started.Before(start) || started.After(time.Now()) {
+			t.Fatalf("session of %q started at %s, want the start of the generator", id, started)
+		}
+	}
+
+	for _, id := range []string{"web_2f8a9c", "grid-1", "grid--1", "grid-x-1"} {
+		if _, _, _, ok := ParseClientOrderID(id); ok {
+			t.Errorf("%q is parsed as a generated id", id)
+		}
+	}
+}
+
+func TestSessionsStartedTogetherDiffer(t *testing.T) {
+	start := time.Now()
+	sessions := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		sessions[newSession(start)] = true
+	}
+	if len(sessions) < 2 {
+		t.Fatalf("sessions started in the same millisecond are all %v", sessions)
+	}
+}
+
+func TestClientOrderIDPrefixIsChecked(t *testing.T) {
+	if _, err := NewClientOrderIDGenerator(""); err == nil {
+		t.Error("empty prefix is accepted")
+	}
+	if _, err := NewClientOrderIDGenerator("grid#1"); err == nil {
+		t.Error("prefix with # is accepted")
+	}
+	if _, err := NewClientOrderIDGenerator(strings.Repeat("a", 12)); err == nil {
+		t.Error("prefix of ids longer than Binance allows is accepted")
+	}
+}
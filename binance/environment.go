@@ -0,0 +1,53 @@
+package binance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment base URLs of Binance spot REST API, streams and WebSocket API
+type Environment struct {
+	Name      string
+	RESTURL   string
+	StreamURL string
+	WsAPIURL  string // empty if the environment has no WebSocket API
+	DataOnly  bool   // public market data only, API keys are not accepted
+}
+
+// Binance spot environments
+// https://binance-docs.github.io/apidocs/spot/en/#general-api-information
+var (
+	Production = Environment{
+		Name:      "prod",
+		RESTURL:   "https://api.binance.com",
+		StreamURL: "wss://stream.binance.com:9443",
+		WsAPIURL:  "wss://ws-api.binance.com:443/ws-api/v3",
+	}
+	Testnet = Environment{
+		Name:      "testnet",
+		RESTURL:   "https://testnet.binance.vision",
+		StreamURL: "wss://stream.testnet.binance.vision",
+		WsAPIURL:  "wss://ws-api.testnet.binance.vision/ws-api/v3",
+	}
+
+	// Mirrors of production with better performance but less stability
+	API1 = Environment{Name: "api1", RESTURL: "https://api1.binance.com", StreamURL: Production.StreamURL, WsAPIURL: Production.WsAPIURL}
+	API2 = Environment{Name: "api2", RESTURL: "https://api2.binance.com", StreamURL: Production.StreamURL, WsAPIURL: Production.WsAPIURL}
+	API3 = Environment{Name: "api3", RESTURL: "https://api3.binance.com", StreamURL: Production.StreamURL, WsAPIURL: Production.WsAPIURL}
+	API4 = Environment{Name: "api4", RESTURL: "https://api4.binance.com", StreamURL: Production.StreamURL, WsAPIURL: Production.WsAPIURL}
+
+	// MarketData public market data only, signed endpoints are not available
+	MarketData = Environment{Name: "data", RESTURL: "https://data-api.binance.vision", StreamURL: "wss://data-stream.binance.vision", DataOnly: true}
+)
+
+var environments = []Environment{Production, Testnet, API1, API2, API3, API4, MarketData}
+
+// EnvironmentByName returns one of the known environments: prod, testnet, api1-api4 or data
+func EnvironmentByName(name string) (Environment, error) {
+	for _, env := range environments {
+		if strings.EqualFold(env.Name, name) {
+			return env, nil
+		}
+	}
+	return Environment{}, fmt.Errorf("unknown Binance environment %s", name)
+}
@@ -1 +1,155 @@
 package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Binance error codes
+// https://binance-docs.github.io/apidocs/spot/en/#error-codes
+const (
+	CodeUnknown              = -1000
+	CodeDisconnected         = -1001
+	CodeUnauthorized         = -1002
+	CodeTooManyRequests      = -1003
+	CodeUnexpectedResponse   = -1006
+	CodeTimeout              = -1007
+	CodeServerBusy           = -1008
+	CodeFilterFailure        = -1013
+	CodeTooManyOrders        = -1015
+	CodeInvalidTimestamp     = -1021
+	CodeInvalidSignature     = -1022
+	CodeIllegalChars         = -1100
+	CodeMandatoryParamEmpty  = -1102
+	CodeBadPrecision         = -1111
+	CodeInvalidSymbol        = -1121
+	CodeInvalidListenKey     = -1125
+	CodeNewOrderRejected     = -2010
+	CodeCancelRejected       = -2011
+	CodeNoSuchOrder          = -2013
+	CodeBadAPIKeyFormat      = -2014
+	CodeRejectedMbxKey       = -2015
+	CodeInsufficientBalances = -2018 // margin only, spot reports it as -2010 with a message
+	CodeCancelReplaceFailed  = -2021
+	CodeOrderArchived        = -2026
+)
+
+// StatusIPBanned HTTP status returned when the IP has been auto-banned
+const StatusIPBanned = 418
+
+const insufficientBalanceMsg = "insufficient balance"
+
+// ErrRateLimitExceeded request is rejected by the client before it would exceed Binance rate limit
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+// ErrFilterFailure order is rejected by the client because it breaks one of the symbol filters
+var ErrFilterFailure = errors.New("filter failure")
+
+var (
+	// ErrOrderNotPlaced the order was not found after its request failed with unknown execution status,
+	// the request may be sent again with the same client order id
+	ErrOrderNotPlaced = errors.New("order not placed")
+	// ErrOrderStatusUnknown the request failed with unknown execution status and the order could not be looked up
+	ErrOrderStatusUnknown = errors.New("order status unknown")
+)
+
+// APIError is an error response of Binance API
+type APIError struct {
+	HTTPStatus int           `json:"-"`
+	RetryAfter time.Duration `json:"-"` // Retry-After of 418 and 429 responses
+	Code       int64         `json:"code"`
+	Msg        string        `json:"msg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance API error: status %d, code %d: %s", e.HTTPStatus, e.Code, e.Msg)
+}
+
+// AsAPIError unwraps APIError from the error chain
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsRateLimited request weight or order rate limit is exceeded, or the IP is banned
+func IsRateLimited(err error) bool {
+	if errors.Is(err, ErrRateLimitExceeded) {
+		return true
+	}
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.HTTPStatus == http.StatusTooManyRequests ||
+		apiErr.HTTPStatus == StatusIPBanned ||
+		apiErr.Code == CodeTooManyRequests ||
+		apiErr.Code == CodeTooManyOrders
+}
+
+// IsIPBanned IP is auto-banned for continuing to send requests after receiving 429
+func IsIPBanned(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.HTTPStatus == StatusIPBanned
+}
+
+// IsUnknownOrder order does not exist or was already canceled
+func IsUnknownOrder(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.Code == CodeNoSuchOrder ||
+		(apiErr.Code == CodeCancelRejected && strings.Contains(strings.ToLower(apiErr.Msg), "unknown order"))
+}
+
+// IsInsufficientBalance account has insufficient balance for requested action
+func IsInsufficientBalance(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.Code == CodeInsufficientBalances ||
+		(apiErr.Code == CodeNewOrderRejected && strings.Contains(strings.ToLower(apiErr.Msg), insufficientBalanceMsg))
+}
+
+// IsTimestampOutsideRecvWindow request timestamp is ahead of server time or outside of the recvWindow
+func IsTimestampOutsideRecvWindow(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Code == CodeInvalidTimestamp
+}
+
+// IsFilterFailure order is rejected by one of the symbol filters
+func IsFilterFailure(err error) bool {
+	if errors.Is(err, ErrFilterFailure) {
+		return true
+	}
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Code == CodeFilterFailure
+}
+
+// IsExecutionUnknown the request may have been executed although it failed: Binance timed out or
+// responded with 5XX, or the connection failed after the request was sent. Requests given up by the caller,
+// its context was cancelled or expired, are not reported as unknown.
+// https://binance-docs.github.io/apidocs/spot/en/#general-api-information
+func IsExecutionUnknown(err error) bool {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.Code == CodeTimeout || apiErr.HTTPStatus >= http.StatusInternalServerError
+	}
+
+	// Errors of the HTTP client, failures before the request is sent are not wrapped
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var opErr *net.OpError
+	return !(errors.As(err, &opErr) && opErr.Op == "dial")
+}
@@ -0,0 +1,37 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestIsExecutionUnknown(t *testing.T) {
+	sent := &url.Error{Op: "Post", URL: "https://api.binance.com/api/v3/order", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout code", &APIError{HTTPStatus: http.StatusBadRequest, Code: CodeTimeout}, true},
+		{"server error", &APIError{HTTPStatus: http.StatusServiceUnavailable, Code: CodeUnknown}, true},
+		{"rejected order", &APIError{HTTPStatus: http.StatusBadRequest, Code: CodeNewOrderRejected}, false},
+		{"connection lost after sending", fmt.Errorf("new order: %w", sent), true},
+		{"dial failed", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, false},
+		{"caller cancelled", &url.Error{Op: "Post", Err: context.Canceled}, false},
+		{"caller deadline", &url.Error{Op: "Post", Err: context.DeadlineExceeded}, false},
+		{"not sent", errors.New("symbol is required"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsExecutionUnknown(tt.err); got != tt.want {
+				t.Fatalf("IsExecutionUnknown(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
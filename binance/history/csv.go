@@ -0,0 +1,209 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gateaway/binance/models"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// flushEvery records written between flushes, what is flushed is not downloaded again on resume
+const flushEvery = 1000
+
+// tailSize bytes read from the end of an existing file to find its last record
+const tailSize = 64 * 1024
+
+var (
+	klineHeader = []string{"open_time", "open", "high", "low", "close", "volume", "close_time",
+		"quote_volume", "trades", "taker_buy_base_volume", "taker_buy_quote_volume"}
+	aggTradeHeader = []string{"agg_trade_id", "price", "qty", "first_trade_id", "last_trade_id", "time",
+		"is_buyer_maker", "is_best_match"}
+	tradeHeader = []string{"id", "price", "qty", "quote_qty", "time", "is_buyer_maker", "is_best_match"}
+)
+
+func klineRecord(k models.Kline) []string {
+	return []string{
+		strconv.FormatInt(k.OpenTime, 10),
+		k.Open.String(),
+		k.High.String(),
+		k.Low.String(),
+		k.Close.String(),
+		k.Volume.String(),
+		strconv.FormatInt(k.CloseTime, 10),
+		k.QuoteAssetVolume.String(),
+		strconv.FormatInt(k.NumberOfTrades, 10),
+		k.TakerBuyBaseAssetVolume.String(),
+		k.TakerBuyQuoteAssetVolume.String(),
+	}
+}
+
+func aggTradeRecord(t models.AggTradesResponse) []string {
+	return []string{
+		strconv.FormatInt(t.AggTradeId, 10),
+		t.Price.String(),
+		t.Qty.String(),
+		strconv.FormatInt(t.FirstTradeId, 10),
+		strconv.FormatInt(t.LastTradeId, 10),
+		strconv.FormatInt(t.Time, 10),
+		strconv.FormatBool(t.IsBuyerMaker),
+		strconv.FormatBool(t.IsBestMatch),
+	}
+}
+
+func tradeRecord(t models.TradesResponse) []string {
+	return []string{
+		strconv.Itoa(t.Id),
+		t.Price.String(),
+		t.Qty.String(),
+		t.QuoteQty.String(),
+		strconv.FormatInt(t.Time, 10),
+		strconv.FormatBool(t.IsBuyerMaker),
+		strconv.FormatBool(t.IsBestMatch),
+	}
+}
+
+// DownloadKlines writes klines opened between start and end to a CSV file.
+// An existing file is continued after its last kline. It returns the number of klines written.
+func (d *Downloader) DownloadKlines(ctx context.Context, path, symbol, interval string, start, end time.Time) (int, error) {
+	return download(ctx, path, klineHeader, klineRecord, func(last int64, ok bool) *Iterator[models.Kline] {
+		if ok {
+			start = time.UnixMilli(last + 1)
+		}
+		return d.Klines(symbol, interval, start, end)
+	})
+}
+
+// DownloadAggTrades writes aggregate trades between start and end to a CSV file.
+// An existing file is continued after its last trade id. It returns the number of trades written.
+func (d *Downloader) DownloadAggTrades(ctx context.Context, path, symbol string, start, end time.Time) (int, error) {
+	return download(ctx, path, aggTradeHeader, aggTradeRecord, func(last int64, ok bool) *Iterator[models.AggTradesResponse] {
+		if ok {
+			return d.AggTradesFrom(symbol, last+1, end)
+		}
+		return d.AggTrades(symbol, start, end)
+	})
+}
+
+// DownloadTrades writes trades between start and end to a CSV file, the client needs an API key.
+// An existing file is continued after its last trade id. It returns the number of trades written.
+func (d *Downloader) DownloadTrades(ctx context.Context, path, symbol string, start, end time.Time) (int, error) {
+	return download(ctx, path, tradeHeader, tradeRecord, func(last int64, ok bool) *Iterator[models.TradesResponse] {
+		if ok {
+			return d.TradesFrom(symbol, last+1, end)
+		}
+		return d.Trades(symbol, start, end)
+	})
+}
+
+// download appends records of the iterator to the file, iterate receives the first column of
+// the last record in the file, open time or id, and whether there was one
+func download[T any](ctx context.Context, path string, header []string, record func(T) []string,
+	iterate func(last int64, ok bool) *Iterator[T]) (int, error) {
+	f, empty, last, err := openCSV(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if empty {
+		if err := w.Write(header); err != nil {
+			return 0, err
+		}
+	}
+	var key int64
+	if last != nil {
+		if key, err = strconv.ParseInt(last[0], 10, 64); err != nil {
+			return 0, fmt.Errorf("last record of %s: %w", path, err)
+		}
+	}
+
+	it := iterate(key, last != nil)
+	n := 0
+	for it.Next(ctx) {
+		if err := w.Write(record(it.Value())); err != nil {
+			return n, err
+		}
+		n++
+		if n%flushEvery == 0 {
+			if w.Flush(); w.Error() != nil {
+				return n, w.Error()
+			}
+		}
+	}
+
+	w.Flush()
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+	if err := w.Error(); err != nil {
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// openCSV opens the file for appending and returns whether it is empty and its last record,
+// nil without records. A line cut off by an interrupted write is truncated.
+func openCSV(path string) (*os.File, bool, []string, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	last, err := lastRecord(f)
+	var offset int64
+	if err == nil {
+		offset, err = f.Seek(0, io.SeekEnd)
+	}
+	if err != nil {
+		f.Close()
+		return nil, false, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, offset == 0, last, nil
+}
+
+// lastRecord reads the last complete line of the file, the header is not a record
+func lastRecord(f *os.File) ([]string, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return nil, err
+	}
+
+	offset := info.Size() - tailSize
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// Drop an incomplete last line
+	if end := bytes.LastIndexByte(tail, '\n') + 1; end < len(tail) {
+		if err := f.Truncate(offset + int64(end)); err != nil {
+			return nil, err
+		}
+		tail = tail[:end]
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	line := lines[len(lines)-1]
+	if len(line) == 0 || (offset == 0 && len(lines) == 1) {
+		return nil, nil
+	}
+
+	record, err := csv.NewReader(bytes.NewReader(line)).Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, errors.New("empty record")
+	}
+	return record, nil
+}
@@ -0,0 +1,194 @@
+package history
+
+import (
+	"context"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"time"
+)
+
+const (
+	// maxPageSize largest limit accepted by klines, aggTrades and historicalTrades
+	maxPageSize = 1000
+	// aggTradesWindow longest startTime to endTime range accepted by aggTrades
+	aggTradesWindow = int64(time.Hour / time.Millisecond)
+)
+
+// Downloader pages through historical market data with the client, which waits for its RateLimiter
+// between requests when Throttle is set
+type Downloader struct {
+	Client     *v3.BinanceClient
+	PageSize   int           // records per request, at most 1000
+	MaxRetries int           // temporary failures of a page retried before Next gives up
+	RetryDelay time.Duration // delay after the first failure, doubled after every next one
+}
+
+func NewDownloader(client *v3.BinanceClient) *Downloader {
+	return &Downloader{
+		Client:     client,
+		PageSize:   maxPageSize,
+		MaxRetries: 5,
+		RetryDelay: time.Second,
+	}
+}
+
+func (d *Downloader) pageSize() int {
+	if d.PageSize <= 0 || d.PageSize > maxPageSize {
+		return maxPageSize
+	}
+	return d.PageSize
+}
+
+// Klines iterates over klines opened between start and end
+func (d *Downloader) Klines(symbol, interval string, start, end time.Time) *Iterator[models.Kline] {
+	from, to := start.UnixMilli(), end.UnixMilli()
+
+	return newIterator(d, func(ctx context.Context) ([]models.Kline, bool, error) {
+		if from > to {
+			return nil, true, nil
+		}
+
+		startTime, endTime := from, to
+		klines, err := d.Client.GetKlines(ctx, models.KlinesRequest{
+			Symbol:    symbol,
+			Interval:  interval,
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Limit:     d.pageSize(),
+		})
+		if err != nil {
+			return nil, false, err
+		}
+
+		page := *klines
+		if len(page) == 0 {
+			return nil, true, nil
+		}
+		from = page[len(page)-1].OpenTime + 1
+		return page, len(page) < d.pageSize(), nil
+	})
+}
+
+// AggTrades iterates over aggregate trades between start and end
+func (d *Downloader) AggTrades(symbol string, start, end time.Time) *Iterator[models.AggTradesResponse] {
+	var fromID int64
+	from, to := start.UnixMilli(), end.UnixMilli()
+
+	return newIterator(d, func(ctx context.Context) ([]models.AggTradesResponse, bool, error) {
+		if fromID > 0 {
+			return d.aggTradesPage(ctx, symbol, &fromID, to)
+		}
+
+		// The first trade is searched by time, following pages continue from its id
+		first, err := d.firstAggTrade(ctx, symbol, &from, to)
+		if err != nil || first == nil {
+			return nil, true, err
+		}
+		fromID = first.AggTradeId
+		return d.aggTradesPage(ctx, symbol, &fromID, to)
+	})
+}
+
+// AggTradesFrom iterates over aggregate trades from the id until end, it resumes an interrupted download
+func (d *Downloader) AggTradesFrom(symbol string, fromID int64, end time.Time) *Iterator[models.AggTradesResponse] {
+	to := end.UnixMilli()
+	return newIterator(d, func(ctx context.Context) ([]models.AggTradesResponse, bool, error) {
+		return d.aggTradesPage(ctx, symbol, &fromID, to)
+	})
+}
+
+// Trades iterates over trades between start and end, the first trade id is found with aggTrades.
+// The client needs an API key.
+func (d *Downloader) Trades(symbol string, start, end time.Time) *Iterator[models.TradesResponse] {
+	var fromID int64
+	from, to := start.UnixMilli(), end.UnixMilli()
+
+	return newIterator(d, func(ctx context.Context) ([]models.TradesResponse, bool, error) {
+		if fromID == 0 {
+			first, err := d.firstAggTrade(ctx, symbol, &from, to)
+			if err != nil || first == nil {
+				return nil, true, err
+			}
+			fromID = first.FirstTradeId
+		}
+		return d.tradesPage(ctx, symbol, &fromID, to)
+	})
+}
+
+// TradesFrom iterates over trades from the id until end, it resumes an interrupted download
+func (d *Downloader) TradesFrom(symbol string, fromID int64, end time.Time) *Iterator[models.TradesResponse] {
+	to := end.UnixMilli()
+	return newIterator(d, func(ctx context.Context) ([]models.TradesResponse, bool, error) {
+		return d.tradesPage(ctx, symbol, &fromID, to)
+	})
+}
+
+// firstAggTrade searches hour long windows from *from until one contains a trade, *from is
+// advanced past the empty windows. It returns nil if there is no trade until to.
+func (d *Downloader) firstAggTrade(ctx context.Context, symbol string, from *int64, to int64) (*models.AggTradesResponse, error) {
+	for *from <= to {
+		startTime, endTime := *from, *from+aggTradesWindow-1
+		if endTime > to {
+			endTime = to
+		}
+
+		trades, err := d.Client.GetAggTrades(ctx, models.AggTradesRequest{
+			Symbol:    symbol,
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Limit:     1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(*trades) > 0 {
+			return &(*trades)[0], nil
+		}
+		*from = endTime + 1
+	}
+	return nil, nil
+}
+
+// aggTradesPage trades from *fromID until to, *fromID is advanced past the page
+func (d *Downloader) aggTradesPage(ctx context.Context, symbol string, fromID *int64, to int64) ([]models.AggTradesResponse, bool, error) {
+	id := *fromID
+	trades, err := d.Client.GetAggTrades(ctx, models.AggTradesRequest{Symbol: symbol, FromID: &id, Limit: d.pageSize()})
+	if err != nil {
+		return nil, false, err
+	}
+
+	page := *trades
+	last := len(page) < d.pageSize()
+	for i, t := range page {
+		if t.Time > to {
+			page, last = page[:i], true
+			break
+		}
+	}
+	if len(page) > 0 {
+		*fromID = page[len(page)-1].AggTradeId + 1
+	}
+	return page, last, nil
+}
+
+// tradesPage trades from *fromID until to, *fromID is advanced past the page
+func (d *Downloader) tradesPage(ctx context.Context, symbol string, fromID *int64, to int64) ([]models.TradesResponse, bool, error) {
+	id := *fromID
+	trades, err := d.Client.GetHistoricalTrades(ctx, models.HistoricalTradesRequest{Symbol: symbol, FromID: &id, Limit: d.pageSize()})
+	if err != nil {
+		return nil, false, err
+	}
+
+	page := *trades
+	last := len(page) < d.pageSize()
+	for i, t := range page {
+		if t.Time > to {
+			page, last = page[:i], true
+			break
+		}
+	}
+	if len(page) > 0 {
+		*fromID = int64(page[len(page)-1].Id) + 1
+	}
+	return page, last, nil
+}
@@ -0,0 +1,261 @@
+package history
+
+import (
+	"context"
+	"encoding/csv"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	v3 "gateaway/binance/v3"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestDownloader pages by 3 records through trades added every step from base
+func newTestDownloader(t *testing.T, trades int, step time.Duration) (*Downloader, *binancetest.Server) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+	for i := 0; i < trades; i++ {
+		s.AddTrade("BTCUSDT", decimal.NewFromInt(int64(100+i)), decimal.NewFromInt(1), base.Add(time.Duration(i)*step), false)
+	}
+
+	d := NewDownloader(v3.NewBinanceClient("key", "secret", v3.WithBaseURL(s.URL()), v3.WithRateLimiter(nil)))
+	d.PageSize = 3
+	d.RetryDelay = time.Millisecond
+	return d, s
+}
+
+// collect returns every record of the iterator
+func collect[T any](t *testing.T, it *Iterator[T]) []T {
+	t.Helper()
+	var records []T
+	for it.Next(context.Background()) {
+		records = append(records, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+// requests counts requests of the endpoint
+func requests(s *binancetest.Server, path string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestKlinesPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		end      time.Duration
+		klines   int
+		requests int
+	}{
+		// The full last page is followed by an empty one
+		{"full last page", time.Hour, 6, 3},
+		// The last kline is at the cutoff, no more pages are requested
+		{"full last page at cutoff", 5 * time.Minute, 6, 2},
+		{"cutoff within a page", 4 * time.Minute, 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, s := newTestDownloader(t, 6, time.Minute)
+
+			klines := collect(t, d.Klines("BTCUSDT", "1m", base, base.Add(tt.end)))
+			if len(klines) != tt.klines {
+				t.Fatalf("klines = %d, want %d", len(klines), tt.klines)
+			}
+			for i, k := range klines {
+				if k.OpenTime != base.Add(time.Duration(i)*time.Minute).UnixMilli() {
+					t.Fatalf("kline %d opened at %d", i, k.OpenTime)
+				}
+			}
+			if n := requests(s, "/api/v3/klines"); n != tt.requests {
+				t.Fatalf("requests = %d, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestAggTradesPaging(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  time.Duration
+		end    time.Duration
+		trades int
+	}{
+		{"full last page", 0, time.Hour, 6},
+		// First trade is found after empty hour long windows
+		{"first trade hours after start", -3 * time.Hour, time.Hour, 6},
+		{"cutoff within a page", 0, 45 * time.Second, 5},
+		{"cutoff at the end of a page", 0, 20 * time.Second, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _ := newTestDownloader(t, 6, 10*time.Second)
+
+			trades := collect(t, d.AggTrades("BTCUSDT", base.Add(tt.start), base.Add(tt.end)))
+			if len(trades) != tt.trades {
+				t.Fatalf("trades = %d, want %d", len(trades), tt.trades)
+			}
+			for i, trade := range trades {
+				if trade.AggTradeId != int64(i+1) {
+					t.Fatalf("trade %d has id %d", i, trade.AggTradeId)
+				}
+			}
+		})
+	}
+}
+
+func TestTradesPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		end      time.Duration
+		trades   int
+		requests int
+	}{
+		{"full last page", time.Hour, 6, 3},
+		// The page with a trade after the cutoff is the last one
+		{"cutoff within a page", 45 * time.Second, 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, s := newTestDownloader(t, 6, 10*time.Second)
+
+			trades := collect(t, d.Trades("BTCUSDT", base, base.Add(tt.end)))
+			if len(trades) != tt.trades {
+				t.Fatalf("trades = %d, want %d", len(trades), tt.trades)
+			}
+			for i, trade := range trades {
+				if trade.Id != i+1 {
+					t.Fatalf("trade %d has id %d", i, trade.Id)
+				}
+			}
+			if n := requests(s, "/api/v3/historicalTrades"); n != tt.requests {
+				t.Fatalf("historicalTrades requests = %d, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRetryAfterRateLimit(t *testing.T) {
+	d, s := newTestDownloader(t, 6, time.Minute)
+	s.Script(http.MethodGet, "/api/v3/klines", binancetest.Response{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"1"}},
+		Body:   binance.APIError{Code: binance.CodeTooManyRequests, Msg: "Too many requests"},
+	})
+
+	started := time.Now()
+	klines := collect(t, d.Klines("BTCUSDT", "1m", base, base.Add(time.Hour)))
+	if len(klines) != 6 {
+		t.Fatalf("klines = %d, want 6", len(klines))
+	}
+	if waited := time.Since(started); waited < time.Second {
+		t.Fatalf("retried after %s, want Retry-After of 1s", waited)
+	}
+}
+
+func TestRejectedPageIsNotRetried(t *testing.T) {
+	d, s := newTestDownloader(t, 6, time.Minute)
+	s.Script(http.MethodGet, "/api/v3/klines", binancetest.Error(http.StatusBadRequest, binance.CodeInvalidSymbol, "Invalid symbol."))
+
+	it := d.Klines("BTCUSDT", "1m", base, base.Add(time.Hour))
+	if it.Next(context.Background()) || it.Err() == nil {
+		t.Fatal("rejected page is not returned as error")
+	}
+	if n := requests(s, "/api/v3/klines"); n != 1 {
+		t.Fatalf("requests = %d, want 1", n)
+	}
+
+	// Next resumes after the error
+	if klines := collect(t, it); len(klines) != 6 {
+		t.Fatalf("klines after resume = %d, want 6", len(klines))
+	}
+}
+
+// readCSV returns records of the file without its header
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records[1:]
+}
+
+func TestDownloadKlinesResumes(t *testing.T) {
+	d, _ := newTestDownloader(t, 6, time.Minute)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "klines.csv")
+
+	if n, err := d.DownloadKlines(ctx, path, "BTCUSDT", "1m", base, base.Add(2*time.Minute)); err != nil || n != 3 {
+		t.Fatalf("first download = %d, %v, want 3 klines", n, err)
+	}
+
+	// Write interrupted in the middle of a line
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(strconv.FormatInt(base.Add(3*time.Minute).UnixMilli(), 10) + ",103")
+	f.Close()
+
+	if n, err := d.DownloadKlines(ctx, path, "BTCUSDT", "1m", base, base.Add(time.Hour)); err != nil || n != 3 {
+		t.Fatalf("resumed download = %d, %v, want 3 klines", n, err)
+	}
+
+	records := readCSV(t, path)
+	if len(records) != 6 {
+		t.Fatalf("records = %d, want 6", len(records))
+	}
+	for i, r := range records {
+		if want := strconv.FormatInt(base.Add(time.Duration(i)*time.Minute).UnixMilli(), 10); r[0] != want {
+			t.Fatalf("record %d opened at %s, want %s", i, r[0], want)
+		}
+	}
+}
+
+func TestDownloadAggTradesResumesFromLastID(t *testing.T) {
+	d, s := newTestDownloader(t, 4, 10*time.Second)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "aggTrades.csv")
+
+	if n, err := d.DownloadAggTrades(ctx, path, "BTCUSDT", base, base.Add(time.Hour)); err != nil || n != 4 {
+		t.Fatalf("first download = %d, %v, want 4 trades", n, err)
+	}
+
+	s.AddTrade("BTCUSDT", decimal.NewFromInt(104), decimal.NewFromInt(1), base.Add(time.Minute), true)
+	s.AddTrade("BTCUSDT", decimal.NewFromInt(105), decimal.NewFromInt(1), base.Add(2*time.Minute), true)
+	if n, err := d.DownloadAggTrades(ctx, path, "BTCUSDT", base, base.Add(time.Hour)); err != nil || n != 2 {
+		t.Fatalf("resumed download = %d, %v, want 2 trades", n, err)
+	}
+
+	records := readCSV(t, path)
+	if len(records) != 6 {
+		t.Fatalf("records = %d, want 6", len(records))
+	}
+	for i, r := range records {
+		if r[0] != strconv.Itoa(i+1) {
+			t.Fatalf("record %d has id %s", i, r[0])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package history downloads months of klines, aggregate trades and trades page by page.
+//
+// Iterators request the next page only when the previous one is consumed, requests go through
+// BinanceClient so that its RateLimiter keeps them within the limits:
+//
+//	d := history.NewDownloader(client)
+//	it := d.Klines("BTCUSDT", "1m", start, end)
+//	for it.Next(ctx) {
+//		k := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		// Next continues after the last returned record
+//	}
+//
+// DownloadKlines, DownloadAggTrades and DownloadTrades write CSV files and continue an existing
+// file after its last record, so an interrupted download is resumed by running it again.
+// Parquet is not supported, CSV files load into pandas, polars or DuckDB as well.
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Iterator pages through records in chronological order
+type Iterator[T any] struct {
+	d *Downloader
+	// fetch returns the next page and whether it is the last one, it advances its cursor only on success
+	fetch func(ctx context.Context) ([]T, bool, error)
+	page  []T
+	value T
+	last  bool
+	err   error
+}
+
+func newIterator[T any](d *Downloader, fetch func(ctx context.Context) ([]T, bool, error)) *Iterator[T] {
+	return &Iterator[T]{d: d, fetch: fetch}
+}
+
+// Next advances to the next record, it returns false at the end or on error.
+// Temporary errors are retried, after a permanent error Next may be called again to resume.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	it.err = nil
+
+	for len(it.page) == 0 {
+		if it.last {
+			return false
+		}
+
+		var page []T
+		var last bool
+		err := it.d.retry(ctx, func(ctx context.Context) error {
+			var err error
+			page, last, err = it.fetch(ctx)
+			return err
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.last = page, last
+	}
+
+	it.value = it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+// Value record returned by the last successful Next
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err error which stopped Next, nil at the end of records
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// retry calls fetch until it succeeds, fails permanently or MaxRetries is exhausted.
+// Rate limit errors wait for Retry-After, other errors for exponential backoff.
+func (d *Downloader) retry(ctx context.Context, fetch func(ctx context.Context) error) error {
+	delay := d.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := fetch(ctx)
+		if err == nil || !temporary(err) || attempt >= d.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		wait := delay
+		if apiErr, ok := binance.AsAPIError(err); ok && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		log.Warn().Msg(fmt.Sprintf("Page request failed, retry %d/%d in %s: %s", attempt+1, d.MaxRetries, wait, err))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// temporary network failures, rate limits and server errors are retried, rejected requests are not
+func temporary(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if binance.IsRateLimited(err) || binance.IsTimestampOutsideRecvWindow(err) {
+		return true
+	}
+	if apiErr, ok := binance.AsAPIError(err); ok {
+		return apiErr.HTTPStatus >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+// Package hub fans out messages of one upstream subscription to many in-process subscribers.
+//
+// Every subscriber reads from its own bounded queue, so a slow one never blocks the websocket
+// reader or the other subscribers. What happens when its queue is full is decided by its Policy:
+//
+//	h := hub.NewHub[*models.DepthEvent]()
+//	err, done := wsClient.SubscribeDepth(ctx, "BTCUSDT", h.Publish)
+//	sub := h.Subscribe(hub.Options{Name: "strategy", QueueSize: 256, Policy: hub.DropOldest})
+//	go sub.Run(ctx, func(e *models.DepthEvent) { ... })
+//
+// Stats reports queue depth, delivered, dropped and conflated messages and the lag of every subscriber.
+package hub
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Hub publishes every message to all subscribers
+type Hub[T any] struct {
+	// Key returns the conflation key of the message, e.g. its symbol.
+	// Conflating subscribers keep the latest message of each key, all messages share one key when nil.
+	Key func(v T) string
+
+	mu          sync.RWMutex
+	subscribers map[*Subscriber[T]]struct{}
+	closed      bool
+	seq         atomic.Uint64 // messages published so far
+}
+
+func NewHub[T any]() *Hub[T] {
+	return &Hub[T]{subscribers: make(map[*Subscriber[T]]struct{})}
+}
+
+// ErrHubClosed is returned by subscribers of a closed hub
+var ErrHubClosed = errors.New("hub closed")
+
+// Subscribe adds a subscriber receiving messages published from now on.
+// Subscribers of a closed hub are returned closed.
+func (h *Hub[T]) Subscribe(opts Options) *Subscriber[T] {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	s := newSubscriber(h, opts)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		s.stop(ErrHubClosed)
+		return s
+	}
+	s.lastSeq = h.seq.Load()
+	h.subscribers[s] = struct{}{}
+	return s
+}
+
+// Publish queues the message to every subscriber, it never blocks on a slow subscriber
+func (h *Hub[T]) Publish(v T) {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return
+	}
+
+	m := message[T]{value: v, seq: h.seq.Add(1)}
+	keyed := false
+	var slow []*Subscriber[T]
+	for s := range h.subscribers {
+		if s.opts.Policy == Conflate && !keyed {
+			if h.Key != nil {
+				m.key = h.Key(v)
+			}
+			keyed = true
+		}
+		if !s.push(m) {
+			slow = append(slow, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		s.stop(ErrSlowConsumer)
+		h.remove(s)
+	}
+}
+
+func (h *Hub[T]) remove(s *Subscriber[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, s)
+}
+
+// Len returns the number of subscribers
+func (h *Hub[T]) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Published returns the number of messages published so far
+func (h *Hub[T]) Published() uint64 {
+	return h.seq.Load()
+}
+
+// Stats returns metrics of every subscriber
+func (h *Hub[T]) Stats() []Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := make([]Stats, 0, len(h.subscribers))
+	for s := range h.subscribers {
+		stats = append(stats, s.Stats())
+	}
+	return stats
+}
+
+// Close stops all subscribers with ErrHubClosed, later messages are ignored
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	subscribers := h.subscribers
+	h.subscribers = make(map[*Subscriber[T]]struct{})
+	h.closed = true
+	h.mu.Unlock()
+
+	for s := range subscribers {
+		s.stop(ErrHubClosed)
+	}
+}
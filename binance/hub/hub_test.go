@@ -0,0 +1,142 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// next returns the oldest queued message, it fails the test when none is queued within a second
+func next[T any](t *testing.T, s *Subscriber[T]) T {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, err := s.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDropOldest(t *testing.T) {
+	h := NewHub[int]()
+	s := h.Subscribe(Options{Name: "slow", QueueSize: 3, Policy: DropOldest})
+
+	for i := 1; i <= 5; i++ {
+		h.Publish(i)
+	}
+	for _, want := range []int{3, 4, 5} {
+		if v := next(t, s); v != want {
+			t.Fatalf("message = %d, want %d", v, want)
+		}
+	}
+
+	stats := s.Stats()
+	if stats.Dropped != 2 || stats.Delivered != 3 || stats.MaxQueued != 3 || stats.Policy != "dropOldest" {
+		t.Fatalf("stats = %+v, want 2 dropped and 3 delivered", stats)
+	}
+}
+
+func TestConflateKeepsQueuePosition(t *testing.T) {
+	h := NewHub[string]()
+	h.Key = func(v string) string { return v[:3] }
+	s := h.Subscribe(Options{QueueSize: 2, Policy: Conflate})
+
+	h.Publish("btc 1")
+	h.Publish("eth 1")
+	// Replaces the queued BTC message ahead of ETH
+	h.Publish("btc 2")
+	if v := next(t, s); v != "btc 2" {
+		t.Fatalf("first message = %s, want btc 2", v)
+	}
+
+	// Full queue without the key drops the oldest message
+	h.Publish("xrp 1")
+	h.Publish("sol 1")
+	for _, want := range []string{"xrp 1", "sol 1"} {
+		if v := next(t, s); v != want {
+			t.Fatalf("message = %s, want %s", v, want)
+		}
+	}
+
+	stats := s.Stats()
+	if stats.Conflated != 1 || stats.Dropped != 1 || stats.Delivered != 3 {
+		t.Fatalf("stats = %+v, want 1 conflated and 1 dropped", stats)
+	}
+}
+
+func TestDisconnectSlowConsumer(t *testing.T) {
+	h := NewHub[int]()
+	slow := h.Subscribe(Options{QueueSize: 2, Policy: Disconnect})
+	other := h.Subscribe(Options{QueueSize: 2, Policy: DropOldest})
+
+	for i := 1; i <= 3; i++ {
+		h.Publish(i)
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow subscriber is not stopped")
+	}
+	if !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Fatalf("Err = %v, want ErrSlowConsumer", slow.Err())
+	}
+	// Queued messages are discarded
+	if _, err := slow.Next(context.Background()); !errors.Is(err, ErrSlowConsumer) {
+		t.Fatalf("Next error = %v, want ErrSlowConsumer", err)
+	}
+	if h.Len() != 1 {
+		t.Fatalf("subscribers = %d, want the slow one removed", h.Len())
+	}
+
+	// Other subscribers keep receiving
+	h.Publish(4)
+	if v := next(t, other); v != 3 {
+		t.Fatalf("message = %d, want 3", v)
+	}
+}
+
+func TestStatsLag(t *testing.T) {
+	h := NewHub[int]()
+	h.Publish(0)
+	s := h.Subscribe(Options{Name: "strategy"})
+
+	for i := 1; i <= 4; i++ {
+		h.Publish(i)
+	}
+	time.Sleep(time.Millisecond)
+	if v := next(t, s); v != 1 {
+		t.Fatalf("message = %d, want 1", v)
+	}
+
+	stats := h.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("stats = %+v, want one subscriber", stats)
+	}
+	if st := stats[0]; st.Name != "strategy" || st.Lag != 3 || st.Queued != 3 || st.Delivered != 1 || st.Delay <= 0 {
+		t.Fatalf("stats = %+v, want lag of 3 queued messages", st)
+	}
+	if h.Published() != 5 {
+		t.Fatalf("published = %d, want 5", h.Published())
+	}
+}
+
+func TestCloseStopsSubscribers(t *testing.T) {
+	h := NewHub[int]()
+	s := h.Subscribe(Options{})
+	closed := h.Subscribe(Options{})
+	closed.Close()
+	if _, err := closed.Next(context.Background()); !errors.Is(err, ErrSubscriberClosed) {
+		t.Fatalf("Next error = %v, want ErrSubscriberClosed", err)
+	}
+
+	h.Close()
+	if _, err := s.Next(context.Background()); !errors.Is(err, ErrHubClosed) {
+		t.Fatalf("Next error = %v, want ErrHubClosed", err)
+	}
+	if late := h.Subscribe(Options{}); !errors.Is(late.Err(), ErrHubClosed) {
+		t.Fatalf("subscriber of a closed hub Err = %v", late.Err())
+	}
+}
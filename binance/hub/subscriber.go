@@ -0,0 +1,262 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultQueueSize of subscribers which do not set QueueSize
+const DefaultQueueSize = 1024
+
+// Policy decides what happens to a message published to a full queue
+type Policy int
+
+const (
+	DropOldest Policy = iota // the oldest queued message is dropped
+	Conflate                 // the message replaces the queued one with the same key, otherwise the oldest is dropped
+	Disconnect               // the subscriber is stopped with ErrSlowConsumer
+)
+
+func (p Policy) String() string {
+	switch p {
+	case DropOldest:
+		return "dropOldest"
+	case Conflate:
+		return "conflate"
+	case Disconnect:
+		return "disconnect"
+	}
+	return "unknown"
+}
+
+var (
+	// ErrSlowConsumer is returned by a Disconnect subscriber whose queue overflowed
+	ErrSlowConsumer = errors.New("subscriber too slow, queue overflow")
+	// ErrSubscriberClosed is returned after Close
+	ErrSubscriberClosed = errors.New("subscriber closed")
+)
+
+// Options of a subscriber
+type Options struct {
+	Name      string // identifies the subscriber in Stats
+	QueueSize int    // messages queued before Policy applies, DefaultQueueSize when zero
+	Policy    Policy
+}
+
+// Stats metrics of a subscriber
+type Stats struct {
+	Name      string        `json:"name"`
+	Policy    string        `json:"policy"`
+	Queued    int           `json:"queued"`    // messages waiting in the queue
+	MaxQueued int           `json:"maxQueued"` // highest queue depth so far
+	Delivered uint64        `json:"delivered"`
+	Dropped   uint64        `json:"dropped"`   // messages dropped from a full queue
+	Conflated uint64        `json:"conflated"` // messages replaced by a newer one with the same key
+	Lag       uint64        `json:"lag"`       // messages published after the last delivered one
+	Delay     time.Duration `json:"delay"`     // how long the oldest queued message waits
+	Err       string        `json:"err,omitempty"`
+}
+
+type message[T any] struct {
+	value T
+	seq   uint64
+	key   string
+	at    time.Time
+}
+
+// Subscriber receives messages of the hub from its bounded queue
+type Subscriber[T any] struct {
+	hub  *Hub[T]
+	opts Options
+
+	mu        sync.Mutex
+	queue     []message[T] // ring buffer, head is the absolute position of the oldest message
+	head      uint64
+	tail      uint64
+	keys      map[string]uint64 // conflation key to absolute position of its queued message
+	maxQueued int
+	delivered uint64
+	dropped   uint64
+	conflated uint64
+	lastSeq   uint64 // hub sequence of the last delivered message
+	err       error
+
+	ready chan struct{} // signalled when a message is queued
+	done  chan struct{} // closed when the subscriber stops
+}
+
+func newSubscriber[T any](h *Hub[T], opts Options) *Subscriber[T] {
+	s := &Subscriber[T]{
+		hub:   h,
+		opts:  opts,
+		queue: make([]message[T], opts.QueueSize),
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	if opts.Policy == Conflate {
+		s.keys = make(map[string]uint64)
+	}
+	return s
+}
+
+func (s *Subscriber[T]) len() int {
+	return int(s.tail - s.head)
+}
+
+func (s *Subscriber[T]) at(pos uint64) *message[T] {
+	return &s.queue[pos%uint64(len(s.queue))]
+}
+
+// push queues the message, it returns false when a Disconnect subscriber overflows
+func (s *Subscriber[T]) push(m message[T]) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return true
+	}
+
+	m.at = time.Now()
+	if s.keys != nil {
+		if pos, ok := s.keys[m.key]; ok {
+			// Keeps the position in the queue so that other keys are not overtaken
+			*s.at(pos) = m
+			s.conflated++
+			return true
+		}
+	}
+
+	if s.len() == len(s.queue) {
+		if s.opts.Policy == Disconnect {
+			return false
+		}
+		s.pop()
+		s.dropped++
+	}
+
+	*s.at(s.tail) = m
+	if s.keys != nil {
+		s.keys[m.key] = s.tail
+	}
+	s.tail++
+	if n := s.len(); n > s.maxQueued {
+		s.maxQueued = n
+	}
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop removes the oldest message, the queue must not be empty
+func (s *Subscriber[T]) pop() message[T] {
+	m := s.at(s.head)
+	popped := *m
+	*m = message[T]{}
+	if s.keys != nil && s.keys[popped.key] == s.head {
+		delete(s.keys, popped.key)
+	}
+	s.head++
+	return popped
+}
+
+// Next returns the oldest queued message, waiting until one is published.
+// It fails when ctx is done or the subscriber is stopped, queued messages are discarded then.
+func (s *Subscriber[T]) Next(ctx context.Context) (T, error) {
+	for {
+		s.mu.Lock()
+		err := s.err
+		if err == nil && s.len() > 0 {
+			m := s.pop()
+			s.delivered++
+			s.lastSeq = m.seq
+			s.mu.Unlock()
+			return m.value, nil
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-s.done:
+		case <-s.ready:
+		}
+	}
+}
+
+// Run calls the handler with every message until ctx is done or the subscriber is stopped
+func (s *Subscriber[T]) Run(ctx context.Context, handler func(v T)) error {
+	for {
+		v, err := s.Next(ctx)
+		if err != nil {
+			return err
+		}
+		handler(v)
+	}
+}
+
+// Done is closed when the subscriber is stopped by Close, overflow or closing of the hub
+func (s *Subscriber[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the subscriber stopped, nil while it is running
+func (s *Subscriber[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes from the hub, Next returns ErrSubscriberClosed
+func (s *Subscriber[T]) Close() {
+	s.stop(ErrSubscriberClosed)
+	s.hub.remove(s)
+}
+
+func (s *Subscriber[T]) stop(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	s.err = err
+	for s.len() > 0 {
+		s.pop()
+	}
+	close(s.done)
+}
+
+// Stats returns metrics of the subscriber
+func (s *Subscriber[T]) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{
+		Name:      s.opts.Name,
+		Policy:    s.opts.Policy.String(),
+		Queued:    s.len(),
+		MaxQueued: s.maxQueued,
+		Delivered: s.delivered,
+		Dropped:   s.dropped,
+		Conflated: s.conflated,
+		Lag:       s.hub.seq.Load() - s.lastSeq,
+	}
+	if s.len() > 0 {
+		stats.Delay = time.Since(s.at(s.head).at)
+	}
+	if s.err != nil {
+		stats.Err = s.err.Error()
+	}
+	return stats
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// dayMillis longest allowed range between startTime and endTime of history requests
+const dayMillis = 24 * 60 * 60 * 1000
+
+// ACCOUNT INFORMATION
+
+type AccountRequest struct {
+	OmitZeroBalances bool  `url:"omitZeroBalances,omitempty"`
+	RecvWindow       int64 `url:"recvWindow,omitempty"`
+	Timestamp        int64 `url:"timestamp,omitempty"`
+}
+
+func (r *AccountRequest) Validate() error {
+	if r.RecvWindow > 60000 {
+		return errors.New("recvWindow must be less than or equal to 60000")
+	}
+	return nil
+}
+
+type AccountResponse struct {
+	MakerCommission            int64           `json:"makerCommission"`
+	TakerCommission            int64           `json:"takerCommission"`
+	BuyerCommission            int64           `json:"buyerCommission"`
+	SellerCommission           int64           `json:"sellerCommission"`
+	CommissionRates            CommissionRates `json:"commissionRates"`
+	CanTrade                   bool            `json:"canTrade"`
+	CanWithdraw                bool            `json:"canWithdraw"`
+	CanDeposit                 bool            `json:"canDeposit"`
+	Brokered                   bool            `json:"brokered"`
+	RequireSelfTradePrevention bool            `json:"requireSelfTradePrevention"`
+	PreventSor                 bool            `json:"preventSor"`
+	UpdateTime                 int64           `json:"updateTime"`
+	AccountType                string          `json:"accountType"`
+	Balances                   []Balance       `json:"balances"`
+	Permissions                []string        `json:"permissions"`
+	UID                        int64           `json:"uid"`
+}
+
+type CommissionRates struct {
+	Maker  decimal.Decimal `json:"maker"`
+	Taker  decimal.Decimal `json:"taker"`
+	Buyer  decimal.Decimal `json:"buyer"`
+	Seller decimal.Decimal `json:"seller"`
+}
+
+type Balance struct {
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+}
+
+// Balance returns free and locked amount of the asset, zero if the account does not hold it
+func (a *AccountResponse) Balance(asset string) Balance {
+	for _, b := range a.Balances {
+		if b.Asset == asset {
+			return b
+		}
+	}
+	return Balance{Asset: asset}
+}
+
+// ACCOUNT TRADE LIST
+
+// MyTradesRequest trades of the account, orderId and fromId can not be combined with startTime and endTime
+type MyTradesRequest struct {
+	Symbol     string `url:"symbol"`
+	OrderID    *int64 `url:"orderId,omitempty"`
+	StartTime  *int64 `url:"startTime,omitempty"`
+	EndTime    *int64 `url:"endTime,omitempty"`
+	FromID     *int64 `url:"fromId,omitempty"`
+	Limit      int    `url:"limit,omitempty"`
+	RecvWindow int64  `url:"recvWindow,omitempty"`
+	Timestamp  int64  `url:"timestamp,omitempty"`
+}
+
+func (r *MyTradesRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is mandatory")
+	}
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	if r.FromID != nil && (r.StartTime != nil || r.EndTime != nil) {
+		return errors.New("fromId can not be combined with startTime or endTime")
+	}
+	if r.OrderID != nil && (r.StartTime != nil || r.EndTime != nil) {
+		return errors.New("orderId can not be combined with startTime or endTime")
+	}
+	if r.StartTime != nil && r.EndTime != nil {
+		if *r.EndTime < *r.StartTime {
+			return errors.New("endTime must not be before startTime")
+		}
+		if *r.EndTime-*r.StartTime > dayMillis {
+			return errors.New("startTime and endTime can not be more than 24 hours apart")
+		}
+	}
+	if r.RecvWindow > 60000 {
+		return errors.New("recvWindow must be less than or equal to 60000")
+	}
+	return nil
+}
+
+type MyTradesResponse struct {
+	Symbol          string          `json:"symbol"`
+	Id              int64           `json:"id"`
+	OrderId         int64           `json:"orderId"`
+	OrderListId     int64           `json:"orderListId"`
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	QuoteQty        decimal.Decimal `json:"quoteQty"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commissionAsset"`
+	Time            int64           `json:"time"`
+	IsBuyer         bool            `json:"isBuyer"`
+	IsMaker         bool            `json:"isMaker"`
+	IsBestMatch     bool            `json:"isBestMatch"`
+}
+
+// QUERY UNFILLED ORDER COUNT
+
+type OrderRateLimitRequest struct {
+	RecvWindow int64 `url:"recvWindow,omitempty"`
+	Timestamp  int64 `url:"timestamp,omitempty"`
+}
+
+func (r *OrderRateLimitRequest) Validate() error {
+	if r.RecvWindow > 60000 {
+		return errors.New("recvWindow must be less than or equal to 60000")
+	}
+	return nil
+}
+
+// OrderRateLimitResponse unfilled order count of an ORDERS limit
+type OrderRateLimitResponse struct {
+	RateLimit
+	Count int `json:"count"`
+}
+
+// QUERY PREVENTED MATCHES
+
+// PreventedMatchesRequest orders expired by self-trade prevention, either preventedMatchId or orderId is required
+type PreventedMatchesRequest struct {
+	Symbol               string `url:"symbol"`
+	PreventedMatchID     *int64 `url:"preventedMatchId,omitempty"`
+	OrderID              *int64 `url:"orderId,omitempty"`
+	FromPreventedMatchID *int64 `url:"fromPreventedMatchId,omitempty"`
+	Limit                int    `url:"limit,omitempty"`
+	RecvWindow           int64  `url:"recvWindow,omitempty"`
+	Timestamp            int64  `url:"timestamp,omitempty"`
+}
+
+func (r *PreventedMatchesRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is mandatory")
+	}
+	if (r.PreventedMatchID == nil) == (r.OrderID == nil) {
+		return errors.New("either preventedMatchId or orderId must be provided")
+	}
+	if r.FromPreventedMatchID != nil && r.OrderID == nil {
+		return errors.New("fromPreventedMatchId can be used only with orderId")
+	}
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	if r.RecvWindow > 60000 {
+		return errors.New("recvWindow must be less than or equal to 60000")
+	}
+	return nil
+}
+
+type PreventedMatchesResponse struct {
+	Symbol                  string                  `json:"symbol"`
+	PreventedMatchId        int64                   `json:"preventedMatchId"`
+	TakerOrderId            int64                   `json:"takerOrderId"`
+	MakerSymbol             string                  `json:"makerSymbol"`
+	MakerOrderId            int64                   `json:"makerOrderId"`
+	TradeGroupId            int64                   `json:"tradeGroupId"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
+	Price                   decimal.Decimal         `json:"price"`
+	MakerPreventedQuantity  decimal.Decimal         `json:"makerPreventedQuantity"`
+	TransactTime            int64                   `json:"transactTime"`
+}
+
+// QUERY ALLOCATIONS
+
+// AllocationsRequest allocations of orders placed by SOR
+type AllocationsRequest struct {
+	Symbol           string `url:"symbol"`
+	StartTime        *int64 `url:"startTime,omitempty"`
+	EndTime          *int64 `url:"endTime,omitempty"`
+	FromAllocationID *int64 `url:"fromAllocationId,omitempty"`
+	Limit            int    `url:"limit,omitempty"`
+	OrderID          *int64 `url:"orderId,omitempty"`
+	RecvWindow       int64  `url:"recvWindow,omitempty"`
+	Timestamp        int64  `url:"timestamp,omitempty"`
+}
+
+func (r *AllocationsRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is mandatory")
+	}
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	if r.StartTime != nil && r.EndTime != nil {
+		if *r.EndTime < *r.StartTime {
+			return errors.New("endTime must not be before startTime")
+		}
+		if *r.EndTime-*r.StartTime > dayMillis {
+			return errors.New("startTime and endTime can not be more than 24 hours apart")
+		}
+	}
+	if r.RecvWindow > 60000 {
+		return errors.New("recvWindow must be less than or equal to 60000")
+	}
+	return nil
+}
+
+type AllocationsResponse struct {
+	Symbol          string          `json:"symbol"`
+	AllocationId    int64           `json:"allocationId"`
+	AllocationType  string          `json:"allocationType"`
+	OrderId         int64           `json:"orderId"`
+	OrderListId     int64           `json:"orderListId"`
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	QuoteQty        decimal.Decimal `json:"quoteQty"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commissionAsset"`
+	Time            int64           `json:"time"`
+	IsBuyer         bool            `json:"isBuyer"`
+	IsMaker         bool            `json:"isMaker"`
+	IsAllocator     bool            `json:"isAllocator"`
+}
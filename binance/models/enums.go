@@ -0,0 +1,303 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Binance enums are upper case and compared case-sensitively. Validate checks request values,
+// response values are checked when they are decoded, unknown values fail the decoding.
+// Lists of the values a symbol supports skip unknown values instead, Binance adds new ones over time.
+
+type Side string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+var sides = []Side{SideBuy, SideSell}
+
+func (s Side) Validate() error { return validateEnum("side", s, sides) }
+
+func (s *Side) UnmarshalJSON(data []byte) error { return unmarshalEnum("side", data, s, sides) }
+
+type OrderType string
+
+const (
+	OrderTypeLimit           OrderType = "LIMIT"
+	OrderTypeMarket          OrderType = "MARKET"
+	OrderTypeStopLoss        OrderType = "STOP_LOSS"
+	OrderTypeStopLossLimit   OrderType = "STOP_LOSS_LIMIT"
+	OrderTypeTakeProfit      OrderType = "TAKE_PROFIT"
+	OrderTypeTakeProfitLimit OrderType = "TAKE_PROFIT_LIMIT"
+	OrderTypeLimitMaker      OrderType = "LIMIT_MAKER"
+)
+
+var orderTypes = []OrderType{OrderTypeLimit, OrderTypeMarket, OrderTypeStopLoss, OrderTypeStopLossLimit,
+	OrderTypeTakeProfit, OrderTypeTakeProfitLimit, OrderTypeLimitMaker}
+
+func (t OrderType) Validate() error { return validateEnum("order type", t, orderTypes) }
+
+func (t *OrderType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("order type", data, t, orderTypes)
+}
+
+// OrderTypes order types of a symbol, unknown types are skipped
+type OrderTypes []OrderType
+
+func (t *OrderTypes) UnmarshalJSON(data []byte) error {
+	return unmarshalKnownEnums("order types", data, (*[]OrderType)(t), orderTypes)
+}
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC" // good till canceled
+	TimeInForceIOC TimeInForce = "IOC" // immediate or cancel
+	TimeInForceFOK TimeInForce = "FOK" // fill or kill
+)
+
+var timeInForces = []TimeInForce{TimeInForceGTC, TimeInForceIOC, TimeInForceFOK}
+
+func (t TimeInForce) Validate() error { return validateEnum("timeInForce", t, timeInForces) }
+
+func (t *TimeInForce) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("timeInForce", data, t, timeInForces)
+}
+
+// SelfTradePreventionMode what expires when an order would trade against an order of the same account
+type SelfTradePreventionMode string
+
+const (
+	STPModeNone        SelfTradePreventionMode = "NONE"
+	STPModeExpireTaker SelfTradePreventionMode = "EXPIRE_TAKER"
+	STPModeExpireMaker SelfTradePreventionMode = "EXPIRE_MAKER"
+	STPModeExpireBoth  SelfTradePreventionMode = "EXPIRE_BOTH"
+	STPModeDecrement   SelfTradePreventionMode = "DECREMENT"
+	STPModeTransfer    SelfTradePreventionMode = "TRANSFER"
+)
+
+var stpModes = []SelfTradePreventionMode{STPModeNone, STPModeExpireTaker, STPModeExpireMaker, STPModeExpireBoth,
+	STPModeDecrement, STPModeTransfer}
+
+func (m SelfTradePreventionMode) Validate() error {
+	return validateEnum("selfTradePreventionMode", m, stpModes)
+}
+
+func (m *SelfTradePreventionMode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("selfTradePreventionMode", data, m, stpModes)
+}
+
+// SelfTradePreventionModes modes allowed for a symbol, unknown modes are skipped
+type SelfTradePreventionModes []SelfTradePreventionMode
+
+func (m *SelfTradePreventionModes) UnmarshalJSON(data []byte) error {
+	return unmarshalKnownEnums("selfTradePreventionModes", data, (*[]SelfTradePreventionMode)(m), stpModes)
+}
+
+type NewOrderRespType string
+
+const (
+	NewOrderRespAck    NewOrderRespType = "ACK"
+	NewOrderRespResult NewOrderRespType = "RESULT"
+	NewOrderRespFull   NewOrderRespType = "FULL"
+)
+
+var newOrderRespTypes = []NewOrderRespType{NewOrderRespAck, NewOrderRespResult, NewOrderRespFull}
+
+func (t NewOrderRespType) Validate() error {
+	return validateEnum("newOrderRespType", t, newOrderRespTypes)
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPendingNew      OrderStatus = "PENDING_NEW" // leg of an order list waiting for its trigger
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusPendingCancel   OrderStatus = "PENDING_CANCEL" // not used by Binance at the moment
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+	OrderStatusExpiredInMatch  OrderStatus = "EXPIRED_IN_MATCH" // expired by self-trade prevention
+)
+
+var orderStatuses = []OrderStatus{OrderStatusNew, OrderStatusPendingNew, OrderStatusPartiallyFilled,
+	OrderStatusFilled, OrderStatusCanceled, OrderStatusPendingCancel, OrderStatusRejected, OrderStatusExpired,
+	OrderStatusExpiredInMatch}
+
+func (s OrderStatus) Validate() error { return validateEnum("order status", s, orderStatuses) }
+
+// IsOpen the order is on the book or waits for its trigger
+func (s OrderStatus) IsOpen() bool {
+	return s == OrderStatusNew || s == OrderStatusPartiallyFilled || s == OrderStatusPendingNew
+}
+
+func (s *OrderStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("order status", data, s, orderStatuses)
+}
+
+// ExecutionType of an execution report
+type ExecutionType string
+
+const (
+	ExecutionTypeNew             ExecutionType = "NEW"
+	ExecutionTypeCanceled        ExecutionType = "CANCELED"
+	ExecutionTypeReplaced        ExecutionType = "REPLACED" // not used by Binance at the moment
+	ExecutionTypeRejected        ExecutionType = "REJECTED"
+	ExecutionTypeTrade           ExecutionType = "TRADE"
+	ExecutionTypeExpired         ExecutionType = "EXPIRED"
+	ExecutionTypeTradePrevention ExecutionType = "TRADE_PREVENTION" // expired by self-trade prevention
+)
+
+var executionTypes = []ExecutionType{ExecutionTypeNew, ExecutionTypeCanceled, ExecutionTypeReplaced,
+	ExecutionTypeRejected, ExecutionTypeTrade, ExecutionTypeExpired, ExecutionTypeTradePrevention}
+
+func (t ExecutionType) Validate() error { return validateEnum("execution type", t, executionTypes) }
+
+func (t *ExecutionType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("execution type", data, t, executionTypes)
+}
+
+type ContingencyType string
+
+const (
+	ContingencyTypeOCO ContingencyType = "OCO"
+	ContingencyTypeOTO ContingencyType = "OTO" // the working order places the pending order when filled
+)
+
+var contingencyTypes = []ContingencyType{ContingencyTypeOCO, ContingencyTypeOTO}
+
+func (t *ContingencyType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("contingencyType", data, t, contingencyTypes)
+}
+
+// ListStatusType status of an order list
+type ListStatusType string
+
+const (
+	ListStatusTypeResponse    ListStatusType = "RESPONSE" // list status of a failed action
+	ListStatusTypeExecStarted ListStatusType = "EXEC_STARTED"
+	ListStatusTypeAllDone     ListStatusType = "ALL_DONE"
+)
+
+var listStatusTypes = []ListStatusType{ListStatusTypeResponse, ListStatusTypeExecStarted, ListStatusTypeAllDone}
+
+func (t *ListStatusType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("listStatusType", data, t, listStatusTypes)
+}
+
+// ListOrderStatus status of the orders of an order list
+type ListOrderStatus string
+
+const (
+	ListOrderStatusExecuting ListOrderStatus = "EXECUTING"
+	ListOrderStatusAllDone   ListOrderStatus = "ALL_DONE"
+	ListOrderStatusReject    ListOrderStatus = "REJECT"
+)
+
+var listOrderStatuses = []ListOrderStatus{ListOrderStatusExecuting, ListOrderStatusAllDone, ListOrderStatusReject}
+
+func (s *ListOrderStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("listOrderStatus", data, s, listOrderStatuses)
+}
+
+type CancelReplaceMode string
+
+const (
+	CancelReplaceStopOnFailure CancelReplaceMode = "STOP_ON_FAILURE" // new order is not placed when the cancel fails
+	CancelReplaceAllowFailure  CancelReplaceMode = "ALLOW_FAILURE"   // new order is placed even when the cancel fails
+)
+
+var cancelReplaceModes = []CancelReplaceMode{CancelReplaceStopOnFailure, CancelReplaceAllowFailure}
+
+func (m CancelReplaceMode) Validate() error {
+	return validateEnum("cancelReplaceMode", m, cancelReplaceModes)
+}
+
+// CancelRestrictions cancels the order only in the status
+type CancelRestrictions string
+
+const (
+	CancelOnlyNew             CancelRestrictions = "ONLY_NEW"
+	CancelOnlyPartiallyFilled CancelRestrictions = "ONLY_PARTIALLY_FILLED"
+)
+
+var cancelRestrictions = []CancelRestrictions{CancelOnlyNew, CancelOnlyPartiallyFilled}
+
+func (r CancelRestrictions) Validate() error {
+	return validateEnum("cancelRestrictions", r, cancelRestrictions)
+}
+
+// CancelReplaceResult of each part of CancelReplace
+type CancelReplaceResult string
+
+const (
+	CancelReplaceSuccess      CancelReplaceResult = "SUCCESS"
+	CancelReplaceFailure      CancelReplaceResult = "FAILURE"
+	CancelReplaceNotAttempted CancelReplaceResult = "NOT_ATTEMPTED"
+)
+
+var cancelReplaceResults = []CancelReplaceResult{CancelReplaceSuccess, CancelReplaceFailure, CancelReplaceNotAttempted}
+
+func (r *CancelReplaceResult) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("cancelReplace result", data, r, cancelReplaceResults)
+}
+
+// optional validates the value when it is set, empty values are left to Binance defaults
+func optional[T interface {
+	~string
+	Validate() error
+}](value T) error {
+	if value == "" {
+		return nil
+	}
+	return value.Validate()
+}
+
+func validateEnum[T ~string](name string, value T, values []T) error {
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q, expected one of %v", name, value, values)
+}
+
+// unmarshalEnum decodes a JSON string, null leaves the value unchanged
+func unmarshalEnum[T ~string](name string, data []byte, value *T, values []T) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := validateEnum(name, T(s), values); err != nil {
+		return err
+	}
+	*value = T(s)
+	return nil
+}
+
+// unmarshalKnownEnums decodes a JSON array of strings keeping only the known values
+func unmarshalKnownEnums[T ~string](name string, data []byte, value *[]T, values []T) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if list == nil {
+		*value = nil
+		return nil
+	}
+	known := make([]T, 0, len(list))
+	for _, s := range list {
+		if validateEnum(name, T(s), values) == nil {
+			known = append(known, T(s))
+		}
+	}
+	*value = known
+	return nil
+}
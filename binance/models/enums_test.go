@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnumDecoding(t *testing.T) {
+	var r struct {
+		Side        Side            `json:"side"`
+		Type        OrderType       `json:"type"`
+		Status      OrderStatus     `json:"status"`
+		Execution   ExecutionType   `json:"x"`
+		Contingency ContingencyType `json:"contingencyType"`
+	}
+
+	data := `{"side":"SELL","type":"LIMIT_MAKER","status":"EXPIRED_IN_MATCH","x":"TRADE_PREVENTION","contingencyType":"OTO"}`
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Side != SideSell || r.Type != OrderTypeLimitMaker || r.Status != OrderStatusExpiredInMatch ||
+		r.Execution != ExecutionTypeTradePrevention || r.Contingency != ContingencyTypeOTO {
+		t.Fatalf("decoded %+v", r)
+	}
+}
+
+func TestEnumDecodingRejectsUnknownValues(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		into interface{}
+	}{
+		{"lower case side", `"buy"`, new(Side)},
+		{"unknown order type", `"TRAILING"`, new(OrderType)},
+		{"unknown status", `"DONE"`, new(OrderStatus)},
+		{"unknown time in force", `"GTX"`, new(TimeInForce)},
+		{"not a string", `1`, new(OrderStatus)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.data), tt.into); err == nil {
+				t.Fatalf("%s decoded without error", tt.data)
+			}
+		})
+	}
+}
+
+func TestEnumDecodingKeepsValueOnNull(t *testing.T) {
+	side := SideBuy
+	if err := json.Unmarshal([]byte(`null`), &side); err != nil {
+		t.Fatal(err)
+	}
+	if side != SideBuy {
+		t.Fatalf("side = %s, want unchanged BUY", side)
+	}
+}
+
+func TestSymbolEnumListsSkipUnknownValues(t *testing.T) {
+	data := `{"symbol":"BTCUSDT","orderTypes":["LIMIT","NEW_ORDER_TYPE","MARKET"],
+		"allowedSelfTradePreventionModes":["EXPIRE_TAKER","NEW_MODE"]}`
+
+	var info SymbolInfo
+	if err := json.Unmarshal([]byte(data), &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.OrderTypes) != 2 || info.OrderTypes[0] != OrderTypeLimit || info.OrderTypes[1] != OrderTypeMarket {
+		t.Fatalf("order types = %v, want LIMIT and MARKET", info.OrderTypes)
+	}
+	if len(info.AllowedSelfTradePreventionModes) != 1 || info.AllowedSelfTradePreventionModes[0] != STPModeExpireTaker {
+		t.Fatalf("STP modes = %v, want EXPIRE_TAKER", info.AllowedSelfTradePreventionModes)
+	}
+}
+
+func TestEnumValidation(t *testing.T) {
+	if err := Side("BUY").Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := OrderType("limit").Validate(); err == nil {
+		t.Fatal("lower case order type is valid")
+	}
+	if err := optional(TimeInForce("")); err != nil {
+		t.Fatalf("empty optional value: %v", err)
+	}
+	if err := optional(TimeInForce("GTD")); err == nil {
+		t.Fatal("unknown optional value is valid")
+	}
+}
+
+func TestOrderStatusIsOpen(t *testing.T) {
+	open := map[OrderStatus]bool{
+		OrderStatusNew:             true,
+		OrderStatusPendingNew:      true,
+		OrderStatusPartiallyFilled: true,
+		OrderStatusFilled:          false,
+		OrderStatusCanceled:        false,
+		OrderStatusRejected:        false,
+		OrderStatusExpired:         false,
+		OrderStatusExpiredInMatch:  false,
+	}
+	for status, want := range open {
+		if status.IsOpen() != want {
+			t.Errorf("%s.IsOpen() = %v, want %v", status, !want, want)
+		}
+	}
+}
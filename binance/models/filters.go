@@ -0,0 +1,290 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateaway/binance"
+	"reflect"
+
+	"github.com/shopspring/decimal"
+)
+
+// Symbol filter types
+// https://binance-docs.github.io/apidocs/spot/en/#filters
+const (
+	FilterPrice              = "PRICE_FILTER"
+	FilterPercentPriceBySide = "PERCENT_PRICE_BY_SIDE"
+	FilterLotSize            = "LOT_SIZE"
+	FilterMarketLotSize      = "MARKET_LOT_SIZE"
+	FilterMinNotional        = "MIN_NOTIONAL"
+	FilterNotional           = "NOTIONAL"
+	FilterIcebergParts       = "ICEBERG_PARTS"
+	FilterMaxNumOrders       = "MAX_NUM_ORDERS"
+	FilterMaxNumAlgoOrders   = "MAX_NUM_ALGO_ORDERS"
+)
+
+// PriceFilter price range and tick size, zero disables the rule
+type PriceFilter struct {
+	MinPrice decimal.Decimal `json:"minPrice"`
+	MaxPrice decimal.Decimal `json:"maxPrice"`
+	TickSize decimal.Decimal `json:"tickSize"`
+}
+
+// PercentPriceBySideFilter price range relative to the weighted average price of the last avgPriceMins minutes
+type PercentPriceBySideFilter struct {
+	BidMultiplierUp   decimal.Decimal `json:"bidMultiplierUp"`
+	BidMultiplierDown decimal.Decimal `json:"bidMultiplierDown"`
+	AskMultiplierUp   decimal.Decimal `json:"askMultiplierUp"`
+	AskMultiplierDown decimal.Decimal `json:"askMultiplierDown"`
+	AvgPriceMins      int             `json:"avgPriceMins"`
+}
+
+// LotSizeFilter quantity range and step size, used by LOT_SIZE and MARKET_LOT_SIZE
+type LotSizeFilter struct {
+	FilterType string          `json:"filterType"`
+	MinQty     decimal.Decimal `json:"minQty"`
+	MaxQty     decimal.Decimal `json:"maxQty"`
+	StepSize   decimal.Decimal `json:"stepSize"`
+}
+
+// MinNotionalFilter minimum price * quantity of an order
+type MinNotionalFilter struct {
+	MinNotional   decimal.Decimal `json:"minNotional"`
+	ApplyToMarket bool            `json:"applyToMarket"`
+	AvgPriceMins  int             `json:"avgPriceMins"`
+}
+
+// NotionalFilter price * quantity range of an order
+type NotionalFilter struct {
+	MinNotional      decimal.Decimal `json:"minNotional"`
+	ApplyMinToMarket bool            `json:"applyMinToMarket"`
+	MaxNotional      decimal.Decimal `json:"maxNotional"`
+	ApplyMaxToMarket bool            `json:"applyMaxToMarket"`
+	AvgPriceMins     int             `json:"avgPriceMins"`
+}
+
+// IcebergPartsFilter maximum number of parts of an iceberg order
+type IcebergPartsFilter struct {
+	Limit int `json:"limit"`
+}
+
+// MaxNumOrdersFilter maximum number of open orders of the symbol
+type MaxNumOrdersFilter struct {
+	MaxNumOrders int `json:"maxNumOrders"`
+}
+
+// MaxNumAlgoOrdersFilter maximum number of open stop and take profit orders of the symbol
+type MaxNumAlgoOrdersFilter struct {
+	MaxNumAlgoOrders int `json:"maxNumAlgoOrders"`
+}
+
+// SymbolFilters typed filters of the symbol, nil if the symbol does not have the filter
+type SymbolFilters struct {
+	Price              *PriceFilter
+	PercentPriceBySide *PercentPriceBySideFilter
+	LotSize            *LotSizeFilter
+	MarketLotSize      *LotSizeFilter
+	MinNotional        *MinNotionalFilter
+	Notional           *NotionalFilter
+	IcebergParts       *IcebergPartsFilter
+	MaxNumOrders       *MaxNumOrdersFilter
+	MaxNumAlgoOrders   *MaxNumAlgoOrdersFilter
+	Other              []json.RawMessage // filters not known by the client
+}
+
+func (f *SymbolFilters) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for _, filter := range raw {
+		header := struct {
+			FilterType string `json:"filterType"`
+		}{}
+		if err := json.Unmarshal(filter, &header); err != nil {
+			return err
+		}
+
+		var target interface{}
+		switch header.FilterType {
+		case FilterPrice:
+			f.Price = new(PriceFilter)
+			target = f.Price
+		case FilterPercentPriceBySide:
+			f.PercentPriceBySide = new(PercentPriceBySideFilter)
+			target = f.PercentPriceBySide
+		case FilterLotSize:
+			f.LotSize = new(LotSizeFilter)
+			target = f.LotSize
+		case FilterMarketLotSize:
+			f.MarketLotSize = new(LotSizeFilter)
+			target = f.MarketLotSize
+		case FilterMinNotional:
+			f.MinNotional = new(MinNotionalFilter)
+			target = f.MinNotional
+		case FilterNotional:
+			f.Notional = new(NotionalFilter)
+			target = f.Notional
+		case FilterIcebergParts:
+			f.IcebergParts = new(IcebergPartsFilter)
+			target = f.IcebergParts
+		case FilterMaxNumOrders:
+			f.MaxNumOrders = new(MaxNumOrdersFilter)
+			target = f.MaxNumOrders
+		case FilterMaxNumAlgoOrders:
+			f.MaxNumAlgoOrders = new(MaxNumAlgoOrdersFilter)
+			target = f.MaxNumAlgoOrders
+		default:
+			f.Other = append(f.Other, filter)
+			continue
+		}
+
+		if err := json.Unmarshal(filter, target); err != nil {
+			return fmt.Errorf("error json parsing %s: %s", header.FilterType, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (f SymbolFilters) MarshalJSON() ([]byte, error) {
+	filters := []struct {
+		filterType string
+		filter     interface{}
+	}{
+		{FilterPrice, f.Price},
+		{FilterPercentPriceBySide, f.PercentPriceBySide},
+		{FilterLotSize, f.LotSize},
+		{FilterMarketLotSize, f.MarketLotSize},
+		{FilterMinNotional, f.MinNotional},
+		{FilterNotional, f.Notional},
+		{FilterIcebergParts, f.IcebergParts},
+		{FilterMaxNumOrders, f.MaxNumOrders},
+		{FilterMaxNumAlgoOrders, f.MaxNumAlgoOrders},
+	}
+
+	raw := make([]json.RawMessage, 0, len(filters)+len(f.Other))
+	for _, filter := range filters {
+		if reflect.ValueOf(filter.filter).IsNil() {
+			continue
+		}
+
+		data, err := json.Marshal(filter.filter)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		fields["filterType"], _ = json.Marshal(filter.filterType)
+
+		if data, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+		raw = append(raw, data)
+	}
+
+	return json.Marshal(append(raw, f.Other...))
+}
+
+// filterError wraps binance.ErrFilterFailure so that local rejections are handled the same way as -1013
+func filterError(filter, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s: %s", binance.ErrFilterFailure, filter, fmt.Sprintf(format, args...))
+}
+
+// CheckPrice checks price against PRICE_FILTER
+func (f *PriceFilter) CheckPrice(price decimal.Decimal) error {
+	if f == nil || price.IsZero() {
+		return nil
+	}
+	if f.MinPrice.IsPositive() && price.LessThan(f.MinPrice) {
+		return filterError(FilterPrice, "price %s is below minPrice %s", price, f.MinPrice)
+	}
+	if f.MaxPrice.IsPositive() && price.GreaterThan(f.MaxPrice) {
+		return filterError(FilterPrice, "price %s is above maxPrice %s", price, f.MaxPrice)
+	}
+	if f.TickSize.IsPositive() && !price.Sub(f.MinPrice).Mod(f.TickSize).IsZero() {
+		return filterError(FilterPrice, "price %s is not a multiple of tickSize %s", price, f.TickSize)
+	}
+	return nil
+}
+
+// CheckQuantity checks quantity against LOT_SIZE or MARKET_LOT_SIZE
+func (f *LotSizeFilter) CheckQuantity(quantity decimal.Decimal) error {
+	if f == nil || quantity.IsZero() {
+		return nil
+	}
+	if f.MinQty.IsPositive() && quantity.LessThan(f.MinQty) {
+		return filterError(f.FilterType, "quantity %s is below minQty %s", quantity, f.MinQty)
+	}
+	if f.MaxQty.IsPositive() && quantity.GreaterThan(f.MaxQty) {
+		return filterError(f.FilterType, "quantity %s is above maxQty %s", quantity, f.MaxQty)
+	}
+	if f.StepSize.IsPositive() && !quantity.Sub(f.MinQty).Mod(f.StepSize).IsZero() {
+		return filterError(f.FilterType, "quantity %s is not a multiple of stepSize %s", quantity, f.StepSize)
+	}
+	return nil
+}
+
+// CheckNotional checks price * quantity of a limit order against MIN_NOTIONAL and NOTIONAL
+func (f *SymbolFilters) CheckNotional(price, quantity decimal.Decimal) error {
+	if price.IsZero() || quantity.IsZero() {
+		return nil
+	}
+
+	notional := price.Mul(quantity)
+	if f.MinNotional != nil && notional.LessThan(f.MinNotional.MinNotional) {
+		return filterError(FilterMinNotional, "notional %s is below minNotional %s", notional, f.MinNotional.MinNotional)
+	}
+	if f.Notional != nil {
+		if notional.LessThan(f.Notional.MinNotional) {
+			return filterError(FilterNotional, "notional %s is below minNotional %s", notional, f.Notional.MinNotional)
+		}
+		if f.Notional.MaxNotional.IsPositive() && notional.GreaterThan(f.Notional.MaxNotional) {
+			return filterError(FilterNotional, "notional %s is above maxNotional %s", notional, f.Notional.MaxNotional)
+		}
+	}
+	return nil
+}
+
+// CheckIcebergParts checks that the order is split into no more than the limit of parts
+func (f *IcebergPartsFilter) CheckIcebergParts(quantity, icebergQty decimal.Decimal) error {
+	if f == nil || !icebergQty.IsPositive() {
+		return nil
+	}
+	if parts := quantity.Div(icebergQty).Ceil(); parts.GreaterThan(decimal.NewFromInt(int64(f.Limit))) {
+		return filterError(FilterIcebergParts, "iceberg order has %s parts, limit is %d", parts, f.Limit)
+	}
+	return nil
+}
+
+// CheckOpenOrders checks that one more order can be opened when openOrders are already open
+func (f *MaxNumOrdersFilter) CheckOpenOrders(openOrders int) error {
+	if f == nil || openOrders < f.MaxNumOrders {
+		return nil
+	}
+	return filterError(FilterMaxNumOrders, "%d orders are open, limit is %d", openOrders, f.MaxNumOrders)
+}
+
+// CheckPrice checks price of the side against the weighted average price, e.g. of GetAvgPrice
+func (f *PercentPriceBySideFilter) CheckPrice(side Side, price, avgPrice decimal.Decimal) error {
+	if f == nil || price.IsZero() || avgPrice.IsZero() {
+		return nil
+	}
+
+	up, down := f.BidMultiplierUp, f.BidMultiplierDown
+	if side == SideSell {
+		up, down = f.AskMultiplierUp, f.AskMultiplierDown
+	}
+
+	if low := avgPrice.Mul(down); price.LessThan(low) {
+		return filterError(FilterPercentPriceBySide, "%s price %s is below %s", side, price, low)
+	}
+	if high := avgPrice.Mul(up); price.GreaterThan(high) {
+		return filterError(FilterPercentPriceBySide, "%s price %s is above %s", side, price, high)
+	}
+	return nil
+}
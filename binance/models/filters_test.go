@@ -0,0 +1,129 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"gateaway/binance"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func TestFilterChecks(t *testing.T) {
+	price := &PriceFilter{MinPrice: d("0.01"), MaxPrice: d("1000"), TickSize: d("0.01")}
+	lotSize := &LotSizeFilter{FilterType: FilterLotSize, MinQty: d("0.001"), MaxQty: d("100"), StepSize: d("0.001")}
+	filters := &SymbolFilters{
+		MinNotional: &MinNotionalFilter{MinNotional: d("5")},
+		Notional:    &NotionalFilter{MinNotional: d("5"), MaxNotional: d("10000")},
+	}
+	iceberg := &IcebergPartsFilter{Limit: 10}
+	maxNumOrders := &MaxNumOrdersFilter{MaxNumOrders: 200}
+	bySide := &PercentPriceBySideFilter{BidMultiplierUp: d("1.1"), BidMultiplierDown: d("0.5"),
+		AskMultiplierUp: d("2"), AskMultiplierDown: d("0.9")}
+
+	tests := []struct {
+		name   string
+		err    error
+		filter string // empty when the check passes
+	}{
+		{"price on tick", price.CheckPrice(d("10.25")), ""},
+		{"price off tick", price.CheckPrice(d("10.255")), FilterPrice},
+		{"price below min", price.CheckPrice(d("0.001")), FilterPrice},
+		{"price above max", price.CheckPrice(d("1000.01")), FilterPrice},
+		{"price not set", price.CheckPrice(decimal.Zero), ""},
+		{"quantity on step", lotSize.CheckQuantity(d("1.234")), ""},
+		{"quantity off step", lotSize.CheckQuantity(d("1.2345")), FilterLotSize},
+		{"quantity below min", lotSize.CheckQuantity(d("0.0001")), FilterLotSize},
+		{"quantity above max", lotSize.CheckQuantity(d("101")), FilterLotSize},
+		{"notional", filters.CheckNotional(d("10"), d("1")), ""},
+		{"notional below min", filters.CheckNotional(d("1"), d("1")), FilterMinNotional},
+		{"notional above max", filters.CheckNotional(d("1000"), d("11")), FilterNotional},
+		{"iceberg parts", iceberg.CheckIcebergParts(d("10"), d("1")), ""},
+		{"too many iceberg parts", iceberg.CheckIcebergParts(d("10"), d("0.9")), FilterIcebergParts},
+		{"open orders below max", maxNumOrders.CheckOpenOrders(199), ""},
+		{"open orders at max", maxNumOrders.CheckOpenOrders(200), FilterMaxNumOrders},
+		{"bid within band", bySide.CheckPrice(SideBuy, d("105"), d("100")), ""},
+		{"bid above band", bySide.CheckPrice(SideBuy, d("111"), d("100")), FilterPercentPriceBySide},
+		{"bid below band", bySide.CheckPrice(SideBuy, d("49"), d("100")), FilterPercentPriceBySide},
+		{"ask above bid band", bySide.CheckPrice(SideSell, d("150"), d("100")), ""},
+		{"ask below band", bySide.CheckPrice(SideSell, d("89"), d("100")), FilterPercentPriceBySide},
+		{"average price not known", bySide.CheckPrice(SideBuy, d("1"), decimal.Zero), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter == "" {
+				if tt.err != nil {
+					t.Fatalf("unexpected error: %v", tt.err)
+				}
+				return
+			}
+			if !errors.Is(tt.err, binance.ErrFilterFailure) {
+				t.Fatalf("error = %v, want %s failure", tt.err, tt.filter)
+			}
+		})
+	}
+}
+
+func TestMissingFiltersAreNotChecked(t *testing.T) {
+	var price *PriceFilter
+	var lotSize *LotSizeFilter
+	var maxNumOrders *MaxNumOrdersFilter
+
+	if err := price.CheckPrice(d("0.0000001")); err != nil {
+		t.Fatal(err)
+	}
+	if err := lotSize.CheckQuantity(d("0.0000001")); err != nil {
+		t.Fatal(err)
+	}
+	if err := maxNumOrders.CheckOpenOrders(1000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSymbolFiltersJSON(t *testing.T) {
+	data := []byte(`[
+		{"filterType":"PRICE_FILTER","minPrice":"0.01","maxPrice":"1000000","tickSize":"0.01"},
+		{"filterType":"LOT_SIZE","minQty":"0.00001","maxQty":"9000","stepSize":"0.00001"},
+		{"filterType":"MAX_NUM_ORDERS","maxNumOrders":200},
+		{"filterType":"TRAILING_DELTA","minTrailingAboveDelta":10}
+	]`)
+
+	var filters SymbolFilters
+	if err := json.Unmarshal(data, &filters); err != nil {
+		t.Fatal(err)
+	}
+	if !filters.Price.TickSize.Equal(d("0.01")) || !filters.LotSize.StepSize.Equal(d("0.00001")) {
+		t.Fatalf("filters = %+v", filters)
+	}
+	if filters.MaxNumOrders.MaxNumOrders != 200 || len(filters.Other) != 1 {
+		t.Fatalf("filters = %+v, want MAX_NUM_ORDERS and one unknown filter", filters)
+	}
+
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded SymbolFilters
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Price.MaxPrice.Equal(d("1000000")) || len(decoded.Other) != 1 {
+		t.Fatalf("round trip = %s", encoded)
+	}
+}
+
+func TestRoundToSymbolSteps(t *testing.T) {
+	info := SymbolInfo{Filters: SymbolFilters{
+		Price:   &PriceFilter{MinPrice: d("0.01"), TickSize: d("0.01")},
+		LotSize: &LotSizeFilter{MinQty: d("0.001"), StepSize: d("0.001")},
+	}}
+
+	if price := info.RoundPrice(d("10.256")); !price.Equal(d("10.26")) {
+		t.Fatalf("RoundPrice = %s, want 10.26", price)
+	}
+	if quantity := info.RoundQuantity(d("1.2349")); !quantity.Equal(d("1.234")) {
+		t.Fatalf("RoundQuantity = %s, want 1.234", quantity)
+	}
+}
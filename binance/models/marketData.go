@@ -1,6 +1,19 @@
 package models
 
-import "errors"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+type ServerTimeResponse struct {
+	ServerTime int64 `json:"serverTime"`
+}
 
 type TradesRequest struct {
 	Symbol string `url:"symbol"`
@@ -15,11 +28,398 @@ func (r *TradesRequest) Validate() error {
 }
 
 type TradesResponse struct {
-	Id           int    `json:"id"`
-	Price        string `json:"price"`
-	Qty          string `json:"qty"`
-	QuoteQty     string `json:"quoteQty"`
-	Time         int64  `json:"time"`
-	IsBuyerMaker bool   `json:"isBuyerMaker"`
-	IsBestMatch  bool   `json:"isBestMatch"`
+	Id           int             `json:"id"`
+	Price        decimal.Decimal `json:"price"`
+	Qty          decimal.Decimal `json:"qty"`
+	QuoteQty     decimal.Decimal `json:"quoteQty"`
+	Time         int64           `json:"time"`
+	IsBuyerMaker bool            `json:"isBuyerMaker"`
+	IsBestMatch  bool            `json:"isBestMatch"`
+}
+
+// StringArray parameter sent as JSON array, e.g. symbols=["BTCUSDT","BNBUSDT"]
+type StringArray []string
+
+// EncodeValues implements query.Encoder
+func (a StringArray) EncodeValues(key string, v *url.Values) error {
+	data, err := json.Marshal([]string(a))
+	if err != nil {
+		return err
+	}
+	v.Set(key, string(data))
+	return nil
+}
+
+// checkSymbols symbol and symbols are mutually exclusive, one of them is required if required is set
+func checkSymbols(symbol string, symbols StringArray, required bool) error {
+	if symbol != "" && len(symbols) > 0 {
+		return errors.New("symbol and symbols can not be sent together")
+	}
+	if required && symbol == "" && len(symbols) == 0 {
+		return errors.New("symbol or symbols is required")
+	}
+	return nil
+}
+
+// unmarshalList decodes an array or a single object into the slice, ticker endpoints return
+// an object for symbol and an array for symbols
+func unmarshalList(data []byte, target interface{}) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	return json.Unmarshal(data, target)
+}
+
+// EXCHANGE INFO
+
+// ExchangeInfoRequest symbol, symbols and permissions are mutually exclusive, all symbols are returned without them
+type ExchangeInfoRequest struct {
+	Symbol      string      `url:"symbol,omitempty"`
+	Symbols     StringArray `url:"symbols,omitempty"`
+	Permissions StringArray `url:"permissions,omitempty"`
+}
+
+func (r *ExchangeInfoRequest) Validate() error {
+	if err := checkSymbols(r.Symbol, r.Symbols, false); err != nil {
+		return err
+	}
+	if len(r.Permissions) > 0 && (r.Symbol != "" || len(r.Symbols) > 0) {
+		return errors.New("permissions can not be combined with symbol or symbols")
+	}
+	return nil
+}
+
+// OLD TRADE LOOKUP
+
+type HistoricalTradesRequest struct {
+	Symbol string `url:"symbol"`
+	Limit  int    `url:"limit,omitempty"`
+	FromID *int64 `url:"fromId,omitempty"`
+}
+
+func (r *HistoricalTradesRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	return nil
+}
+
+// COMPRESSED/AGGREGATE TRADES LIST
+
+type AggTradesRequest struct {
+	Symbol    string `url:"symbol"`
+	FromID    *int64 `url:"fromId,omitempty"`
+	StartTime *int64 `url:"startTime,omitempty"`
+	EndTime   *int64 `url:"endTime,omitempty"`
+	Limit     int    `url:"limit,omitempty"`
+}
+
+func (r *AggTradesRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	if r.FromID != nil && (r.StartTime != nil || r.EndTime != nil) {
+		return errors.New("fromId can not be combined with startTime or endTime")
+	}
+	if r.StartTime != nil && r.EndTime != nil && *r.EndTime < *r.StartTime {
+		return errors.New("endTime must not be before startTime")
+	}
+	return nil
+}
+
+type AggTradesResponse struct {
+	AggTradeId   int64           `json:"a"`
+	Price        decimal.Decimal `json:"p"`
+	Qty          decimal.Decimal `json:"q"`
+	FirstTradeId int64           `json:"f"`
+	LastTradeId  int64           `json:"l"`
+	Time         int64           `json:"T"`
+	IsBuyerMaker bool            `json:"m"`
+	IsBestMatch  bool            `json:"M"`
+}
+
+// KLINE/CANDLESTICK DATA
+
+// KlineIntervals supported by klines and uiKlines
+var KlineIntervals = []string{"1s", "1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w", "1M"}
+
+// KlinesRequest is used by klines and uiKlines
+type KlinesRequest struct {
+	Symbol    string `url:"symbol"`
+	Interval  string `url:"interval"`
+	StartTime *int64 `url:"startTime,omitempty"`
+	EndTime   *int64 `url:"endTime,omitempty"`
+	TimeZone  string `url:"timeZone,omitempty"`
+	Limit     int    `url:"limit,omitempty"`
+}
+
+func (r *KlinesRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+
+	// Intervals are case sensitive, 1m is a minute and 1M is a month
+	valid := false
+	for _, interval := range KlineIntervals {
+		if interval == r.Interval {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid interval %q", r.Interval)
+	}
+
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	if r.StartTime != nil && r.EndTime != nil && *r.EndTime < *r.StartTime {
+		return errors.New("endTime must not be before startTime")
+	}
+	return nil
+}
+
+// Kline candlestick, Binance sends it as an array
+type Kline struct {
+	OpenTime                 int64
+	Open                     decimal.Decimal
+	High                     decimal.Decimal
+	Low                      decimal.Decimal
+	Close                    decimal.Decimal
+	Volume                   decimal.Decimal
+	CloseTime                int64
+	QuoteAssetVolume         decimal.Decimal
+	NumberOfTrades           int64
+	TakerBuyBaseAssetVolume  decimal.Decimal
+	TakerBuyQuoteAssetVolume decimal.Decimal
+}
+
+func (k *Kline) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) < 11 {
+		return fmt.Errorf("kline has %d fields, expected at least 11", len(fields))
+	}
+
+	targets := []interface{}{
+		&k.OpenTime, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume, &k.CloseTime,
+		&k.QuoteAssetVolume, &k.NumberOfTrades, &k.TakerBuyBaseAssetVolume, &k.TakerBuyQuoteAssetVolume,
+	}
+	for i, target := range targets {
+		if err := json.Unmarshal(fields[i], target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k Kline) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{
+		k.OpenTime, k.Open, k.High, k.Low, k.Close, k.Volume, k.CloseTime,
+		k.QuoteAssetVolume, k.NumberOfTrades, k.TakerBuyBaseAssetVolume, k.TakerBuyQuoteAssetVolume, "0",
+	})
+}
+
+// CURRENT AVERAGE PRICE
+
+type AvgPriceRequest struct {
+	Symbol string `url:"symbol"`
+}
+
+func (r *AvgPriceRequest) Validate() error {
+	if r.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	return nil
+}
+
+type AvgPriceResponse struct {
+	Mins      int             `json:"mins"`
+	Price     decimal.Decimal `json:"price"`
+	CloseTime int64           `json:"closeTime"`
+}
+
+// 24HR TICKER PRICE CHANGE STATISTICS
+
+// Ticker24hrRequest all symbols are returned without symbol and symbols, type is FULL (default) or MINI
+type Ticker24hrRequest struct {
+	Symbol  string      `url:"symbol,omitempty"`
+	Symbols StringArray `url:"symbols,omitempty"`
+	Type    string      `url:"type,omitempty"`
+}
+
+func (r *Ticker24hrRequest) Validate() error {
+	if err := checkSymbols(r.Symbol, r.Symbols, false); err != nil {
+		return err
+	}
+	return checkTickerType(r.Type)
+}
+
+func checkTickerType(tickerType string) error {
+	if tickerType != "" && tickerType != "FULL" && tickerType != "MINI" {
+		return errors.New("type must be either FULL or MINI")
+	}
+	return nil
+}
+
+// Ticker24hrResponse bid, ask and previous close fields are empty for MINI type
+type Ticker24hrResponse struct {
+	Symbol             string          `json:"symbol"`
+	PriceChange        decimal.Decimal `json:"priceChange"`
+	PriceChangePercent decimal.Decimal `json:"priceChangePercent"`
+	WeightedAvgPrice   decimal.Decimal `json:"weightedAvgPrice"`
+	PrevClosePrice     decimal.Decimal `json:"prevClosePrice"`
+	LastPrice          decimal.Decimal `json:"lastPrice"`
+	LastQty            decimal.Decimal `json:"lastQty"`
+	BidPrice           decimal.Decimal `json:"bidPrice"`
+	BidQty             decimal.Decimal `json:"bidQty"`
+	AskPrice           decimal.Decimal `json:"askPrice"`
+	AskQty             decimal.Decimal `json:"askQty"`
+	OpenPrice          decimal.Decimal `json:"openPrice"`
+	HighPrice          decimal.Decimal `json:"highPrice"`
+	LowPrice           decimal.Decimal `json:"lowPrice"`
+	Volume             decimal.Decimal `json:"volume"`
+	QuoteVolume        decimal.Decimal `json:"quoteVolume"`
+	OpenTime           int64           `json:"openTime"`
+	CloseTime          int64           `json:"closeTime"`
+	FirstId            int64           `json:"firstId"`
+	LastId             int64           `json:"lastId"`
+	Count              int64           `json:"count"`
+}
+
+type Ticker24hrList []Ticker24hrResponse
+
+func (l *Ticker24hrList) UnmarshalJSON(data []byte) error {
+	return unmarshalList(data, (*[]Ticker24hrResponse)(l))
+}
+
+// TRADING DAY TICKER AND ROLLING WINDOW PRICE CHANGE STATISTICS
+
+// TradingDayTickerRequest symbol or up to 100 symbols, timeZone defaults to 0 (UTC)
+type TradingDayTickerRequest struct {
+	Symbol   string      `url:"symbol,omitempty"`
+	Symbols  StringArray `url:"symbols,omitempty"`
+	TimeZone string      `url:"timeZone,omitempty"`
+	Type     string      `url:"type,omitempty"`
+}
+
+func (r *TradingDayTickerRequest) Validate() error {
+	if err := checkSymbols(r.Symbol, r.Symbols, true); err != nil {
+		return err
+	}
+	if len(r.Symbols) > 100 {
+		return errors.New("at most 100 symbols are allowed")
+	}
+	return checkTickerType(r.Type)
+}
+
+// RollingTickerRequest symbol or up to 100 symbols, windowSize is 1m-59m, 1h-23h or 1d-7d, 1d by default
+type RollingTickerRequest struct {
+	Symbol     string      `url:"symbol,omitempty"`
+	Symbols    StringArray `url:"symbols,omitempty"`
+	WindowSize string      `url:"windowSize,omitempty"`
+	Type       string      `url:"type,omitempty"`
+}
+
+func (r *RollingTickerRequest) Validate() error {
+	if err := checkSymbols(r.Symbol, r.Symbols, true); err != nil {
+		return err
+	}
+	if len(r.Symbols) > 100 {
+		return errors.New("at most 100 symbols are allowed")
+	}
+	if r.WindowSize != "" {
+		if err := checkWindowSize(r.WindowSize); err != nil {
+			return err
+		}
+	}
+	return checkTickerType(r.Type)
+}
+
+func checkWindowSize(windowSize string) error {
+	if len(windowSize) < 2 {
+		return fmt.Errorf("invalid windowSize %q", windowSize)
+	}
+
+	n, err := strconv.Atoi(windowSize[:len(windowSize)-1])
+	if err != nil {
+		return fmt.Errorf("invalid windowSize %q", windowSize)
+	}
+
+	max := map[byte]int{'m': 59, 'h': 23, 'd': 7}[windowSize[len(windowSize)-1]]
+	if n < 1 || n > max {
+		return fmt.Errorf("invalid windowSize %q", windowSize)
+	}
+	return nil
+}
+
+// TickerResponse statistics of trading day and rolling window tickers, MINI type omits price change and weighted average
+type TickerResponse struct {
+	Symbol             string          `json:"symbol"`
+	PriceChange        decimal.Decimal `json:"priceChange"`
+	PriceChangePercent decimal.Decimal `json:"priceChangePercent"`
+	WeightedAvgPrice   decimal.Decimal `json:"weightedAvgPrice"`
+	OpenPrice          decimal.Decimal `json:"openPrice"`
+	HighPrice          decimal.Decimal `json:"highPrice"`
+	LowPrice           decimal.Decimal `json:"lowPrice"`
+	LastPrice          decimal.Decimal `json:"lastPrice"`
+	Volume             decimal.Decimal `json:"volume"`
+	QuoteVolume        decimal.Decimal `json:"quoteVolume"`
+	OpenTime           int64           `json:"openTime"`
+	CloseTime          int64           `json:"closeTime"`
+	FirstId            int64           `json:"firstId"`
+	LastId             int64           `json:"lastId"`
+	Count              int64           `json:"count"`
+}
+
+type TickerList []TickerResponse
+
+func (l *TickerList) UnmarshalJSON(data []byte) error {
+	return unmarshalList(data, (*[]TickerResponse)(l))
+}
+
+// SYMBOL PRICE TICKER AND ORDER BOOK TICKER
+
+// TickerRequest is used by price and book tickers, all symbols are returned without symbol and symbols
+type TickerRequest struct {
+	Symbol  string      `url:"symbol,omitempty"`
+	Symbols StringArray `url:"symbols,omitempty"`
+}
+
+func (r *TickerRequest) Validate() error {
+	return checkSymbols(r.Symbol, r.Symbols, false)
+}
+
+type PriceTickerResponse struct {
+	Symbol string          `json:"symbol"`
+	Price  decimal.Decimal `json:"price"`
+}
+
+type PriceTickerList []PriceTickerResponse
+
+func (l *PriceTickerList) UnmarshalJSON(data []byte) error {
+	return unmarshalList(data, (*[]PriceTickerResponse)(l))
+}
+
+type BookTickerResponse struct {
+	Symbol   string          `json:"symbol"`
+	BidPrice decimal.Decimal `json:"bidPrice"`
+	BidQty   decimal.Decimal `json:"bidQty"`
+	AskPrice decimal.Decimal `json:"askPrice"`
+	AskQty   decimal.Decimal `json:"askQty"`
+}
+
+type BookTickerList []BookTickerResponse
+
+func (l *BookTickerList) UnmarshalJSON(data []byte) error {
+	return unmarshalList(data, (*[]BookTickerResponse)(l))
 }
@@ -3,37 +3,33 @@ package models
 import (
 	"errors"
 	"fmt"
+	"gateaway/binance"
 	"github.com/shopspring/decimal"
-	"strings"
 )
 
 // NEW ORDER
 
-type Side string
-
-type OrderType string
-
 type TestOrder struct{}
 
 // OrderRequest represents an order to be sent to Binance API.
 type OrderRequest struct {
-	Symbol                  string  `url:"symbol"`
-	Side                    string  `url:"side"`
-	Type                    string  `url:"type"`
-	TimeInForce             string  `url:"timeInForce,omitempty"`
-	Quantity                float32 `url:"quantity"`
-	QuoteOrderQty           float32 `url:"quoteOrderQty,omitempty"`
-	Price                   float32 `url:"price,omitempty"`
-	NewClientOrderID        string  `url:"newClientOrderId,omitempty"`
-	StopPrice               float32 `url:"stopPrice,omitempty"`
-	IcebergQty              float32 `url:"icebergQty,omitempty"`
-	NewOrderRespType        string  `url:"newOrderRespType,omitempty"`
-	RecvWindow              int64   `url:"recvWindow,omitempty"`
-	Timestamp               int64   `url:"timestamp"`
-	StrategyID              int     `url:"strategyId,omitempty"`
-	StrategyType            int     `url:"strategyType,omitempty"`
-	TrailingDelta           int64   `url:"trailingDelta,omitempty"`
-	SelfTradePreventionMode *string `url:"selfTradePreventionMode,omitempty"`
+	Symbol                  string                  `url:"symbol"`
+	Side                    Side                    `url:"side"`
+	Type                    OrderType               `url:"type"`
+	TimeInForce             TimeInForce             `url:"timeInForce,omitempty"`
+	Quantity                decimal.Decimal         `url:"quantity,omitempty"`
+	QuoteOrderQty           decimal.Decimal         `url:"quoteOrderQty,omitempty"`
+	Price                   decimal.Decimal         `url:"price,omitempty"`
+	NewClientOrderID        string                  `url:"newClientOrderId,omitempty"`
+	StopPrice               decimal.Decimal         `url:"stopPrice,omitempty"`
+	IcebergQty              decimal.Decimal         `url:"icebergQty,omitempty"`
+	NewOrderRespType        NewOrderRespType        `url:"newOrderRespType,omitempty"`
+	RecvWindow              int64                   `url:"recvWindow,omitempty"`
+	Timestamp               int64                   `url:"timestamp,omitempty"`
+	StrategyID              int                     `url:"strategyId,omitempty"`
+	StrategyType            int                     `url:"strategyType,omitempty"`
+	TrailingDelta           int64                   `url:"trailingDelta,omitempty"`
+	SelfTradePreventionMode SelfTradePreventionMode `url:"selfTradePreventionMode,omitempty"`
 }
 
 // Validate request
@@ -48,46 +44,47 @@ func (o *OrderRequest) Validate() error {
 	if o.Type == "" {
 		return errors.New("type is required")
 	}
-	if o.Timestamp == 0 {
-		return errors.New("timestamp is required")
-	}
 
-	// Validate Side values
-	validSides := []string{"BUY", "SELL"}
-	if !stringInSlice(o.Side, validSides) {
-		return errors.New("Invalid Side value")
+	if err := o.Side.Validate(); err != nil {
+		return err
 	}
-
-	// Validate Type values
-	validTypes := []string{"LIMIT", "MARKET", "STOP_LOSS", "STOP_LOSS_LIMIT", "TAKE_PROFIT", "TAKE_PROFIT_LIMIT", "LIMIT_MAKER"}
-	if !stringInSlice(o.Type, validTypes) {
-		return errors.New("Invalid Type value")
+	if err := o.Type.Validate(); err != nil {
+		return err
 	}
-
-	// Validate TimeInForce if present
-	if o.TimeInForce != "" {
-		validTimeInForce := []string{"GTC", "IOC", "FOK"}
-		if !stringInSlice(o.TimeInForce, validTimeInForce) {
-			return errors.New("Invalid TimeInForce value")
-		}
+	if err := optional(o.TimeInForce); err != nil {
+		return err
+	}
+	if err := optional(o.NewOrderRespType); err != nil {
+		return err
+	}
+	if err := optional(o.SelfTradePreventionMode); err != nil {
+		return err
 	}
 
 	// Validate Quantity if present (should be greater than 0)
-	if o.Quantity != 0 && o.Quantity <= 0 {
+	if o.Quantity.IsNegative() {
 		return errors.New("quantity should be greater than 0")
 	}
 
+	if o.NewClientOrderID != "" {
+		if err := binance.ValidateClientOrderID(o.NewClientOrderID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// Helper function to check if a string exists in a slice
-func stringInSlice(str string, list []string) bool {
-	for _, v := range list {
-		if strings.EqualFold(v, str) {
-			return true
-		}
-	}
-	return false
+// CheckRules checks the order against the symbol rules of the registry
+func (o *OrderRequest) CheckRules(rules *SymbolRegistry) error {
+	return rules.check(o.Symbol, orderRules{
+		orderType:  o.Type,
+		side:       o.Side,
+		price:      o.Price,
+		stopPrice:  o.StopPrice,
+		quantity:   o.Quantity,
+		icebergQty: o.IcebergQty,
+	})
 }
 
 type OrderResponseAck struct {
@@ -99,145 +96,148 @@ type OrderResponseAck struct {
 }
 
 type OrderResponseResult struct {
-	Symbol                  string `json:"symbol"`
-	OrderId                 int    `json:"orderId"`
-	OrderListId             int    `json:"orderListId"`
-	ClientOrderId           string `json:"clientOrderId"`
-	TransactTime            int64  `json:"transactTime"`
-	Price                   string `json:"price"`
-	OrigQty                 string `json:"origQty"`
-	ExecutedQty             string `json:"executedQty"`
-	CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-	Status                  string `json:"status"`
-	TimeInForce             string `json:"timeInForce"`
-	Type                    string `json:"type"`
-	Side                    string `json:"side"`
-	WorkingTime             int64  `json:"workingTime"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	Symbol                  string                  `json:"symbol"`
+	OrderId                 int                     `json:"orderId"`
+	OrderListId             int                     `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId"`
+	TransactTime            int64                   `json:"transactTime"`
+	Price                   decimal.Decimal         `json:"price"`
+	OrigQty                 decimal.Decimal         `json:"origQty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+	Status                  OrderStatus             `json:"status"`
+	TimeInForce             TimeInForce             `json:"timeInForce"`
+	Type                    OrderType               `json:"type"`
+	Side                    Side                    `json:"side"`
+	WorkingTime             int64                   `json:"workingTime"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 }
 
 type OrderResponseFull struct {
-	Symbol                  string `json:"symbol"`
-	OrderId                 int64  `json:"orderId"`
-	OrderListId             int64  `json:"orderListId"`
-	ClientOrderId           string `json:"clientOrderId"`
-	TransactTime            int64  `json:"transactTime"`
-	Price                   string `json:"price"`
-	OrigQty                 string `json:"origQty"`
-	ExecutedQty             string `json:"executedQty"`
-	CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-	Status                  string `json:"status"`
-	TimeInForce             string `json:"timeInForce"`
-	Type                    string `json:"type"`
-	Side                    string `json:"side"`
-	WorkingTime             int64  `json:"workingTime"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
-	Fills                   []struct {
-		Price           string `json:"price"`
-		Qty             string `json:"qty"`
-		Commission      string `json:"commission"`
-		CommissionAsset string `json:"commissionAsset"`
-		TradeId         int    `json:"tradeId"`
-	} `json:"fills"`
+	Symbol                  string                  `json:"symbol"`
+	OrderId                 int64                   `json:"orderId"`
+	OrderListId             int64                   `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId"`
+	TransactTime            int64                   `json:"transactTime"`
+	Price                   decimal.Decimal         `json:"price"`
+	OrigQty                 decimal.Decimal         `json:"origQty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+	Status                  OrderStatus             `json:"status"`
+	TimeInForce             TimeInForce             `json:"timeInForce"`
+	Type                    OrderType               `json:"type"`
+	Side                    Side                    `json:"side"`
+	WorkingTime             int64                   `json:"workingTime"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
+	Fills                   []Fill                  `json:"fills"`
+}
+
+// Fill is a trade of the new order in FULL response
+type Fill struct {
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commissionAsset"`
+	TradeId         int             `json:"tradeId"`
 }
 
 // CANCEL ORDER
 
 type OrderCancelRequest struct {
-	Symbol            string `url:"symbol" binding:"required"`
-	OrderID           int64  `url:"orderId,omitempty"`
-	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
-	NewClientOrderID  string `url:"newClientOrderId,omitempty"`
-	CancelRestriction string `url:"cancelRestrictions,omitempty"`
-	RecvWindow        int64  `url:"recvWindow,omitempty" binding:"omitempty,lt=60001"`
-	Timestamp         int64  `url:"timestamp" binding:"required"`
+	Symbol            string             `url:"symbol" binding:"required"`
+	OrderID           int64              `url:"orderId,omitempty"`
+	OrigClientOrderID string             `url:"origClientOrderId,omitempty"`
+	NewClientOrderID  string             `url:"newClientOrderId,omitempty"`
+	CancelRestriction CancelRestrictions `url:"cancelRestrictions,omitempty"`
+	RecvWindow        int64              `url:"recvWindow,omitempty" binding:"omitempty,lt=60001"`
+	Timestamp         int64              `url:"timestamp,omitempty"`
 }
 
 func (o *OrderCancelRequest) Validate() error {
-	if o.CancelRestriction != "ONLY_NEW" && o.CancelRestriction != "ONLY_PARTIALLY_FILLED" {
-		return fmt.Errorf("incorrect cancelrestriction value")
+	if o.Symbol == "" {
+		return errors.New("symbol is mandatory")
 	}
-	return nil
+	if o.OrderID == 0 && o.OrigClientOrderID == "" {
+		return errors.New("either orderId or origClientOrderId must be provided")
+	}
+	return optional(o.CancelRestriction)
 }
 
 type OrderCancelResponse struct {
-	Symbol                  string          `json:"symbol"`
-	OrigClientOrderId       string          `json:"origClientOrderId"`
-	OrderId                 int64           `json:"orderId"`
-	OrderListId             int64           `json:"orderListId"`
-	ClientOrderId           string          `json:"clientOrderId"`
-	TransactTime            int64           `json:"transactTime"`
-	Price                   decimal.Decimal `json:"price"`
-	OrigQty                 string          `json:"origQty"`
-	ExecutedQty             string          `json:"executedQty"`
-	CummulativeQuoteQty     string          `json:"cummulativeQuoteQty"`
-	Status                  string          `json:"status"`
-	TimeInForce             string          `json:"timeInForce"`
-	Type                    string          `json:"type"`
-	Side                    string          `json:"side"`
-	SelfTradePreventionMode string          `json:"selfTradePreventionMode"`
+	Symbol                  string                  `json:"symbol"`
+	OrigClientOrderId       string                  `json:"origClientOrderId"`
+	OrderId                 int64                   `json:"orderId"`
+	OrderListId             int64                   `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId"`
+	TransactTime            int64                   `json:"transactTime"`
+	Price                   decimal.Decimal         `json:"price"`
+	OrigQty                 decimal.Decimal         `json:"origQty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+	Status                  OrderStatus             `json:"status"`
+	TimeInForce             TimeInForce             `json:"timeInForce"`
+	Type                    OrderType               `json:"type"`
+	Side                    Side                    `json:"side"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 }
 
 type CancelAllOrdersRequest struct {
 	Symbol     string `url:"symbol" binding:"required"`
 	RecvWindow int64  `url:"recvWindow,omitempty"`
-	Timestamp  int64  `url:"timestamp" binding:"required"`
+	Timestamp  int64  `url:"timestamp,omitempty"`
 }
 
 func (r CancelAllOrdersRequest) Validate() error {
 	if r.Symbol == "" {
 		return fmt.Errorf("symbol cannot be empty")
 	}
-	if r.Timestamp == 0 {
-		return fmt.Errorf("timestamp cannot be empty")
-	}
 	return nil
 }
 
 type CancelAllOrdersResponse struct {
-	Symbol                  string `json:"symbol"`
-	OrigClientOrderId       string `json:"origClientOrderId,omitempty"`
-	OrderId                 int    `json:"orderId,omitempty"`
-	OrderListId             int    `json:"orderListId"`
-	ClientOrderId           string `json:"clientOrderId,omitempty"`
-	TransactTime            int64  `json:"transactTime,omitempty"`
-	Price                   string `json:"price,omitempty"`
-	OrigQty                 string `json:"origQty,omitempty"`
-	ExecutedQty             string `json:"executedQty,omitempty"`
-	CummulativeQuoteQty     string `json:"cummulativeQuoteQty,omitempty"`
-	Status                  string `json:"status,omitempty"`
-	TimeInForce             string `json:"timeInForce,omitempty"`
-	Type                    string `json:"type,omitempty"`
-	Side                    string `json:"side,omitempty"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode,omitempty"`
-	ContingencyType         string `json:"contingencyType,omitempty"`
-	ListStatusType          string `json:"listStatusType,omitempty"`
-	ListOrderStatus         string `json:"listOrderStatus,omitempty"`
-	ListClientOrderId       string `json:"listClientOrderId,omitempty"`
-	TransactionTime         int64  `json:"transactionTime,omitempty"`
+	Symbol                  string                  `json:"symbol"`
+	OrigClientOrderId       string                  `json:"origClientOrderId,omitempty"`
+	OrderId                 int                     `json:"orderId,omitempty"`
+	OrderListId             int                     `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId,omitempty"`
+	TransactTime            int64                   `json:"transactTime,omitempty"`
+	Price                   decimal.Decimal         `json:"price,omitempty"`
+	OrigQty                 decimal.Decimal         `json:"origQty,omitempty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty,omitempty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty,omitempty"`
+	Status                  OrderStatus             `json:"status,omitempty"`
+	TimeInForce             TimeInForce             `json:"timeInForce,omitempty"`
+	Type                    OrderType               `json:"type,omitempty"`
+	Side                    Side                    `json:"side,omitempty"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode,omitempty"`
+	ContingencyType         ContingencyType         `json:"contingencyType,omitempty"`
+	ListStatusType          ListStatusType          `json:"listStatusType,omitempty"`
+	ListOrderStatus         ListOrderStatus         `json:"listOrderStatus,omitempty"`
+	ListClientOrderId       string                  `json:"listClientOrderId,omitempty"`
+	TransactionTime         int64                   `json:"transactionTime,omitempty"`
 	Orders                  []struct {
 		Symbol        string `json:"symbol"`
 		OrderId       int    `json:"orderId"`
 		ClientOrderId string `json:"clientOrderId"`
 	} `json:"orders,omitempty"`
 	OrderReports []struct {
-		Symbol                  string `json:"symbol"`
-		OrigClientOrderId       string `json:"origClientOrderId"`
-		OrderId                 int    `json:"orderId"`
-		OrderListId             int    `json:"orderListId"`
-		ClientOrderId           string `json:"clientOrderId"`
-		TransactTime            int64  `json:"transactTime"`
-		Price                   string `json:"price"`
-		OrigQty                 string `json:"origQty"`
-		ExecutedQty             string `json:"executedQty"`
-		CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-		Status                  string `json:"status"`
-		TimeInForce             string `json:"timeInForce"`
-		Type                    string `json:"type"`
-		Side                    string `json:"side"`
-		StopPrice               string `json:"stopPrice,omitempty"`
-		IcebergQty              string `json:"icebergQty"`
-		SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+		Symbol                  string                  `json:"symbol"`
+		OrigClientOrderId       string                  `json:"origClientOrderId"`
+		OrderId                 int                     `json:"orderId"`
+		OrderListId             int                     `json:"orderListId"`
+		ClientOrderId           string                  `json:"clientOrderId"`
+		TransactTime            int64                   `json:"transactTime"`
+		Price                   decimal.Decimal         `json:"price"`
+		OrigQty                 decimal.Decimal         `json:"origQty"`
+		ExecutedQty             decimal.Decimal         `json:"executedQty"`
+		CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+		Status                  OrderStatus             `json:"status"`
+		TimeInForce             TimeInForce             `json:"timeInForce"`
+		Type                    OrderType               `json:"type"`
+		Side                    Side                    `json:"side"`
+		StopPrice               decimal.Decimal         `json:"stopPrice,omitempty"`
+		IcebergQty              decimal.Decimal         `json:"icebergQty"`
+		SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 	} `json:"orderReports,omitempty"`
 }
 
@@ -246,30 +246,30 @@ type GetOrderRequest struct {
 	OrderID           int64  `url:"orderId,omitempty"`
 	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
 	RecvWindow        int64  `url:"recvWindow,omitempty"`
-	Timestamp         int64  `url:"timestamp"`
+	Timestamp         int64  `url:"timestamp,omitempty"`
 }
 
 type GetOrderResponse struct {
-	Symbol                  string `json:"symbol"`
-	OrderId                 int    `json:"orderId"`
-	OrderListId             int    `json:"orderListId"`
-	ClientOrderId           string `json:"clientOrderId"`
-	Price                   string `json:"price"`
-	OrigQty                 string `json:"origQty"`
-	ExecutedQty             string `json:"executedQty"`
-	CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-	Status                  string `json:"status"`
-	TimeInForce             string `json:"timeInForce"`
-	Type                    string `json:"type"`
-	Side                    string `json:"side"`
-	StopPrice               string `json:"stopPrice"`
-	IcebergQty              string `json:"icebergQty"`
-	Time                    int64  `json:"time"`
-	UpdateTime              int64  `json:"updateTime"`
-	IsWorking               bool   `json:"isWorking"`
-	WorkingTime             int64  `json:"workingTime"`
-	OrigQuoteOrderQty       string `json:"origQuoteOrderQty"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	Symbol                  string                  `json:"symbol"`
+	OrderId                 int                     `json:"orderId"`
+	OrderListId             int                     `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId"`
+	Price                   decimal.Decimal         `json:"price"`
+	OrigQty                 decimal.Decimal         `json:"origQty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+	Status                  OrderStatus             `json:"status"`
+	TimeInForce             TimeInForce             `json:"timeInForce"`
+	Type                    OrderType               `json:"type"`
+	Side                    Side                    `json:"side"`
+	StopPrice               decimal.Decimal         `json:"stopPrice"`
+	IcebergQty              decimal.Decimal         `json:"icebergQty"`
+	Time                    int64                   `json:"time"`
+	UpdateTime              int64                   `json:"updateTime"`
+	IsWorking               bool                    `json:"isWorking"`
+	WorkingTime             int64                   `json:"workingTime"`
+	OrigQuoteOrderQty       decimal.Decimal         `json:"origQuoteOrderQty"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 }
 
 func (o *GetOrderRequest) Validate() error {
@@ -277,40 +277,36 @@ func (o *GetOrderRequest) Validate() error {
 		return errors.New("symbol is mandatory")
 	}
 
-	if o.Timestamp == 0 {
-		return errors.New("timestamp is mandatory")
-	}
-
-	if o.OrderID == 0 {
-		return fmt.Errorf("provide correct order id")
+	if o.OrderID == 0 && o.OrigClientOrderID == "" {
+		return errors.New("either orderId or origClientOrderId must be provided")
 	}
 
 	return nil
 }
 
 type CancelReplaceRequest struct {
-	Symbol                  string  `url:"symbol"`
-	Side                    string  `url:"side"`
-	Type                    string  `url:"type"`
-	CancelReplaceMode       string  `url:"cancelReplaceMode"`
-	TimeInForce             string  `url:"timeInForce,omitempty"`
-	Quantity                float64 `url:"quantity,omitempty"`
-	QuoteOrderQty           float64 `url:"quoteOrderQty,omitempty"`
-	Price                   float64 `url:"price,omitempty"`
-	CancelNewClientOrderId  string  `url:"cancelNewClientOrderId,omitempty"`
-	CancelOrigClientOrderId string  `url:"cancelOrigClientOrderId,omitempty"`
-	CancelOrderId           int64   `url:"cancelOrderId,omitempty"`
-	NewClientOrderId        string  `url:"newClientOrderId,omitempty"`
-	StrategyId              int     `url:"strategyId,omitempty"`
-	StrategyType            int     `url:"strategyType,omitempty"`
-	StopPrice               float64 `url:"stopPrice,omitempty"`
-	TrailingDelta           int64   `url:"trailingDelta,omitempty"`
-	IcebergQty              float64 `url:"icebergQty,omitempty"`
-	NewOrderRespType        string  `url:"newOrderRespType,omitempty"`
-	SelfTradePreventionMode string  `url:"selfTradePreventionMode,omitempty"`
-	CancelRestrictions      string  `url:"cancelRestrictions,omitempty"`
-	RecvWindow              int64   `url:"recvWindow,omitempty"`
-	Timestamp               int64   `url:"timestamp"`
+	Symbol                  string                  `url:"symbol"`
+	Side                    Side                    `url:"side"`
+	Type                    OrderType               `url:"type"`
+	CancelReplaceMode       CancelReplaceMode       `url:"cancelReplaceMode"`
+	TimeInForce             TimeInForce             `url:"timeInForce,omitempty"`
+	Quantity                decimal.Decimal         `url:"quantity,omitempty"`
+	QuoteOrderQty           decimal.Decimal         `url:"quoteOrderQty,omitempty"`
+	Price                   decimal.Decimal         `url:"price,omitempty"`
+	CancelNewClientOrderId  string                  `url:"cancelNewClientOrderId,omitempty"`
+	CancelOrigClientOrderId string                  `url:"cancelOrigClientOrderId,omitempty"`
+	CancelOrderId           int64                   `url:"cancelOrderId,omitempty"`
+	NewClientOrderId        string                  `url:"newClientOrderId,omitempty"`
+	StrategyId              int                     `url:"strategyId,omitempty"`
+	StrategyType            int                     `url:"strategyType,omitempty"`
+	StopPrice               decimal.Decimal         `url:"stopPrice,omitempty"`
+	TrailingDelta           int64                   `url:"trailingDelta,omitempty"`
+	IcebergQty              decimal.Decimal         `url:"icebergQty,omitempty"`
+	NewOrderRespType        NewOrderRespType        `url:"newOrderRespType,omitempty"`
+	SelfTradePreventionMode SelfTradePreventionMode `url:"selfTradePreventionMode,omitempty"`
+	CancelRestrictions      CancelRestrictions      `url:"cancelRestrictions,omitempty"`
+	RecvWindow              int64                   `url:"recvWindow,omitempty"`
+	Timestamp               int64                   `url:"timestamp,omitempty"`
 }
 
 func (req *CancelReplaceRequest) Validate() error {
@@ -318,17 +314,20 @@ func (req *CancelReplaceRequest) Validate() error {
 	if req.Symbol == "" {
 		return errors.New("symbol is mandatory")
 	}
-	if req.Side != "BUY" && req.Side != "SELL" {
-		return errors.New("side must be either BUY or SELL")
+	if err := req.Side.Validate(); err != nil {
+		return err
 	}
 	if req.Type == "" {
 		return errors.New("type is mandatory")
 	}
-	if req.CancelReplaceMode != "STOP_ON_FAILURE" && req.CancelReplaceMode != "ALLOW_FAILURE" {
-		return errors.New("cancelReplaceMode must be either STOP_ON_FAILURE or ALLOW_FAILURE")
+	if err := req.Type.Validate(); err != nil {
+		return err
+	}
+	if err := req.CancelReplaceMode.Validate(); err != nil {
+		return err
 	}
-	if req.Timestamp == 0 {
-		return errors.New("timestamp is mandatory")
+	if err := optional(req.TimeInForce); err != nil {
+		return err
 	}
 
 	if req.StrategyType != 0 && req.StrategyType < 1000000 {
@@ -343,117 +342,96 @@ func (req *CancelReplaceRequest) Validate() error {
 		return errors.New("either cancelOrigClientOrderId or cancelOrderId must be provided")
 	}
 
-	if req.NewOrderRespType != "" {
-		switch req.NewOrderRespType {
-		case "ACK", "RESULT", "FULL":
-			// valid
-		default:
-			return errors.New("newOrderRespType must be either ACK, RESULT, or FULL")
-		}
-	}
-
-	if req.SelfTradePreventionMode != "" {
-		switch req.SelfTradePreventionMode {
-		case "EXPIRE_TAKER", "EXPIRE_MAKER", "EXPIRE_BOTH", "NONE":
-			// valid
-		default:
-			return errors.New("selfTradePreventionMode is not valid")
-		}
+	if err := optional(req.NewOrderRespType); err != nil {
+		return err
 	}
-
-	if req.CancelRestrictions != "" {
-		switch req.CancelRestrictions {
-		case "ONLY_NEW", "ONLY_PARTIALLY_FILLED":
-			// valid
-		default:
-			return errors.New("cancelRestrictions is not valid")
-		}
+	if err := optional(req.SelfTradePreventionMode); err != nil {
+		return err
 	}
-
-	return nil
+	return optional(req.CancelRestrictions)
 }
 
 type CancelReplaceResponse struct {
-	Code           int64  `json:"code,omitempty"`
-	Msg            string `json:"msg,omitempty"`
-	CancelResult   string `json:"cancelResult,omitempty"`
-	NewOrderResult string `json:"newOrderResult,omitempty"`
+	Code           int64               `json:"code,omitempty"`
+	Msg            string              `json:"msg,omitempty"`
+	CancelResult   CancelReplaceResult `json:"cancelResult,omitempty"`
+	NewOrderResult CancelReplaceResult `json:"newOrderResult,omitempty"`
 	CancelResponse struct {
-		Code                    int    `json:"code,omitempty"`
-		Msg                     string `json:"msg,omitempty"`
-		Symbol                  string `json:"symbol,omitempty"`
-		OrigClientOrderId       string `json:"origClientOrderId,omitempty"`
-		OrderId                 int64  `json:"orderId,omitempty"`
-		OrderListId             int64  `json:"orderListId,omitempty"`
-		ClientOrderId           string `json:"clientOrderId,omitempty"`
-		Price                   string `json:"price,omitempty"`
-		OrigQty                 string `json:"origQty,omitempty"`
-		ExecutedQty             string `json:"executedQty,omitempty"`
-		CumulativeQuoteQty      string `json:"cumulativeQuoteQty,omitempty"`
-		Status                  string `json:"status,omitempty"`
-		TimeInForce             string `json:"timeInForce,omitempty"`
-		Type                    string `json:"type,omitempty"`
-		Side                    string `json:"side,omitempty"`
-		SelfTradePreventionMode string `json:"selfTradePreventionMode,omitempty"`
+		Code                    int                     `json:"code,omitempty"`
+		Msg                     string                  `json:"msg,omitempty"`
+		Symbol                  string                  `json:"symbol,omitempty"`
+		OrigClientOrderId       string                  `json:"origClientOrderId,omitempty"`
+		OrderId                 int64                   `json:"orderId,omitempty"`
+		OrderListId             int64                   `json:"orderListId,omitempty"`
+		ClientOrderId           string                  `json:"clientOrderId,omitempty"`
+		Price                   decimal.Decimal         `json:"price,omitempty"`
+		OrigQty                 decimal.Decimal         `json:"origQty,omitempty"`
+		ExecutedQty             decimal.Decimal         `json:"executedQty,omitempty"`
+		CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty,omitempty"`
+		Status                  OrderStatus             `json:"status,omitempty"`
+		TimeInForce             TimeInForce             `json:"timeInForce,omitempty"`
+		Type                    OrderType               `json:"type,omitempty"`
+		Side                    Side                    `json:"side,omitempty"`
+		SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode,omitempty"`
 	} `json:"cancelResponse,omitempty"`
 	NewOrderResponse struct {
-		Code                    int64    `json:"code,omitempty"`
-		Msg                     string   `json:"msg,omitempty"`
-		Symbol                  string   `json:"symbol,omitempty"`
-		OrderId                 int64    `json:"orderId,omitempty"`
-		OrderListId             int64    `json:"orderListId,omitempty"`
-		ClientOrderId           string   `json:"clientOrderId,omitempty"`
-		TransactTime            uint64   `json:"transactTime,omitempty"`
-		Price                   string   `json:"price,omitempty"`
-		OrigQty                 string   `json:"origQty,omitempty"`
-		ExecutedQty             string   `json:"executedQty,omitempty"`
-		CumulativeQuoteQty      string   `json:"cumulativeQuoteQty,omitempty"`
-		Status                  string   `json:"status,omitempty"`
-		TimeInForce             string   `json:"timeInForce,omitempty"`
-		Type                    string   `json:"type,omitempty"`
-		Side                    string   `json:"side,omitempty"`
-		Fills                   []string `json:"fills,omitempty"`
-		SelfTradePreventionMode string   `json:"selfTradePreventionMode,omitempty"`
+		Code                    int64                   `json:"code,omitempty"`
+		Msg                     string                  `json:"msg,omitempty"`
+		Symbol                  string                  `json:"symbol,omitempty"`
+		OrderId                 int64                   `json:"orderId,omitempty"`
+		OrderListId             int64                   `json:"orderListId,omitempty"`
+		ClientOrderId           string                  `json:"clientOrderId,omitempty"`
+		TransactTime            uint64                  `json:"transactTime,omitempty"`
+		Price                   decimal.Decimal         `json:"price,omitempty"`
+		OrigQty                 decimal.Decimal         `json:"origQty,omitempty"`
+		ExecutedQty             decimal.Decimal         `json:"executedQty,omitempty"`
+		CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty,omitempty"`
+		Status                  OrderStatus             `json:"status,omitempty"`
+		TimeInForce             TimeInForce             `json:"timeInForce,omitempty"`
+		Type                    OrderType               `json:"type,omitempty"`
+		Side                    Side                    `json:"side,omitempty"`
+		Fills                   []Fill                  `json:"fills,omitempty"`
+		SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode,omitempty"`
 	} `json:"newOrderResponse,omitempty"`
 	Data struct {
-		CancelResult   string `json:"cancelResult,omitempty"`
-		NewOrderResult string `json:"newOrderResult,omitempty"`
+		CancelResult   CancelReplaceResult `json:"cancelResult,omitempty"`
+		NewOrderResult CancelReplaceResult `json:"newOrderResult,omitempty"`
 		CancelResponse struct {
-			Code                    int64  `json:"code,omitempty"`
-			Msg                     string `json:"msg,omitempty"`
-			Symbol                  string `json:"symbol,omitempty"`
-			OrigClientOrderId       string `json:"origClientOrderId,omitempty"`
-			OrderId                 int64  `json:"orderId,omitempty"`
-			OrderListId             int64  `json:"orderListId,omitempty"`
-			ClientOrderId           string `json:"clientOrderId,omitempty"`
-			Price                   string `json:"price,omitempty"`
-			OrigQty                 string `json:"origQty,omitempty"`
-			ExecutedQty             string `json:"executedQty,omitempty"`
-			CumulativeQuoteQty      string `json:"cumulativeQuoteQty,omitempty"`
-			Status                  string `json:"status,omitempty"`
-			TimeInForce             string `json:"timeInForce,omitempty"`
-			Type                    string `json:"type,omitempty"`
-			Side                    string `json:"side,omitempty"`
-			SelfTradePreventionMode string `json:"selfTradePreventionMode,omitempty"`
+			Code                    int64                   `json:"code,omitempty"`
+			Msg                     string                  `json:"msg,omitempty"`
+			Symbol                  string                  `json:"symbol,omitempty"`
+			OrigClientOrderId       string                  `json:"origClientOrderId,omitempty"`
+			OrderId                 int64                   `json:"orderId,omitempty"`
+			OrderListId             int64                   `json:"orderListId,omitempty"`
+			ClientOrderId           string                  `json:"clientOrderId,omitempty"`
+			Price                   decimal.Decimal         `json:"price,omitempty"`
+			OrigQty                 decimal.Decimal         `json:"origQty,omitempty"`
+			ExecutedQty             decimal.Decimal         `json:"executedQty,omitempty"`
+			CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty,omitempty"`
+			Status                  OrderStatus             `json:"status,omitempty"`
+			TimeInForce             TimeInForce             `json:"timeInForce,omitempty"`
+			Type                    OrderType               `json:"type,omitempty"`
+			Side                    Side                    `json:"side,omitempty"`
+			SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode,omitempty"`
 		} `json:"cancelResponse,omitempty"`
 		NewOrderResponse struct {
-			Code                    int64    `json:"code,omitempty"`
-			Msg                     string   `json:"msg,omitempty"`
-			Symbol                  string   `json:"symbol,omitempty"`
-			OrderId                 int64    `json:"orderId,omitempty"`
-			OrderListId             int64    `json:"orderListId,omitempty"`
-			ClientOrderId           string   `json:"clientOrderId,omitempty"`
-			TransactTime            uint64   `json:"transactTime,omitempty"`
-			Price                   string   `json:"price,omitempty"`
-			OrigQty                 string   `json:"origQty,omitempty"`
-			ExecutedQty             string   `json:"executedQty,omitempty"`
-			CumulativeQuoteQty      string   `json:"cumulativeQuoteQty,omitempty"`
-			Status                  string   `json:"status,omitempty"`
-			TimeInForce             string   `json:"timeInForce,omitempty"`
-			Type                    string   `json:"type,omitempty"`
-			Side                    string   `json:"side,omitempty"`
-			Fills                   []string `json:"fills,omitempty"`
-			SelfTradePreventionMode string   `json:"selfTradePreventionMode,omitempty"`
+			Code                    int64                   `json:"code,omitempty"`
+			Msg                     string                  `json:"msg,omitempty"`
+			Symbol                  string                  `json:"symbol,omitempty"`
+			OrderId                 int64                   `json:"orderId,omitempty"`
+			OrderListId             int64                   `json:"orderListId,omitempty"`
+			ClientOrderId           string                  `json:"clientOrderId,omitempty"`
+			TransactTime            uint64                  `json:"transactTime,omitempty"`
+			Price                   decimal.Decimal         `json:"price,omitempty"`
+			OrigQty                 decimal.Decimal         `json:"origQty,omitempty"`
+			ExecutedQty             decimal.Decimal         `json:"executedQty,omitempty"`
+			CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty,omitempty"`
+			Status                  OrderStatus             `json:"status,omitempty"`
+			TimeInForce             TimeInForce             `json:"timeInForce,omitempty"`
+			Type                    OrderType               `json:"type,omitempty"`
+			Side                    Side                    `json:"side,omitempty"`
+			Fills                   []Fill                  `json:"fills,omitempty"`
+			SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode,omitempty"`
 		} `json:"newOrderResponse"`
 	} `json:"data,omitempty"`
 }
@@ -461,37 +439,34 @@ type CancelReplaceResponse struct {
 type OpenOrdersRequest struct {
 	Symbol     string `url:"symbol,omitempty"`
 	RecvWindow *int64 `url:"recvWindow,omitempty"`
-	Timestamp  int64  `url:"timestamp"`
+	Timestamp  int64  `url:"timestamp,omitempty"`
 }
 
 func (o *OpenOrdersRequest) Validate() error {
-	if o.Timestamp <= 0 {
-		return errors.New("timestamp is required and must be a positive integer")
-	}
 	return nil
 }
 
 type OpenOrdersResponse struct {
-	Symbol                  string `json:"symbol"`
-	OrderId                 int    `json:"orderId"`
-	OrderListId             int    `json:"orderListId"`
-	ClientOrderId           string `json:"clientOrderId"`
-	Price                   string `json:"price"`
-	OrigQty                 string `json:"origQty"`
-	ExecutedQty             string `json:"executedQty"`
-	CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-	Status                  string `json:"status"`
-	TimeInForce             string `json:"timeInForce"`
-	Type                    string `json:"type"`
-	Side                    string `json:"side"`
-	StopPrice               string `json:"stopPrice"`
-	IcebergQty              string `json:"icebergQty"`
-	Time                    int64  `json:"time"`
-	UpdateTime              int64  `json:"updateTime"`
-	IsWorking               bool   `json:"isWorking"`
-	WorkingTime             int64  `json:"workingTime"`
-	OrigQuoteOrderQty       string `json:"origQuoteOrderQty"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	Symbol                  string                  `json:"symbol"`
+	OrderId                 int                     `json:"orderId"`
+	OrderListId             int                     `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId"`
+	Price                   decimal.Decimal         `json:"price"`
+	OrigQty                 decimal.Decimal         `json:"origQty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+	Status                  OrderStatus             `json:"status"`
+	TimeInForce             TimeInForce             `json:"timeInForce"`
+	Type                    OrderType               `json:"type"`
+	Side                    Side                    `json:"side"`
+	StopPrice               decimal.Decimal         `json:"stopPrice"`
+	IcebergQty              decimal.Decimal         `json:"icebergQty"`
+	Time                    int64                   `json:"time"`
+	UpdateTime              int64                   `json:"updateTime"`
+	IsWorking               bool                    `json:"isWorking"`
+	WorkingTime             int64                   `json:"workingTime"`
+	OrigQuoteOrderQty       decimal.Decimal         `json:"origQuoteOrderQty"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 }
 
 type AllOpenOrdersRequest struct {
@@ -501,7 +476,7 @@ type AllOpenOrdersRequest struct {
 	EndTime    *int64 `url:"endTime,omitempty"`
 	Limit      *int   `url:"limit,omitempty"`
 	RecvWindow *int64 `url:"recvWindow,omitempty"`
-	Timestamp  int64  `url:"timestamp"`
+	Timestamp  int64  `url:"timestamp,omitempty"`
 }
 
 func (o *AllOpenOrdersRequest) Validate() error {
@@ -513,50 +488,50 @@ func (o *AllOpenOrdersRequest) Validate() error {
 }
 
 type AllOpenOrdersResponse struct {
-	Symbol                  string `json:"symbol"`
-	OrderId                 int    `json:"orderId"`
-	OrderListId             int    `json:"orderListId"`
-	ClientOrderId           string `json:"clientOrderId"`
-	Price                   string `json:"price"`
-	OrigQty                 string `json:"origQty"`
-	ExecutedQty             string `json:"executedQty"`
-	CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-	Status                  string `json:"status"`
-	TimeInForce             string `json:"timeInForce"`
-	Type                    string `json:"type"`
-	Side                    string `json:"side"`
-	StopPrice               string `json:"stopPrice"`
-	IcebergQty              string `json:"icebergQty"`
-	Time                    int64  `json:"time"`
-	UpdateTime              int64  `json:"updateTime"`
-	IsWorking               bool   `json:"isWorking"`
-	OrigQuoteOrderQty       string `json:"origQuoteOrderQty"`
-	WorkingTime             int64  `json:"workingTime"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	Symbol                  string                  `json:"symbol"`
+	OrderId                 int                     `json:"orderId"`
+	OrderListId             int                     `json:"orderListId"`
+	ClientOrderId           string                  `json:"clientOrderId"`
+	Price                   decimal.Decimal         `json:"price"`
+	OrigQty                 decimal.Decimal         `json:"origQty"`
+	ExecutedQty             decimal.Decimal         `json:"executedQty"`
+	CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+	Status                  OrderStatus             `json:"status"`
+	TimeInForce             TimeInForce             `json:"timeInForce"`
+	Type                    OrderType               `json:"type"`
+	Side                    Side                    `json:"side"`
+	StopPrice               decimal.Decimal         `json:"stopPrice"`
+	IcebergQty              decimal.Decimal         `json:"icebergQty"`
+	Time                    int64                   `json:"time"`
+	UpdateTime              int64                   `json:"updateTime"`
+	IsWorking               bool                    `json:"isWorking"`
+	OrigQuoteOrderQty       decimal.Decimal         `json:"origQuoteOrderQty"`
+	WorkingTime             int64                   `json:"workingTime"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 }
 
 type NewOCORequest struct {
-	Symbol                  string   `url:"symbol"`
-	ListClientOrderId       *string  `url:"listClientOrderId,omitempty"`
-	Side                    Side     `url:"side"`
-	Quantity                float64  `url:"quantity"`
-	LimitClientOrderId      *string  `url:"limitClientOrderId,omitempty"`
-	LimitStrategyId         *int     `url:"limitStrategyId,omitempty"`
-	LimitStrategyType       *int     `url:"limitStrategyType,omitempty"`
-	Price                   float64  `url:"price"`
-	LimitIcebergQty         *float64 `url:"limitIcebergQty,omitempty"`
-	TrailingDelta           *int64   `url:"trailingDelta,omitempty"`
-	StopClientOrderId       *string  `url:"stopClientOrderId,omitempty"`
-	StopPrice               float64  `url:"stopPrice"`
-	StopStrategyId          *int     `url:"stopStrategyId,omitempty"`
-	StopStrategyType        *int     `url:"stopStrategyType,omitempty"`
-	StopLimitPrice          *float64 `url:"stopLimitPrice,omitempty"`
-	StopIcebergQty          *float64 `url:"stopIcebergQty,omitempty"`
-	StopLimitTimeInForce    string   `url:"stopLimitTimeInForce,omitempty"`
-	NewOrderRespType        string   `url:"newOrderRespType,omitempty"`
-	SelfTradePreventionMode string   `url:"selfTradePreventionMode,omitempty"`
-	RecvWindow              *int64   `url:"recvWindow,omitempty"`
-	Timestamp               int64    `url:"timestamp"`
+	Symbol                  string                  `url:"symbol"`
+	ListClientOrderId       *string                 `url:"listClientOrderId,omitempty"`
+	Side                    Side                    `url:"side"`
+	Quantity                decimal.Decimal         `url:"quantity"`
+	LimitClientOrderId      *string                 `url:"limitClientOrderId,omitempty"`
+	LimitStrategyId         *int                    `url:"limitStrategyId,omitempty"`
+	LimitStrategyType       *int                    `url:"limitStrategyType,omitempty"`
+	Price                   decimal.Decimal         `url:"price"`
+	LimitIcebergQty         *decimal.Decimal        `url:"limitIcebergQty,omitempty"`
+	TrailingDelta           *int64                  `url:"trailingDelta,omitempty"`
+	StopClientOrderId       *string                 `url:"stopClientOrderId,omitempty"`
+	StopPrice               decimal.Decimal         `url:"stopPrice"`
+	StopStrategyId          *int                    `url:"stopStrategyId,omitempty"`
+	StopStrategyType        *int                    `url:"stopStrategyType,omitempty"`
+	StopLimitPrice          *decimal.Decimal        `url:"stopLimitPrice,omitempty"`
+	StopIcebergQty          *decimal.Decimal        `url:"stopIcebergQty,omitempty"`
+	StopLimitTimeInForce    TimeInForce             `url:"stopLimitTimeInForce,omitempty"`
+	NewOrderRespType        NewOrderRespType        `url:"newOrderRespType,omitempty"`
+	SelfTradePreventionMode SelfTradePreventionMode `url:"selfTradePreventionMode,omitempty"`
+	RecvWindow              *int64                  `url:"recvWindow,omitempty"`
+	Timestamp               int64                   `url:"timestamp,omitempty"`
 }
 
 func (r *NewOCORequest) Validate() error {
@@ -564,19 +539,19 @@ func (r *NewOCORequest) Validate() error {
 		return errors.New("symbol is required and cannot be empty")
 	}
 
-	if r.Side != "BUY" && r.Side != "SELL" {
-		return errors.New("side is required and must be either BUY or SELL")
+	if err := r.Side.Validate(); err != nil {
+		return err
 	}
 
-	if r.Quantity <= 0 {
+	if !r.Quantity.IsPositive() {
 		return errors.New("quantity is required and must be greater than 0")
 	}
 
-	if r.Price <= 0 {
+	if !r.Price.IsPositive() {
 		return errors.New("price is required and must be greater than 0")
 	}
 
-	if r.StopPrice <= 0 {
+	if !r.StopPrice.IsPositive() {
 		return errors.New("stopPrice is required and must be greater than 0")
 	}
 
@@ -592,16 +567,12 @@ func (r *NewOCORequest) Validate() error {
 		return errors.New("recvWindow cannot be greater than 60000")
 	}
 
-	if r.Timestamp <= 0 {
-		return errors.New("timestamp is required and must be a positive integer")
-	}
-
 	// Price and quantity restrictions based on side
-	if r.Side == "SELL" && (r.Price <= r.StopPrice) {
+	if r.Side == SideSell && r.Price.LessThanOrEqual(r.StopPrice) {
 		return errors.New("for a SELL order, limit price must be greater than the stop price")
 	}
 
-	if r.Side == "BUY" && (r.Price >= r.StopPrice) {
+	if r.Side == SideBuy && r.Price.GreaterThanOrEqual(r.StopPrice) {
 		return errors.New("for a BUY order, limit price must be less than the stop price")
 	}
 
@@ -609,40 +580,71 @@ func (r *NewOCORequest) Validate() error {
 	if r.StopLimitPrice != nil && r.StopLimitTimeInForce == "" {
 		return errors.New("if stopLimitPrice is provided, stopLimitTimeInForce is required")
 	}
+	if err := optional(r.StopLimitTimeInForce); err != nil {
+		return err
+	}
+	if err := optional(r.NewOrderRespType); err != nil {
+		return err
+	}
+	if err := optional(r.SelfTradePreventionMode); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// CheckRules checks both legs, limit maker and stop loss (limit), against the symbol rules of the registry
+func (r *NewOCORequest) CheckRules(rules *SymbolRegistry) error {
+	if info, ok := rules.Get(r.Symbol); ok && !info.OcoAllowed {
+		return fmt.Errorf("OCO orders are not allowed for %s", r.Symbol)
+	}
+
+	limitLeg := orderRules{orderType: OrderTypeLimitMaker, side: r.Side, price: r.Price, quantity: r.Quantity}
+	if r.LimitIcebergQty != nil {
+		limitLeg.icebergQty = *r.LimitIcebergQty
+	}
+
+	stopLeg := orderRules{orderType: OrderTypeStopLoss, side: r.Side, stopPrice: r.StopPrice, quantity: r.Quantity}
+	if r.StopLimitPrice != nil {
+		stopLeg.orderType = OrderTypeStopLossLimit
+		stopLeg.price = *r.StopLimitPrice
+	}
+	if r.StopIcebergQty != nil {
+		stopLeg.icebergQty = *r.StopIcebergQty
+	}
+	return rules.check(r.Symbol, limitLeg, stopLeg)
+}
+
 type NewOCOResponse struct {
-	OrderListId       int    `json:"orderListId"`
-	ContingencyType   string `json:"contingencyType"`
-	ListStatusType    string `json:"listStatusType"`
-	ListOrderStatus   string `json:"listOrderStatus"`
-	ListClientOrderId string `json:"listClientOrderId"`
-	TransactionTime   int64  `json:"transactionTime"`
-	Symbol            string `json:"symbol"`
+	OrderListId       int             `json:"orderListId"`
+	ContingencyType   ContingencyType `json:"contingencyType"`
+	ListStatusType    ListStatusType  `json:"listStatusType"`
+	ListOrderStatus   ListOrderStatus `json:"listOrderStatus"`
+	ListClientOrderId string          `json:"listClientOrderId"`
+	TransactionTime   int64           `json:"transactionTime"`
+	Symbol            string          `json:"symbol"`
 	Orders            []struct {
 		Symbol        string `json:"symbol"`
 		OrderId       int    `json:"orderId"`
 		ClientOrderId string `json:"clientOrderId"`
 	} `json:"orders"`
 	OrderReports []struct {
-		Symbol                  string `json:"symbol"`
-		OrderId                 int    `json:"orderId"`
-		OrderListId             int    `json:"orderListId"`
-		ClientOrderId           string `json:"clientOrderId"`
-		TransactTime            int64  `json:"transactTime"`
-		Price                   string `json:"price"`
-		OrigQty                 string `json:"origQty"`
-		ExecutedQty             string `json:"executedQty"`
-		CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-		Status                  string `json:"status"`
-		TimeInForce             string `json:"timeInForce"`
-		Type                    string `json:"type"`
-		Side                    string `json:"side"`
-		StopPrice               string `json:"stopPrice,omitempty"`
-		WorkingTime             int64  `json:"workingTime"`
-		SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+		Symbol                  string                  `json:"symbol"`
+		OrderId                 int                     `json:"orderId"`
+		OrderListId             int                     `json:"orderListId"`
+		ClientOrderId           string                  `json:"clientOrderId"`
+		TransactTime            int64                   `json:"transactTime"`
+		Price                   decimal.Decimal         `json:"price"`
+		OrigQty                 decimal.Decimal         `json:"origQty"`
+		ExecutedQty             decimal.Decimal         `json:"executedQty"`
+		CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+		Status                  OrderStatus             `json:"status"`
+		TimeInForce             TimeInForce             `json:"timeInForce"`
+		Type                    OrderType               `json:"type"`
+		Side                    Side                    `json:"side"`
+		StopPrice               decimal.Decimal         `json:"stopPrice,omitempty"`
+		WorkingTime             int64                   `json:"workingTime"`
+		SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 	} `json:"orderReports"`
 }
 
@@ -652,7 +654,7 @@ type CancelOCORequest struct {
 	ListClientOrderID *string `url:"listClientOrderId,omitempty"`
 	NewClientOrderID  *string `url:"newClientOrderId,omitempty"`
 	RecvWindow        *int64  `url:"recvWindow,omitempty"`
-	Timestamp         int64   `url:"timestamp"`
+	Timestamp         int64   `url:"timestamp,omitempty"`
 }
 
 func (r *CancelOCORequest) Validate() error {
@@ -668,43 +670,39 @@ func (r *CancelOCORequest) Validate() error {
 		return errors.New("recvWindow cannot be greater than 60000")
 	}
 
-	if r.Timestamp <= 0 {
-		return errors.New("timestamp must be a positive integer and is required")
-	}
-
 	return nil
 }
 
 type CancelOCOResponse struct {
-	OrderListId       int    `json:"orderListId"`
-	ContingencyType   string `json:"contingencyType"`
-	ListStatusType    string `json:"listStatusType"`
-	ListOrderStatus   string `json:"listOrderStatus"`
-	ListClientOrderId string `json:"listClientOrderId"`
-	TransactionTime   int64  `json:"transactionTime"`
-	Symbol            string `json:"symbol"`
+	OrderListId       int             `json:"orderListId"`
+	ContingencyType   ContingencyType `json:"contingencyType"`
+	ListStatusType    ListStatusType  `json:"listStatusType"`
+	ListOrderStatus   ListOrderStatus `json:"listOrderStatus"`
+	ListClientOrderId string          `json:"listClientOrderId"`
+	TransactionTime   int64           `json:"transactionTime"`
+	Symbol            string          `json:"symbol"`
 	Orders            []struct {
 		Symbol        string `json:"symbol"`
 		OrderId       int    `json:"orderId"`
 		ClientOrderId string `json:"clientOrderId"`
 	} `json:"orders"`
 	OrderReports []struct {
-		Symbol                  string `json:"symbol"`
-		OrigClientOrderId       string `json:"origClientOrderId"`
-		OrderId                 int    `json:"orderId"`
-		OrderListId             int    `json:"orderListId"`
-		ClientOrderId           string `json:"clientOrderId"`
-		TransactTime            int64  `json:"transactTime"`
-		Price                   string `json:"price"`
-		OrigQty                 string `json:"origQty"`
-		ExecutedQty             string `json:"executedQty"`
-		CummulativeQuoteQty     string `json:"cummulativeQuoteQty"`
-		Status                  string `json:"status"`
-		TimeInForce             string `json:"timeInForce"`
-		Type                    string `json:"type"`
-		Side                    string `json:"side"`
-		StopPrice               string `json:"stopPrice,omitempty"`
-		SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+		Symbol                  string                  `json:"symbol"`
+		OrigClientOrderId       string                  `json:"origClientOrderId"`
+		OrderId                 int                     `json:"orderId"`
+		OrderListId             int                     `json:"orderListId"`
+		ClientOrderId           string                  `json:"clientOrderId"`
+		TransactTime            int64                   `json:"transactTime"`
+		Price                   decimal.Decimal         `json:"price"`
+		OrigQty                 decimal.Decimal         `json:"origQty"`
+		ExecutedQty             decimal.Decimal         `json:"executedQty"`
+		CummulativeQuoteQty     decimal.Decimal         `json:"cummulativeQuoteQty"`
+		Status                  OrderStatus             `json:"status"`
+		TimeInForce             TimeInForce             `json:"timeInForce"`
+		Type                    OrderType               `json:"type"`
+		Side                    Side                    `json:"side"`
+		StopPrice               decimal.Decimal         `json:"stopPrice,omitempty"`
+		SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
 	} `json:"orderReports"`
 }
 
@@ -712,7 +710,7 @@ type GetOCORequest struct {
 	OrderListID       *int    `url:"orderListId,omitempty"`
 	OrigClientOrderID *string `url:"origClientOrderId,omitempty"`
 	RecvWindow        *int    `url:"recvWindow,omitempty"`
-	Timestamp         int64   `url:"timestamp"`
+	Timestamp         int64   `url:"timestamp,omitempty"`
 }
 
 func (p *GetOCORequest) Validate() error {
@@ -724,21 +722,17 @@ func (p *GetOCORequest) Validate() error {
 		return errors.New("recvWindow cannot be greater than 60000")
 	}
 
-	if p.Timestamp <= 0 {
-		return errors.New("timestamp must be a positive integer and is required")
-	}
-
 	return nil
 }
 
 type GetOCOResponse struct {
-	OrderListId       int    `json:"orderListId"`
-	ContingencyType   string `json:"contingencyType"`
-	ListStatusType    string `json:"listStatusType"`
-	ListOrderStatus   string `json:"listOrderStatus"`
-	ListClientOrderId string `json:"listClientOrderId"`
-	TransactionTime   int64  `json:"transactionTime"`
-	Symbol            string `json:"symbol"`
+	OrderListId       int             `json:"orderListId"`
+	ContingencyType   ContingencyType `json:"contingencyType"`
+	ListStatusType    ListStatusType  `json:"listStatusType"`
+	ListOrderStatus   ListOrderStatus `json:"listOrderStatus"`
+	ListClientOrderId string          `json:"listClientOrderId"`
+	TransactionTime   int64           `json:"transactionTime"`
+	Symbol            string          `json:"symbol"`
 	Orders            []struct {
 		Symbol        string `json:"symbol"`
 		OrderId       int    `json:"orderId"`
@@ -752,7 +746,7 @@ type AllOCOListRequest struct {
 	EndTime    *int64 `url:"endTime,omitempty"`
 	Limit      *int   `url:"limit,omitempty"`
 	RecvWindow *int64 `url:"recvWindow,omitempty"`
-	Timestamp  int64  `url:"timestamp"`
+	Timestamp  int64  `url:"timestamp,omitempty"`
 }
 
 func (r *AllOCOListRequest) Validate() error {
@@ -764,13 +758,13 @@ func (r *AllOCOListRequest) Validate() error {
 }
 
 type AllOCOListResponse struct {
-	OrderListId       int    `json:"orderListId"`
-	ContingencyType   string `json:"contingencyType"`
-	ListStatusType    string `json:"listStatusType"`
-	ListOrderStatus   string `json:"listOrderStatus"`
-	ListClientOrderId string `json:"listClientOrderId"`
-	TransactionTime   int64  `json:"transactionTime"`
-	Symbol            string `json:"symbol"`
+	OrderListId       int             `json:"orderListId"`
+	ContingencyType   ContingencyType `json:"contingencyType"`
+	ListStatusType    ListStatusType  `json:"listStatusType"`
+	ListOrderStatus   ListOrderStatus `json:"listOrderStatus"`
+	ListClientOrderId string          `json:"listClientOrderId"`
+	TransactionTime   int64           `json:"transactionTime"`
+	Symbol            string          `json:"symbol"`
 	Orders            []struct {
 		Symbol        string `json:"symbol"`
 		OrderId       int    `json:"orderId"`
@@ -780,24 +774,21 @@ type AllOCOListResponse struct {
 
 type QueryOpenOCORequest struct {
 	RecvWindow *int64 `url:"recvWindow,omitempty"`
-	Timestamp  int64  `url:"timestamp"`
+	Timestamp  int64  `url:"timestamp,omitempty"`
 }
 
 func (r QueryOpenOCORequest) Validate() error {
-	if r.Timestamp <= 0 {
-		return errors.New("timestamp must be a positive integer and is required")
-	}
 	return nil
 }
 
 type QueryOpenOCOResponse struct {
-	OrderListId       int    `json:"orderListId"`
-	ContingencyType   string `json:"contingencyType"`
-	ListStatusType    string `json:"listStatusType"`
-	ListOrderStatus   string `json:"listOrderStatus"`
-	ListClientOrderId string `json:"listClientOrderId"`
-	TransactionTime   int64  `json:"transactionTime"`
-	Symbol            string `json:"symbol"`
+	OrderListId       int             `json:"orderListId"`
+	ContingencyType   ContingencyType `json:"contingencyType"`
+	ListStatusType    ListStatusType  `json:"listStatusType"`
+	ListOrderStatus   ListOrderStatus `json:"listOrderStatus"`
+	ListClientOrderId string          `json:"listClientOrderId"`
+	TransactionTime   int64           `json:"transactionTime"`
+	Symbol            string          `json:"symbol"`
 	Orders            []struct {
 		Symbol        string `json:"symbol"`
 		OrderId       int    `json:"orderId"`
@@ -806,20 +797,20 @@ type QueryOpenOCOResponse struct {
 }
 
 type NewSORRequest struct {
-	Symbol                  string    `url:"symbol"`
-	Side                    string    `url:"side"`
-	Type                    OrderType `url:"type"`
-	TimeInForce             string    `url:"timeInForce,omitempty"`
-	Quantity                float64   `url:"quantity"`
-	Price                   float64   `url:"price,omitempty"`
-	NewClientOrderId        string    `url:"newClientOrderId,omitempty"`
-	StrategyId              int       `url:"strategyId,omitempty"`
-	StrategyType            int       `url:"strategyType,omitempty"`
-	IcebergQty              float64   `url:"icebergQty,omitempty"`
-	NewOrderRespType        string    `url:"newOrderRespType,omitempty"`
-	SelfTradePreventionMode string    `url:"selfTradePreventionMode,omitempty"`
-	RecvWindow              int64     `url:"recvWindow,omitempty"`
-	Timestamp               int64     `url:"timestamp"`
+	Symbol                  string                  `url:"symbol"`
+	Side                    Side                    `url:"side"`
+	Type                    OrderType               `url:"type"`
+	TimeInForce             TimeInForce             `url:"timeInForce,omitempty"`
+	Quantity                decimal.Decimal         `url:"quantity"`
+	Price                   decimal.Decimal         `url:"price,omitempty"`
+	NewClientOrderId        string                  `url:"newClientOrderId,omitempty"`
+	StrategyId              int                     `url:"strategyId,omitempty"`
+	StrategyType            int                     `url:"strategyType,omitempty"`
+	IcebergQty              decimal.Decimal         `url:"icebergQty,omitempty"`
+	NewOrderRespType        NewOrderRespType        `url:"newOrderRespType,omitempty"`
+	SelfTradePreventionMode SelfTradePreventionMode `url:"selfTradePreventionMode,omitempty"`
+	RecvWindow              int64                   `url:"recvWindow,omitempty"`
+	Timestamp               int64                   `url:"timestamp,omitempty"`
 }
 
 // Validate checks the fields of Order for validity.
@@ -828,11 +819,30 @@ func (o *NewSORRequest) Validate() error {
 		return errors.New("symbol is required")
 	}
 
-	if o.Quantity <= 0 {
+	if err := o.Side.Validate(); err != nil {
+		return err
+	}
+
+	// SOR supports LIMIT and MARKET orders only
+	if o.Type != OrderTypeLimit && o.Type != OrderTypeMarket {
+		return fmt.Errorf("invalid SOR order type %q, expected LIMIT or MARKET", o.Type)
+	}
+
+	if err := optional(o.TimeInForce); err != nil {
+		return err
+	}
+	if err := optional(o.NewOrderRespType); err != nil {
+		return err
+	}
+	if err := optional(o.SelfTradePreventionMode); err != nil {
+		return err
+	}
+
+	if !o.Quantity.IsPositive() {
 		return errors.New("quantity must be greater than 0")
 	}
 
-	if o.Type == "LIMIT" && (o.Price <= 0) {
+	if o.Type == OrderTypeLimit && !o.Price.IsPositive() {
 		return errors.New("price must be set and greater than 0 for LIMIT order type")
 	}
 
@@ -844,38 +854,45 @@ func (o *NewSORRequest) Validate() error {
 		return errors.New("recvWindow cannot be greater than 60000")
 	}
 
-	if o.Timestamp <= 0 {
-		return errors.New("timestamp must be a positive integer and is required")
-	}
-
 	return nil
 }
 
+// CheckRules checks the order against the symbol rules of the registry
+func (o *NewSORRequest) CheckRules(rules *SymbolRegistry) error {
+	return rules.check(o.Symbol, orderRules{
+		orderType:  o.Type,
+		side:       o.Side,
+		price:      o.Price,
+		quantity:   o.Quantity,
+		icebergQty: o.IcebergQty,
+	})
+}
+
 type NewSORResponse struct {
-	Symbol              string `json:"symbol"`
-	OrderId             int    `json:"orderId"`
-	OrderListId         int    `json:"orderListId"`
-	ClientOrderId       string `json:"clientOrderId"`
-	TransactTime        int64  `json:"transactTime"`
-	Price               string `json:"price"`
-	OrigQty             string `json:"origQty"`
-	ExecutedQty         string `json:"executedQty"`
-	CummulativeQuoteQty string `json:"cummulativeQuoteQty"`
-	Status              string `json:"status"`
-	TimeInForce         string `json:"timeInForce"`
-	Type                string `json:"type"`
-	Side                string `json:"side"`
-	WorkingTime         int64  `json:"workingTime"`
+	Symbol              string          `json:"symbol"`
+	OrderId             int             `json:"orderId"`
+	OrderListId         int             `json:"orderListId"`
+	ClientOrderId       string          `json:"clientOrderId"`
+	TransactTime        int64           `json:"transactTime"`
+	Price               decimal.Decimal `json:"price"`
+	OrigQty             decimal.Decimal `json:"origQty"`
+	ExecutedQty         decimal.Decimal `json:"executedQty"`
+	CummulativeQuoteQty decimal.Decimal `json:"cummulativeQuoteQty"`
+	Status              OrderStatus     `json:"status"`
+	TimeInForce         TimeInForce     `json:"timeInForce"`
+	Type                OrderType       `json:"type"`
+	Side                Side            `json:"side"`
+	WorkingTime         int64           `json:"workingTime"`
 	Fills               []struct {
-		MatchType       string `json:"matchType"`
-		Price           string `json:"price"`
-		Qty             string `json:"qty"`
-		Commission      string `json:"commission"`
-		CommissionAsset string `json:"commissionAsset"`
-		TradeId         int    `json:"tradeId"`
-		AllocId         int    `json:"allocId"`
+		MatchType       string          `json:"matchType"`
+		Price           decimal.Decimal `json:"price"`
+		Qty             decimal.Decimal `json:"qty"`
+		Commission      decimal.Decimal `json:"commission"`
+		CommissionAsset string          `json:"commissionAsset"`
+		TradeId         int             `json:"tradeId"`
+		AllocId         int             `json:"allocId"`
 	} `json:"fills"`
-	WorkingFloor            string `json:"workingFloor"`
-	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
-	UsedSor                 bool   `json:"usedSor"`
+	WorkingFloor            string                  `json:"workingFloor"`
+	SelfTradePreventionMode SelfTradePreventionMode `json:"selfTradePreventionMode"`
+	UsedSor                 bool                    `json:"usedSor"`
 }
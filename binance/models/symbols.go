@@ -0,0 +1,276 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// defaultAvgPriceMins average price window of symbols without PERCENT_PRICE_BY_SIDE filter
+	defaultAvgPriceMins = 5
+	// openOrdersMaxAge open orders count is not checked this long after GetOpenOrders, fills since are not known
+	openOrdersMaxAge = 2 * time.Minute
+)
+
+// SymbolRegistry cached trading rules of symbols, loaded by BinanceClient.GetExchangeInfo.
+// It also caches the average price and the open orders count which PERCENT_PRICE_BY_SIDE and MAX_NUM_ORDERS
+// filters are checked against. Orders of symbols which are not loaded are not checked, a nil registry loads none.
+type SymbolRegistry struct {
+	mu         sync.RWMutex
+	symbols    map[string]*SymbolInfo
+	avgPrices  map[string]cachedAvgPrice
+	openOrders map[string]cachedOpenOrders
+	updatedAt  time.Time
+}
+
+type cachedAvgPrice struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+type cachedOpenOrders struct {
+	count int
+	at    time.Time // of GetOpenOrders, orders placed and cancelled since adjust the count
+}
+
+func NewSymbolRegistry() *SymbolRegistry {
+	return &SymbolRegistry{
+		symbols:    make(map[string]*SymbolInfo),
+		avgPrices:  make(map[string]cachedAvgPrice),
+		openOrders: make(map[string]cachedOpenOrders),
+	}
+}
+
+// Load adds or replaces rules of the symbols of the exchange info
+func (r *SymbolRegistry) Load(info *ExchangeInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range info.Symbols {
+		symbol := info.Symbols[i]
+		r.symbols[symbol.Symbol] = &symbol
+	}
+	r.updatedAt = time.Now()
+}
+
+// Get returns rules of the symbol
+func (r *SymbolRegistry) Get(symbol string) (*SymbolInfo, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.symbols[strings.ToUpper(symbol)]
+	return info, ok
+}
+
+// BaseAsset base asset of the symbol, false if its rules are not loaded
+func (r *SymbolRegistry) BaseAsset(symbol string) (string, bool) {
+	info, ok := r.Get(symbol)
+	if !ok {
+		return "", false
+	}
+	return info.BaseAsset, true
+}
+
+// UpdatedAt time of the last Load
+func (r *SymbolRegistry) UpdatedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.updatedAt
+}
+
+// SetAvgPrice caches the current average price of the symbol
+func (r *SymbolRegistry) SetAvgPrice(symbol string, price decimal.Decimal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.avgPrices[strings.ToUpper(symbol)] = cachedAvgPrice{price: price, at: time.Now()}
+}
+
+// AvgPrice cached average price of the symbol, false if it is older than the average price window
+func (r *SymbolRegistry) AvgPrice(symbol string) (decimal.Decimal, bool) {
+	if r == nil {
+		return decimal.Zero, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	symbol = strings.ToUpper(symbol)
+	cached, ok := r.avgPrices[symbol]
+	if !ok {
+		return decimal.Zero, false
+	}
+	mins := defaultAvgPriceMins
+	if info, ok := r.symbols[symbol]; ok && info.Filters.PercentPriceBySide != nil && info.Filters.PercentPriceBySide.AvgPriceMins > 0 {
+		mins = info.Filters.PercentPriceBySide.AvgPriceMins
+	}
+	if time.Since(cached.at) > time.Duration(mins)*time.Minute {
+		return decimal.Zero, false
+	}
+	return cached.price, true
+}
+
+// SetOpenOrders caches the open orders count of the symbol returned by GetOpenOrders
+func (r *SymbolRegistry) SetOpenOrders(symbol string, count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.openOrders[strings.ToUpper(symbol)] = cachedOpenOrders{count: count, at: time.Now()}
+}
+
+// LoadOpenOrders replaces open orders counts of all symbols, symbols missing in counts have none
+func (r *SymbolRegistry) LoadOpenOrders(counts map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.openOrders = make(map[string]cachedOpenOrders, len(r.symbols))
+	for symbol := range r.symbols {
+		r.openOrders[symbol] = cachedOpenOrders{at: now}
+	}
+	for symbol, count := range counts {
+		r.openOrders[strings.ToUpper(symbol)] = cachedOpenOrders{count: count, at: now}
+	}
+}
+
+// AddOpenOrders adjusts the cached count by orders placed or cancelled, counts not loaded stay unknown
+func (r *SymbolRegistry) AddOpenOrders(symbol string, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbol = strings.ToUpper(symbol)
+	cached, ok := r.openOrders[symbol]
+	if !ok {
+		return
+	}
+	cached.count += delta
+	if cached.count < 0 {
+		cached.count = 0
+	}
+	r.openOrders[symbol] = cached
+}
+
+// OpenOrders cached open orders count of the symbol, false if it is not loaded or too old
+func (r *SymbolRegistry) OpenOrders(symbol string) (int, bool) {
+	if r == nil {
+		return 0, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cached, ok := r.openOrders[strings.ToUpper(symbol)]
+	if !ok || time.Since(cached.at) > openOrdersMaxAge {
+		return 0, false
+	}
+	return cached.count, true
+}
+
+// RoundPrice rounds price to the nearest multiple of tickSize
+func (s *SymbolInfo) RoundPrice(price decimal.Decimal) decimal.Decimal {
+	f := s.Filters.Price
+	if f == nil || !f.TickSize.IsPositive() {
+		return price
+	}
+	return price.Sub(f.MinPrice).Div(f.TickSize).Round(0).Mul(f.TickSize).Add(f.MinPrice)
+}
+
+// RoundQuantity rounds quantity down to a multiple of stepSize so that it never exceeds the available balance
+func (s *SymbolInfo) RoundQuantity(quantity decimal.Decimal) decimal.Decimal {
+	f := s.Filters.LotSize
+	if f == nil || !f.StepSize.IsPositive() {
+		return quantity
+	}
+	return quantity.Sub(f.MinQty).Div(f.StepSize).Floor().Mul(f.StepSize).Add(f.MinQty)
+}
+
+// orderRules fields of an order checked against the symbol rules, zero values are not checked
+type orderRules struct {
+	orderType  OrderType
+	side       Side
+	price      decimal.Decimal
+	stopPrice  decimal.Decimal
+	quantity   decimal.Decimal
+	icebergQty decimal.Decimal
+}
+
+func (s *SymbolInfo) allowsOrderType(orderType OrderType) bool {
+	for _, t := range s.OrderTypes {
+		if t == orderType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrder checks the order against the symbol status, allowed order types and filters
+func (s *SymbolInfo) checkOrder(o orderRules) error {
+	if s.Status != "" && s.Status != "TRADING" {
+		return fmt.Errorf("symbol %s is not trading, status %s", s.Symbol, s.Status)
+	}
+
+	if o.orderType != "" && len(s.OrderTypes) > 0 && !s.allowsOrderType(o.orderType) {
+		return fmt.Errorf("order type %s is not allowed for %s", o.orderType, s.Symbol)
+	}
+	if o.icebergQty.IsPositive() && !s.IcebergAllowed {
+		return fmt.Errorf("iceberg orders are not allowed for %s", s.Symbol)
+	}
+
+	f := s.Filters
+	if err := f.Price.CheckPrice(o.price); err != nil {
+		return err
+	}
+	if err := f.Price.CheckPrice(o.stopPrice); err != nil {
+		return err
+	}
+	if err := f.LotSize.CheckQuantity(o.quantity); err != nil {
+		return err
+	}
+	if o.orderType == OrderTypeMarket {
+		if err := f.MarketLotSize.CheckQuantity(o.quantity); err != nil {
+			return err
+		}
+	}
+	if err := f.CheckNotional(o.price, o.quantity); err != nil {
+		return err
+	}
+	if err := f.IcebergParts.CheckIcebergParts(o.quantity, o.icebergQty); err != nil {
+		return err
+	}
+	if err := f.LotSize.CheckQuantity(o.icebergQty); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// check checks the orders placed together against the rules of the symbol if they are loaded.
+// Prices are checked against the cached average price and the count of new orders against open orders.
+func (r *SymbolRegistry) check(symbol string, orders ...orderRules) error {
+	info, ok := r.Get(symbol)
+	if !ok {
+		return nil
+	}
+
+	if open, ok := r.OpenOrders(symbol); ok {
+		if err := info.Filters.MaxNumOrders.CheckOpenOrders(open + len(orders) - 1); err != nil {
+			return err
+		}
+	}
+
+	avgPrice, hasAvgPrice := r.AvgPrice(symbol)
+	for _, o := range orders {
+		if err := info.checkOrder(o); err != nil {
+			return err
+		}
+		if hasAvgPrice {
+			if err := info.Filters.PercentPriceBySide.CheckPrice(o.side, o.price, avgPrice); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
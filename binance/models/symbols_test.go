@@ -0,0 +1,162 @@
+package models
+
+import (
+	"errors"
+	"gateaway/binance"
+	"testing"
+	"time"
+)
+
+func newTestRegistry() *SymbolRegistry {
+	r := NewSymbolRegistry()
+	r.Load(&ExchangeInfo{Symbols: []SymbolInfo{{
+		Symbol:     "BTCUSDT",
+		Status:     "TRADING",
+		BaseAsset:  "BTC",
+		QuoteAsset: "USDT",
+		OrderTypes: OrderTypes{OrderTypeLimit, OrderTypeLimitMaker, OrderTypeMarket, OrderTypeStopLossLimit},
+		OcoAllowed: true,
+		Filters: SymbolFilters{
+			Price:              &PriceFilter{MinPrice: d("0.01"), MaxPrice: d("1000000"), TickSize: d("0.01")},
+			LotSize:            &LotSizeFilter{MinQty: d("0.001"), MaxQty: d("100"), StepSize: d("0.001")},
+			MaxNumOrders:       &MaxNumOrdersFilter{MaxNumOrders: 2},
+			PercentPriceBySide: &PercentPriceBySideFilter{BidMultiplierUp: d("1.2"), BidMultiplierDown: d("0.8"), AskMultiplierUp: d("1.2"), AskMultiplierDown: d("0.8"), AvgPriceMins: 5},
+		},
+	}}})
+	return r
+}
+
+func TestRegistryAvgPriceExpires(t *testing.T) {
+	r := newTestRegistry()
+
+	if _, ok := r.AvgPrice("BTCUSDT"); ok {
+		t.Fatal("average price known before it is set")
+	}
+	r.SetAvgPrice("btcusdt", d("100"))
+	if price, ok := r.AvgPrice("BTCUSDT"); !ok || !price.Equal(d("100")) {
+		t.Fatalf("AvgPrice = %s, %v, want 100", price, ok)
+	}
+
+	r.avgPrices["BTCUSDT"] = cachedAvgPrice{price: d("100"), at: time.Now().Add(-6 * time.Minute)}
+	if _, ok := r.AvgPrice("BTCUSDT"); ok {
+		t.Fatal("average price older than the window is used")
+	}
+}
+
+func TestRegistryOpenOrders(t *testing.T) {
+	r := newTestRegistry()
+
+	// Counts which are not loaded stay unknown
+	r.AddOpenOrders("BTCUSDT", 1)
+	if _, ok := r.OpenOrders("BTCUSDT"); ok {
+		t.Fatal("open orders known before they are loaded")
+	}
+
+	r.LoadOpenOrders(map[string]int{"ETHUSDT": 3})
+	if count, ok := r.OpenOrders("BTCUSDT"); !ok || count != 0 {
+		t.Fatalf("BTCUSDT open orders = %d, %v, want 0", count, ok)
+	}
+	if count, _ := r.OpenOrders("ETHUSDT"); count != 3 {
+		t.Fatalf("ETHUSDT open orders = %d, want 3", count)
+	}
+
+	r.AddOpenOrders("BTCUSDT", 2)
+	r.AddOpenOrders("BTCUSDT", -5)
+	if count, _ := r.OpenOrders("BTCUSDT"); count != 0 {
+		t.Fatalf("open orders = %d, want floor of 0", count)
+	}
+
+	r.SetOpenOrders("BTCUSDT", 1)
+	r.openOrders["BTCUSDT"] = cachedOpenOrders{count: 1, at: time.Now().Add(-openOrdersMaxAge - time.Second)}
+	if _, ok := r.OpenOrders("BTCUSDT"); ok {
+		t.Fatal("open orders count older than the max age is used")
+	}
+}
+
+func TestNilRegistryChecksNothing(t *testing.T) {
+	var r *SymbolRegistry
+
+	if _, ok := r.Get("BTCUSDT"); ok {
+		t.Fatal("nil registry has rules")
+	}
+	o := OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: d("0.0001"), Quantity: d("0.0001")}
+	if err := o.CheckRules(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOrderCheckRules(t *testing.T) {
+	r := newTestRegistry()
+	order := func(orderType OrderType, price, quantity string) OrderRequest {
+		o := OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: orderType, Quantity: d(quantity)}
+		if price != "" {
+			o.Price = d(price)
+		}
+		return o
+	}
+
+	valid := order(OrderTypeLimit, "100.01", "0.5")
+	if err := valid.CheckRules(r); err != nil {
+		t.Fatalf("valid order: %v", err)
+	}
+	offTick := order(OrderTypeLimit, "100.001", "0.5")
+	if err := offTick.CheckRules(r); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("PRICE_FILTER error = %v, want filter failure", err)
+	}
+	notAllowed := order(OrderTypeTakeProfit, "", "0.5")
+	if err := notAllowed.CheckRules(r); err == nil {
+		t.Fatal("order type which is not allowed is accepted")
+	}
+	unknownSymbol := order(OrderTypeLimit, "0.0001", "0.0001")
+	unknownSymbol.Symbol = "ETHBTC"
+	if err := unknownSymbol.CheckRules(r); err != nil {
+		t.Fatalf("order of a symbol without rules: %v", err)
+	}
+
+	r.SetAvgPrice("BTCUSDT", d("100"))
+	outsideBand := order(OrderTypeLimit, "130", "0.5")
+	if err := outsideBand.CheckRules(r); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("PERCENT_PRICE_BY_SIDE error = %v, want filter failure", err)
+	}
+
+	r.LoadOpenOrders(map[string]int{"BTCUSDT": 2})
+	if err := valid.CheckRules(r); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("MAX_NUM_ORDERS error = %v, want filter failure", err)
+	}
+}
+
+func TestOCOCheckRulesChecksBothLegs(t *testing.T) {
+	r := newTestRegistry()
+	stopLimitPrice := d("89.99")
+	oco := NewOCORequest{
+		Symbol:         "BTCUSDT",
+		Side:           SideSell,
+		Quantity:       d("0.5"),
+		Price:          d("110"),
+		StopPrice:      d("90"),
+		StopLimitPrice: &stopLimitPrice,
+	}
+	if err := oco.CheckRules(r); err != nil {
+		t.Fatalf("valid OCO: %v", err)
+	}
+
+	offTick := d("89.999")
+	oco.StopLimitPrice = &offTick
+	if err := oco.CheckRules(r); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("stop leg error = %v, want filter failure", err)
+	}
+
+	// Both legs count against MAX_NUM_ORDERS
+	oco.StopLimitPrice = &stopLimitPrice
+	r.LoadOpenOrders(map[string]int{"BTCUSDT": 1})
+	if err := oco.CheckRules(r); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("MAX_NUM_ORDERS error = %v, want filter failure", err)
+	}
+
+	info, _ := r.Get("BTCUSDT")
+	info.OcoAllowed = false
+	r.LoadOpenOrders(nil)
+	if err := oco.CheckRules(r); err == nil {
+		t.Fatal("OCO accepted for a symbol which does not allow it")
+	}
+}
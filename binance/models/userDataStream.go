@@ -0,0 +1,18 @@
+package models
+
+import "errors"
+
+type ListenKeyResponse struct {
+	ListenKey string `json:"listenKey"`
+}
+
+type ListenKeyRequest struct {
+	ListenKey string `url:"listenKey"`
+}
+
+func (r ListenKeyRequest) Validate() error {
+	if r.ListenKey == "" {
+		return errors.New("listenKey is required")
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+package oms
+
+import (
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	wsmodels "gateaway/binance/ws/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrUnknownOrder update of an order which was not submitted through the manager
+	ErrUnknownOrder = errors.New("unknown client order id")
+	// ErrDuplicateOrder order with the client order id is already tracked
+	ErrDuplicateOrder = errors.New("duplicate client order id")
+	// ErrIllegalTransition update would move the order to a status it can not reach
+	ErrIllegalTransition = errors.New("illegal order status transition")
+	// ErrStaleUpdate update is older than the order state, e.g. REST response after the execution report
+	ErrStaleUpdate = errors.New("stale order update")
+)
+
+// UpdateHandler is called after every change of an order
+type UpdateHandler func(o Order)
+
+// Manager tracks orders keyed by clientOrderId and positions of their fills
+type Manager struct {
+	mu        sync.RWMutex
+	orders    map[string]*Order
+	positions map[string]*Position
+	onUpdate  UpdateHandler
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		orders:    make(map[string]*Order),
+		positions: make(map[string]*Position),
+	}
+}
+
+// OnUpdate sets the callback called after each change of any order
+func (m *Manager) OnUpdate(handler UpdateHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onUpdate = handler
+}
+
+// update is a change of order state from a response or an execution report
+type update struct {
+	clientOrderID      string
+	orderID            int64
+	status             models.OrderStatus
+	executedQty        decimal.Decimal
+	cumulativeQuoteQty decimal.Decimal
+	rejectReason       string
+	time               int64 // Binance time of the change, zero when the response does not have one
+}
+
+// Submit registers the order in PENDING_NEW status before it is sent, NewClientOrderID is required
+func (m *Manager) Submit(r models.OrderRequest) (Order, error) {
+	if r.NewClientOrderID == "" {
+		return Order{}, errors.New("newClientOrderId is required to track the order")
+	}
+
+	m.mu.Lock()
+	if _, ok := m.orders[r.NewClientOrderID]; ok {
+		m.mu.Unlock()
+		return Order{}, fmt.Errorf("%w: %s", ErrDuplicateOrder, r.NewClientOrderID)
+	}
+
+	now := time.Now().UnixMilli()
+	o := &Order{
+		ClientOrderID: r.NewClientOrderID,
+		Symbol:        strings.ToUpper(r.Symbol),
+		Side:          r.Side,
+		Type:          r.Type,
+		TimeInForce:   r.TimeInForce,
+		Price:         r.Price,
+		Quantity:      r.Quantity,
+		Status:        StatusPendingNew,
+		CreateTime:    now,
+		UpdateTime:    now,
+	}
+	m.orders[o.ClientOrderID] = o
+	order, handler := *o, m.onUpdate
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(order)
+	}
+	return order, nil
+}
+
+// Adopt tracks an order placed before the manager started, e.g. by a previous run of the gateway.
+// Fills before the adoption are not counted in positions.
+func (m *Manager) Adopt(o Order) (Order, error) {
+	if o.ClientOrderID == "" {
+		return Order{}, errors.New("clientOrderId is required to track the order")
+	}
+
+	m.mu.Lock()
+	if _, ok := m.orders[o.ClientOrderID]; ok {
+		m.mu.Unlock()
+		return Order{}, fmt.Errorf("%w: %s", ErrDuplicateOrder, o.ClientOrderID)
+	}
+	o.Symbol = strings.ToUpper(o.Symbol)
+	m.orders[o.ClientOrderID] = &o
+	handler := m.onUpdate
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(o)
+	}
+	return o, nil
+}
+
+// Reject marks the order rejected when its request failed, orders whose execution status is unknown
+// stay PENDING_NEW until they are looked up or reconciled.
+// It returns whether the order was rejected.
+func (m *Manager) Reject(clientOrderID string, err error) (Order, bool) {
+	if binance.IsExecutionUnknown(err) || errors.Is(err, binance.ErrOrderStatusUnknown) {
+		o, _ := m.Order(clientOrderID)
+		return o, false
+	}
+
+	reason := err.Error()
+	if apiErr, ok := binance.AsAPIError(err); ok {
+		reason = apiErr.Msg
+	}
+	o, applyErr := m.apply(update{clientOrderID: clientOrderID, status: models.OrderStatusRejected, rejectReason: reason})
+	return o, applyErr == nil
+}
+
+// ApplyNewOrder applies the response of NewOrder
+func (m *Manager) ApplyNewOrder(r *models.OrderResponseFull) (Order, error) {
+	return m.apply(update{
+		clientOrderID:      r.ClientOrderId,
+		orderID:            r.OrderId,
+		status:             r.Status,
+		executedQty:        r.ExecutedQty,
+		cumulativeQuoteQty: r.CummulativeQuoteQty,
+		time:               r.TransactTime,
+	})
+}
+
+// ApplyCancel applies the response of CancelOrder to the canceled order
+func (m *Manager) ApplyCancel(r *models.OrderCancelResponse) (Order, error) {
+	return m.apply(update{
+		clientOrderID:      r.OrigClientOrderId,
+		orderID:            r.OrderId,
+		status:             r.Status,
+		executedQty:        r.ExecutedQty,
+		cumulativeQuoteQty: r.CummulativeQuoteQty,
+		time:               r.TransactTime,
+	})
+}
+
+// ApplyGetOrder applies the order returned by GetOrder
+func (m *Manager) ApplyGetOrder(r *models.GetOrderResponse) (Order, error) {
+	return m.apply(update{
+		clientOrderID:      r.ClientOrderId,
+		orderID:            int64(r.OrderId),
+		status:             r.Status,
+		executedQty:        r.ExecutedQty,
+		cumulativeQuoteQty: r.CummulativeQuoteQty,
+		time:               r.UpdateTime,
+	})
+}
+
+// ApplyCancelReplace applies both parts of CancelReplace response, the new order must be submitted before.
+// A part which failed is not applied, a rejected new order is marked rejected.
+func (m *Manager) ApplyCancelReplace(r *models.CancelReplaceResponse) error {
+	var errs []error
+
+	c := r.CancelResponse
+	if r.CancelResult == models.CancelReplaceSuccess {
+		_, err := m.apply(update{
+			clientOrderID:      c.OrigClientOrderId,
+			orderID:            c.OrderId,
+			status:             c.Status,
+			executedQty:        c.ExecutedQty,
+			cumulativeQuoteQty: c.CummulativeQuoteQty,
+		})
+		errs = append(errs, err)
+	}
+
+	n := r.NewOrderResponse
+	switch r.NewOrderResult {
+	case models.CancelReplaceSuccess:
+		_, err := m.apply(update{
+			clientOrderID:      n.ClientOrderId,
+			orderID:            n.OrderId,
+			status:             n.Status,
+			executedQty:        n.ExecutedQty,
+			cumulativeQuoteQty: n.CummulativeQuoteQty,
+			time:               int64(n.TransactTime),
+		})
+		errs = append(errs, err)
+	case models.CancelReplaceFailure:
+		if n.ClientOrderId != "" {
+			_, err := m.apply(update{clientOrderID: n.ClientOrderId, status: models.OrderStatusRejected, rejectReason: n.Msg})
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ApplyExecutionReport applies the order update of the user data stream
+func (m *Manager) ApplyExecutionReport(e *wsmodels.ExecutionReportEvent) (Order, error) {
+	// Cancel reports carry the client order id of the cancel request, the order has the original one
+	clientOrderID := e.ClientOrderID
+	if e.Status == models.OrderStatusCanceled && e.OrigClientOrderID != "" {
+		clientOrderID = e.OrigClientOrderID
+	}
+
+	return m.apply(update{
+		clientOrderID:      clientOrderID,
+		orderID:            e.OrderID,
+		status:             e.Status,
+		executedQty:        e.CumulativeFilledQty,
+		cumulativeQuoteQty: e.CumulativeQuoteQty,
+		rejectReason:       e.RejectReason,
+		time:               e.TransactTime,
+	})
+}
+
+// apply moves the order to the status of the update, fills change the position of the symbol
+func (m *Manager) apply(u update) (Order, error) {
+	status, err := parseStatus(u.status)
+	if err != nil {
+		return Order{}, err
+	}
+
+	m.mu.Lock()
+	o, ok := m.orders[u.clientOrderID]
+	if !ok {
+		m.mu.Unlock()
+		return Order{}, fmt.Errorf("%w: %s", ErrUnknownOrder, u.clientOrderID)
+	}
+
+	if err := check(o, u, status); err != nil {
+		order := *o
+		m.mu.Unlock()
+		return order, err
+	}
+
+	// Repeated update, e.g. REST response and execution report of the same change
+	if status == o.Status && u.executedQty.Equal(o.ExecutedQty) {
+		if o.OrderID == 0 {
+			o.OrderID = u.orderID
+		}
+		order := *o
+		m.mu.Unlock()
+		return order, nil
+	}
+
+	m.fill(o, u)
+	// Local time of Submit is replaced by Binance time of the first update
+	if u.time != 0 && (u.time > o.UpdateTime || o.Status == StatusPendingNew) {
+		o.UpdateTime = u.time
+	}
+	if u.orderID != 0 {
+		o.OrderID = u.orderID
+	}
+	o.Status = status
+	if u.rejectReason != "" && u.rejectReason != "NONE" {
+		o.RejectReason = u.rejectReason
+	}
+
+	order, handler := *o, m.onUpdate
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(order)
+	}
+	return order, nil
+}
+
+// check rejects updates older than the order state and transitions the status can not make
+func check(o *Order, u update, status Status) error {
+	if u.executedQty.LessThan(o.ExecutedQty) {
+		return fmt.Errorf("%w: %s executed %s after %s", ErrStaleUpdate, o.ClientOrderID, u.executedQty, o.ExecutedQty)
+	}
+
+	if status == o.Status || o.Status.CanTransition(status) {
+		return nil
+	}
+
+	// An acknowledgement arriving after a later change is stale, not illegal
+	if u.time != 0 && u.time <= o.UpdateTime && u.executedQty.Equal(o.ExecutedQty) && !status.IsTerminal() {
+		return fmt.Errorf("%w: %s %s after %s", ErrStaleUpdate, o.ClientOrderID, status, o.Status)
+	}
+	return fmt.Errorf("%w: %s %s to %s", ErrIllegalTransition, o.ClientOrderID, o.Status, status)
+}
+
+// fill adds quantity executed since the last update to the position of the symbol
+func (m *Manager) fill(o *Order, u update) {
+	qty := u.executedQty.Sub(o.ExecutedQty)
+	if !qty.IsPositive() {
+		return
+	}
+	quote := u.cumulativeQuoteQty.Sub(o.CumulativeQuoteQty)
+
+	p, ok := m.positions[o.Symbol]
+	if !ok {
+		p = &Position{Symbol: o.Symbol}
+		m.positions[o.Symbol] = p
+	}
+	if o.Side == models.SideSell {
+		p.SellQty = p.SellQty.Add(qty)
+		p.SellQuote = p.SellQuote.Add(quote)
+	} else {
+		p.BuyQty = p.BuyQty.Add(qty)
+		p.BuyQuote = p.BuyQuote.Add(quote)
+	}
+
+	o.ExecutedQty = u.executedQty
+	o.CumulativeQuoteQty = u.cumulativeQuoteQty
+}
+
+// Order returns the order with the client order id
+func (m *Manager) Order(clientOrderID string) (Order, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	o, ok := m.orders[clientOrderID]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// Orders returns all tracked orders of the symbol, of all symbols when it is empty, oldest first
+func (m *Manager) Orders(symbol string) []Order {
+	return m.filter(symbol, func(o *Order) bool { return true })
+}
+
+// OpenOrders returns orders of the symbol which may still be filled, of all symbols when it is empty
+func (m *Manager) OpenOrders(symbol string) []Order {
+	return m.filter(symbol, func(o *Order) bool { return o.IsOpen() })
+}
+
+func (m *Manager) filter(symbol string, keep func(o *Order) bool) []Order {
+	symbol = strings.ToUpper(symbol)
+
+	m.mu.RLock()
+	var orders []Order
+	for _, o := range m.orders {
+		if (symbol == "" || o.Symbol == symbol) && keep(o) {
+			orders = append(orders, *o)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreateTime != orders[j].CreateTime {
+			return orders[i].CreateTime < orders[j].CreateTime
+		}
+		return orders[i].ClientOrderID < orders[j].ClientOrderID
+	})
+	return orders
+}
+
+// FilledQty returns executed quantity of the order
+func (m *Manager) FilledQty(clientOrderID string) (decimal.Decimal, bool) {
+	o, ok := m.Order(clientOrderID)
+	return o.ExecutedQty, ok
+}
+
+// Position returns filled quantities of the symbol
+func (m *Manager) Position(symbol string) Position {
+	symbol = strings.ToUpper(symbol)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if p, ok := m.positions[symbol]; ok {
+		return *p
+	}
+	return Position{Symbol: symbol}
+}
+
+// Positions returns positions of all symbols with fills
+func (m *Manager) Positions() []Position {
+	m.mu.RLock()
+	positions := make([]Position, 0, len(m.positions))
+	for _, p := range m.positions {
+		positions = append(positions, *p)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions
+}
+
+// Forget stops tracking the order, open orders can not be forgotten
+func (m *Manager) Forget(clientOrderID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, ok := m.orders[clientOrderID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownOrder, clientOrderID)
+	}
+	if o.IsOpen() {
+		return fmt.Errorf("order %s is %s", clientOrderID, o.Status)
+	}
+	delete(m.orders, clientOrderID)
+	return nil
+}
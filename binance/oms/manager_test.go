@@ -0,0 +1,264 @@
+package oms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+	wsmodels "gateaway/binance/ws/models"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func request(clientOrderID string, side models.Side, quantity string) models.OrderRequest {
+	return models.OrderRequest{
+		Symbol:           "BTCUSDT",
+		Side:             side,
+		Type:             models.OrderTypeLimit,
+		TimeInForce:      models.TimeInForceGTC,
+		Price:            d("100"),
+		Quantity:         d(quantity),
+		NewClientOrderID: clientOrderID,
+	}
+}
+
+func report(clientOrderID string, status models.OrderStatus, executed, quote string, at int64) *wsmodels.ExecutionReportEvent {
+	return &wsmodels.ExecutionReportEvent{
+		Symbol:              "BTCUSDT",
+		ClientOrderID:       clientOrderID,
+		Status:              status,
+		OrderID:             1,
+		CumulativeFilledQty: d(executed),
+		CumulativeQuoteQty:  d(quote),
+		TransactTime:        at,
+	}
+}
+
+func submitted(t *testing.T, m *Manager, clientOrderID string, side models.Side, quantity string) {
+	t.Helper()
+	if _, err := m.Submit(request(clientOrderID, side, quantity)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	m := NewManager()
+	var updates []Status
+	m.OnUpdate(func(o Order) { updates = append(updates, o.Status) })
+
+	o, err := m.Submit(request("a", models.SideBuy, "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != StatusPendingNew || o.Symbol != "BTCUSDT" {
+		t.Fatalf("submitted order = %+v", o)
+	}
+	if _, err := m.Submit(request("a", models.SideBuy, "2")); !errors.Is(err, ErrDuplicateOrder) {
+		t.Fatalf("duplicate error = %v", err)
+	}
+
+	o, err = m.ApplyNewOrder(&models.OrderResponseFull{ClientOrderId: "a", OrderId: 1, Status: models.OrderStatusNew, TransactTime: 1000})
+	if err != nil || o.Status != StatusNew || o.OrderID != 1 || o.UpdateTime != 1000 {
+		t.Fatalf("acknowledged order = %+v, %v", o, err)
+	}
+
+	if _, err := m.ApplyExecutionReport(report("a", models.OrderStatusPartiallyFilled, "0.5", "50", 2000)); err != nil {
+		t.Fatal(err)
+	}
+	o, err = m.ApplyExecutionReport(report("a", models.OrderStatusFilled, "2", "201", 3000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != StatusFilled || !o.RemainingQty().IsZero() || !o.AvgPrice().Equal(d("100.5")) {
+		t.Fatalf("filled order = %+v", o)
+	}
+
+	want := []Status{StatusPendingNew, StatusNew, StatusPartiallyFilled, StatusFilled}
+	if len(updates) != len(want) {
+		t.Fatalf("updates = %v, want %v", updates, want)
+	}
+	for i := range want {
+		if updates[i] != want[i] {
+			t.Fatalf("updates = %v, want %v", updates, want)
+		}
+	}
+
+	if p := m.Position("btcusdt"); !p.Net().Equal(d("2")) || !p.BuyQuote.Equal(d("201")) {
+		t.Fatalf("position = %+v, want 2 bought for 201", p)
+	}
+	if len(m.OpenOrders("")) != 0 {
+		t.Fatalf("open orders = %v, want none", m.OpenOrders(""))
+	}
+	if err := m.Forget("a"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIllegalAndStaleUpdates(t *testing.T) {
+	m := NewManager()
+	submitted(t, m, "a", models.SideSell, "1")
+
+	if _, err := m.ApplyExecutionReport(report("a", models.OrderStatusFilled, "1", "100", 2000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// REST response of the acknowledgement arrives after the fill
+	_, err := m.ApplyNewOrder(&models.OrderResponseFull{ClientOrderId: "a", OrderId: 1, Status: models.OrderStatusNew, TransactTime: 1000})
+	if !errors.Is(err, ErrStaleUpdate) {
+		t.Fatalf("late acknowledgement error = %v, want ErrStaleUpdate", err)
+	}
+	if _, err := m.ApplyExecutionReport(report("a", models.OrderStatusCanceled, "1", "100", 3000)); !errors.Is(err, ErrIllegalTransition) {
+		t.Fatalf("cancel of a filled order error = %v, want ErrIllegalTransition", err)
+	}
+	if _, err := m.ApplyExecutionReport(report("a", models.OrderStatusFilled, "0.5", "50", 4000)); !errors.Is(err, ErrStaleUpdate) {
+		t.Fatalf("lower executed quantity error = %v, want ErrStaleUpdate", err)
+	}
+
+	// Repeated update does not fill twice
+	if _, err := m.ApplyExecutionReport(report("a", models.OrderStatusFilled, "1", "100", 2000)); err != nil {
+		t.Fatal(err)
+	}
+	if p := m.Position("BTCUSDT"); !p.Net().Equal(d("-1")) {
+		t.Fatalf("net position = %s, want -1", p.Net())
+	}
+
+	if _, err := m.ApplyExecutionReport(report("b", models.OrderStatusNew, "0", "0", 1000)); !errors.Is(err, ErrUnknownOrder) {
+		t.Fatalf("unknown order error = %v, want ErrUnknownOrder", err)
+	}
+}
+
+func TestCancelReportUsesOriginalClientOrderID(t *testing.T) {
+	m := NewManager()
+	submitted(t, m, "a", models.SideBuy, "1")
+
+	e := report("cancel-1", models.OrderStatusCanceled, "0", "0", 1000)
+	e.OrigClientOrderID = "a"
+	if o, err := m.ApplyExecutionReport(e); err != nil || o.Status != StatusCanceled {
+		t.Fatalf("canceled order = %+v, %v", o, err)
+	}
+}
+
+func TestReject(t *testing.T) {
+	invalid := models.OrderRequest{Symbol: "BTCUSDT"}
+	tests := []struct {
+		name     string
+		err      error
+		rejected bool
+	}{
+		{"rejected by binance", &binance.APIError{HTTPStatus: http.StatusBadRequest, Code: binance.CodeNewOrderRejected, Msg: "insufficient balance"}, true},
+		{"not placed", binance.ErrOrderNotPlaced, true},
+		{"rejected by risk check", &risk.Rejection{Reason: risk.ReasonFatFinger, Symbol: "BTCUSDT"}, true},
+		{"filter failure", fmt.Errorf("%w: PRICE_FILTER: price is not a multiple of tick size", binance.ErrFilterFailure), true},
+		{"rate limit exceeded", fmt.Errorf("%w: ORDERS 10/10s", binance.ErrRateLimitExceeded), true},
+		{"invalid request", invalid.Validate(), true},
+		{"cancelled before send", &url.Error{Op: "Post", URL: "https://api.binance.com/api/v3/order", Err: context.Canceled}, true},
+		{"execution unknown", &binance.APIError{HTTPStatus: http.StatusServiceUnavailable, Code: binance.CodeUnknown}, false},
+		{"status unknown", binance.ErrOrderStatusUnknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager()
+			submitted(t, m, "a", models.SideBuy, "1")
+
+			o, rejected := m.Reject("a", tt.err)
+			if rejected != tt.rejected {
+				t.Fatalf("rejected = %v, want %v", rejected, tt.rejected)
+			}
+			want := StatusPendingNew
+			if tt.rejected {
+				want = StatusRejected
+			}
+			if o.Status != want {
+				t.Fatalf("status = %s, want %s", o.Status, want)
+			}
+		})
+	}
+}
+
+func TestAdoptDoesNotCountEarlierFills(t *testing.T) {
+	m := NewManager()
+	o, err := m.Adopt(Order{ClientOrderID: "a", OrderID: 1, Symbol: "btcusdt", Side: models.SideBuy, Quantity: d("2"),
+		Status: StatusPartiallyFilled, ExecutedQty: d("0.5"), CumulativeQuoteQty: d("50"), UpdateTime: 1000})
+	if err != nil || o.Symbol != "BTCUSDT" {
+		t.Fatalf("adopted order = %+v, %v", o, err)
+	}
+	if _, err := m.Adopt(o); !errors.Is(err, ErrDuplicateOrder) {
+		t.Fatalf("duplicate error = %v", err)
+	}
+
+	if _, err := m.ApplyExecutionReport(report("a", models.OrderStatusFilled, "2", "200", 2000)); err != nil {
+		t.Fatal(err)
+	}
+	if p := m.Position("BTCUSDT"); !p.BuyQty.Equal(d("1.5")) || !p.BuyQuote.Equal(d("150")) {
+		t.Fatalf("position = %+v, want 1.5 bought after the adoption", p)
+	}
+}
+
+func TestApplyCancelReplace(t *testing.T) {
+	m := NewManager()
+	submitted(t, m, "old", models.SideBuy, "2")
+	if _, err := m.ApplyNewOrder(&models.OrderResponseFull{ClientOrderId: "old", OrderId: 1, Status: models.OrderStatusNew, TransactTime: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	submitted(t, m, "new", models.SideBuy, "1.5")
+
+	r := &models.CancelReplaceResponse{CancelResult: models.CancelReplaceSuccess, NewOrderResult: models.CancelReplaceSuccess}
+	r.CancelResponse.OrigClientOrderId = "old"
+	r.CancelResponse.OrderId = 1
+	r.CancelResponse.Status = models.OrderStatusCanceled
+	r.CancelResponse.ExecutedQty = d("0.5")
+	r.CancelResponse.CummulativeQuoteQty = d("50")
+	r.NewOrderResponse.ClientOrderId = "new"
+	r.NewOrderResponse.OrderId = 2
+	r.NewOrderResponse.Status = models.OrderStatusNew
+	r.NewOrderResponse.TransactTime = 2000
+
+	if err := m.ApplyCancelReplace(r); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := m.Order("old"); o.Status != StatusCanceled || !o.ExecutedQty.Equal(d("0.5")) {
+		t.Fatalf("canceled order = %+v", o)
+	}
+	if o, _ := m.Order("new"); o.Status != StatusNew || o.OrderID != 2 {
+		t.Fatalf("new order = %+v", o)
+	}
+	if p := m.Position("BTCUSDT"); !p.BuyQty.Equal(d("0.5")) {
+		t.Fatalf("position = %+v, want 0.5 bought before the cancel", p)
+	}
+
+	// Failed new order is rejected
+	submitted(t, m, "failed", models.SideBuy, "1")
+	failed := &models.CancelReplaceResponse{CancelResult: models.CancelReplaceFailure, NewOrderResult: models.CancelReplaceFailure}
+	failed.NewOrderResponse.ClientOrderId = "failed"
+	failed.NewOrderResponse.Msg = "Order would immediately match and take."
+	if err := m.ApplyCancelReplace(failed); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := m.Order("failed"); o.Status != StatusRejected || o.RejectReason == "" {
+		t.Fatalf("failed order = %+v", o)
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	for _, status := range []Status{StatusFilled, StatusCanceled, StatusExpired, StatusExpiredInMatch, StatusRejected} {
+		if !status.IsTerminal() {
+			t.Errorf("%s is not terminal", status)
+		}
+	}
+	if StatusNew.CanTransition(StatusRejected) || StatusFilled.CanTransition(StatusNew) {
+		t.Error("illegal transition is allowed")
+	}
+	if !StatusPartiallyFilled.CanTransition(StatusPartiallyFilled) {
+		t.Error("partial fills of a partially filled order are not allowed")
+	}
+	if _, err := parseStatus(models.OrderStatusPendingCancel); err == nil {
+		t.Error("PENDING_CANCEL is tracked")
+	}
+}
@@ -0,0 +1,122 @@
+// Package oms keeps every submitted order keyed by its clientOrderId and drives its state from
+// REST responses and user data stream execution reports.
+//
+// An order is registered by Submit before it is sent, the response or the failure is applied after:
+//
+//	m := oms.NewManager()
+//	order, err := m.Submit(request)
+//	response, err := client.NewOrder(ctx, request)
+//	if err != nil {
+//		m.Reject(request.NewClientOrderID, err)
+//	} else {
+//		m.ApplyNewOrder(response)
+//	}
+//
+// Updates which would move an order backwards, e.g. FILLED to NEW, are rejected with
+// ErrIllegalTransition, updates older than the order state with ErrStaleUpdate.
+package oms
+
+import (
+	"fmt"
+	"gateaway/binance/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Status of an order
+type Status string
+
+const (
+	StatusPendingNew      Status = "PENDING_NEW" // submitted, not acknowledged by Binance yet
+	StatusNew             Status = "NEW"
+	StatusPartiallyFilled Status = "PARTIALLY_FILLED"
+	StatusFilled          Status = "FILLED"
+	StatusCanceled        Status = "CANCELED"
+	StatusExpired         Status = "EXPIRED"
+	StatusExpiredInMatch  Status = "EXPIRED_IN_MATCH" // expired by self-trade prevention
+	StatusRejected        Status = "REJECTED"
+)
+
+// transitions allowed from each status, terminal statuses have none
+var transitions = map[Status][]Status{
+	StatusPendingNew: {StatusNew, StatusPartiallyFilled, StatusFilled, StatusCanceled, StatusExpired,
+		StatusExpiredInMatch, StatusRejected},
+	StatusNew:             {StatusPartiallyFilled, StatusFilled, StatusCanceled, StatusExpired, StatusExpiredInMatch},
+	StatusPartiallyFilled: {StatusPartiallyFilled, StatusFilled, StatusCanceled, StatusExpired, StatusExpiredInMatch},
+}
+
+// parseStatus Binance order status, PENDING_CANCEL is not tracked
+func parseStatus(s models.OrderStatus) (Status, error) {
+	status := Status(s)
+	switch status {
+	case StatusPendingNew, StatusNew, StatusPartiallyFilled, StatusFilled, StatusCanceled,
+		StatusExpired, StatusExpiredInMatch, StatusRejected:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown order status %q", s)
+}
+
+// IsTerminal no more updates follow a terminal status
+func (s Status) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// CanTransition reports whether an order in status s may move to status to
+func (s Status) CanTransition(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Order state of a submitted order
+type Order struct {
+	ClientOrderID      string             `json:"clientOrderId"`
+	OrderID            int64              `json:"orderId"` // assigned by Binance, zero until acknowledged
+	Symbol             string             `json:"symbol"`
+	Side               models.Side        `json:"side"`
+	Type               models.OrderType   `json:"type"`
+	TimeInForce        models.TimeInForce `json:"timeInForce"`
+	Price              decimal.Decimal    `json:"price"`
+	Quantity           decimal.Decimal    `json:"quantity"`
+	Status             Status             `json:"status"`
+	ExecutedQty        decimal.Decimal    `json:"executedQty"`
+	CumulativeQuoteQty decimal.Decimal    `json:"cumulativeQuoteQty"`
+	RejectReason       string             `json:"rejectReason,omitempty"`
+	CreateTime         int64              `json:"createTime"` // milliseconds of Submit
+	UpdateTime         int64              `json:"updateTime"` // milliseconds of the last applied update, Binance time once acknowledged
+}
+
+// IsOpen the order may still be filled
+func (o Order) IsOpen() bool {
+	return !o.Status.IsTerminal()
+}
+
+// RemainingQty quantity not filled yet
+func (o Order) RemainingQty() decimal.Decimal {
+	return o.Quantity.Sub(o.ExecutedQty)
+}
+
+// AvgPrice average fill price, zero without fills
+func (o Order) AvgPrice() decimal.Decimal {
+	if !o.ExecutedQty.IsPositive() {
+		return decimal.Zero
+	}
+	return o.CumulativeQuoteQty.Div(o.ExecutedQty)
+}
+
+// Position filled quantities of a symbol over all tracked orders
+type Position struct {
+	Symbol    string          `json:"symbol"`
+	BuyQty    decimal.Decimal `json:"buyQty"`
+	SellQty   decimal.Decimal `json:"sellQty"`
+	BuyQuote  decimal.Decimal `json:"buyQuote"`  // quote asset spent on buys
+	SellQuote decimal.Decimal `json:"sellQuote"` // quote asset received for sells
+}
+
+// Net bought minus sold base asset quantity
+func (p Position) Net() decimal.Decimal {
+	return p.BuyQty.Sub(p.SellQty)
+}
@@ -0,0 +1,399 @@
+package oms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// allOrdersLimit orders of a symbol requested by each GetAllOrders call
+var allOrdersLimit = 1000
+
+const (
+	// DefaultReconcileInterval between reconciliations started by Start
+	DefaultReconcileInterval = time.Minute
+	// DefaultPendingGrace orders submitted less ago may not have reached Binance yet
+	DefaultPendingGrace = 10 * time.Second
+	// noOrderList orderListId of orders which are not part of an order list
+	noOrderList = -1
+)
+
+// EventType says how Binance and the local order state differ
+type EventType string
+
+const (
+	EventOrphaned   EventType = "ORPHANED"   // open on Binance, not placed through the manager
+	EventAdopted    EventType = "ADOPTED"    // open on Binance with a client order id of ClientOrderIDs, tracked from now on
+	EventMissing    EventType = "MISSING"    // tracked as open, Binance does not know the order
+	EventMismatched EventType = "MISMATCHED" // Binance has a different status or executed quantity
+)
+
+// Event difference found by a reconciliation
+type Event struct {
+	Type          EventType `json:"type"`
+	Symbol        string    `json:"symbol"`
+	ClientOrderID string    `json:"clientOrderId"`
+	OrderID       int64     `json:"orderId"`
+	OrderListID   int64     `json:"orderListId"`        // -1 unless the order is part of an OCO
+	Local         *Order    `json:"local,omitempty"`    // tracked state before the reconciliation, nil for orphans
+	Remote        *Order    `json:"remote,omitempty"`   // Binance state, nil for missing orders
+	Repaired      bool      `json:"repaired,omitempty"` // Binance state was applied to the tracked order
+	Canceled      bool      `json:"canceled,omitempty"` // orphan was canceled by CancelOrphans
+	Err           error     `json:"-"`                  // why the order was not repaired, adopted or canceled
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("%s %s order %s (%d)", e.Type, e.Symbol, e.ClientOrderID, e.OrderID)
+	if e.Local != nil && e.Remote != nil {
+		s += fmt.Sprintf(": local %s %s, binance %s %s", e.Local.Status, e.Local.ExecutedQty, e.Remote.Status, e.Remote.ExecutedQty)
+	}
+	if e.Canceled {
+		s += ", canceled"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// EventHandler is called with every difference found
+type EventHandler func(e Event)
+
+// Reconciler compares open orders and OCO lists on Binance with orders tracked by the manager.
+// Orders whose status changed while execution reports were missed are repaired from GetAllOrders.
+// Open orders with client order ids of ClientOrderIDs prefix were placed by an earlier run and are adopted,
+// other untracked orders are orphans.
+type Reconciler struct {
+	Client         *v3.BinanceClient
+	Orders         *Manager
+	ClientOrderIDs *binance.ClientOrderIDGenerator // ids of its prefix are adopted, nil adopts no order
+	Interval       time.Duration                   // between reconciliations started by Start
+	PendingGrace   time.Duration                   // PENDING_NEW orders younger than this are not reported missing
+	CancelOrphans  bool                            // cancel open orders and OCO lists which were not placed through the manager
+
+	mu      sync.Mutex // one reconciliation at a time
+	onEvent EventHandler
+}
+
+func NewReconciler(client *v3.BinanceClient, orders *Manager) *Reconciler {
+	return &Reconciler{
+		Client:         client,
+		Orders:         orders,
+		ClientOrderIDs: client.ClientOrderIDs,
+		Interval:       DefaultReconcileInterval,
+		PendingGrace:   DefaultPendingGrace,
+	}
+}
+
+// OnEvent sets the callback called with every difference found
+func (r *Reconciler) OnEvent(handler EventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onEvent = handler
+}
+
+// Start reconciles now and then every Interval in background.
+// Closing done channel or cancelling ctx stops reconciliation.
+func (r *Reconciler) Start(ctx context.Context) (chan<- struct{}, error) {
+	if _, err := r.Reconcile(ctx); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(r.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := r.Reconcile(ctx); err != nil {
+					log.Error().Msg(fmt.Sprintf("Failed to reconcile orders: %s", err))
+				}
+			}
+		}
+	}()
+
+	return done, nil
+}
+
+// Reconcile fetches open orders and OCO lists, compares them with tracked orders and returns the differences.
+// Tracked open orders which are not open on Binance anymore are looked up by GetAllOrders.
+func (r *Reconciler) Reconcile(ctx context.Context) ([]Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Local state is read first, orders submitted later are not open on Binance yet
+	local := r.Orders.OpenOrders("")
+
+	openOrders, err := r.Client.GetOpenOrders(ctx, models.OpenOrdersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("open orders: %w", err)
+	}
+	openLists, err := r.Client.QueryOCOList(ctx, models.QueryOpenOCORequest{})
+	if err != nil {
+		return nil, fmt.Errorf("open OCO lists: %w", err)
+	}
+
+	grace := time.Now().Add(-r.PendingGrace).UnixMilli()
+	var events []Event
+	open := make(map[string]bool, len(*openOrders))
+	for _, o := range *openOrders {
+		remote := remoteOrder(models.GetOrderResponse(o))
+		open[remote.ClientOrderID] = true
+
+		tracked, ok := r.Orders.Order(remote.ClientOrderID)
+		if !ok && r.owned(remote.ClientOrderID) {
+			events = append(events, r.adopt(remote, int64(o.OrderListId)))
+			continue
+		}
+		if !ok {
+			// Orphaned legs of OCO lists are reported with their list
+			if o.OrderListId == noOrderList {
+				events = append(events, r.orphan(ctx, remote))
+			}
+			continue
+		}
+		if e, ok := r.compare(tracked, remote, int64(o.OrderListId), grace); ok {
+			events = append(events, e)
+		}
+	}
+	events = append(events, r.orphanLists(ctx, *openLists)...)
+
+	// Tracked orders which are not open on Binance were filled, canceled or expired meanwhile
+	gone := make(map[string][]Order)
+	for _, o := range local {
+		if open[o.ClientOrderID] || (o.Status == StatusPendingNew && o.CreateTime > grace) {
+			continue
+		}
+		gone[o.Symbol] = append(gone[o.Symbol], o)
+	}
+	for symbol, orders := range gone {
+		found, err := r.history(ctx, symbol, orders)
+		if err != nil {
+			return events, fmt.Errorf("all orders of %s: %w", symbol, err)
+		}
+		for _, o := range orders {
+			remote, ok := found[o.ClientOrderID]
+			if !ok {
+				tracked := o
+				events = append(events, Event{Type: EventMissing, Symbol: o.Symbol, ClientOrderID: o.ClientOrderID,
+					OrderID: o.OrderID, OrderListID: noOrderList, Local: &tracked})
+				continue
+			}
+			if e, ok := r.compare(o, remote, noOrderList, grace); ok {
+				events = append(events, e)
+			}
+		}
+	}
+
+	if r.onEvent != nil {
+		for _, e := range events {
+			r.onEvent(e)
+		}
+	}
+	return events, nil
+}
+
+// compare repairs the tracked order when Binance state differs, an update older than the tracked state
+// means an execution report was applied meanwhile and is not a difference.
+// Orders submitted after grace may be acknowledged by Binance before their response is applied.
+func (r *Reconciler) compare(tracked Order, remote Order, listID int64, grace int64) (Event, bool) {
+	same := tracked.Status == remote.Status && tracked.ExecutedQty.Equal(remote.ExecutedQty)
+	inFlight := tracked.Status == StatusPendingNew && tracked.CreateTime > grace
+	if same || inFlight {
+		if tracked.OrderID == 0 {
+			r.Orders.apply(remoteUpdate(remote))
+		}
+		return Event{}, false
+	}
+
+	_, err := r.Orders.apply(remoteUpdate(remote))
+	if errors.Is(err, ErrStaleUpdate) {
+		return Event{}, false
+	}
+	return Event{
+		Type:          EventMismatched,
+		Symbol:        remote.Symbol,
+		ClientOrderID: remote.ClientOrderID,
+		OrderID:       remote.OrderID,
+		OrderListID:   listID,
+		Local:         &tracked,
+		Remote:        &remote,
+		Repaired:      err == nil,
+		Err:           err,
+	}, true
+}
+
+// owned the client order id was generated with the prefix of ClientOrderIDs
+func (r *Reconciler) owned(clientOrderID string) bool {
+	if r.ClientOrderIDs == nil {
+		return false
+	}
+	prefix, _, _, ok := binance.ParseClientOrderID(clientOrderID)
+	return ok && prefix == r.ClientOrderIDs.Prefix()
+}
+
+// adopt tracks the open order placed by an earlier run
+func (r *Reconciler) adopt(remote Order, listID int64) Event {
+	e := Event{Type: EventAdopted, Symbol: remote.Symbol, ClientOrderID: remote.ClientOrderID,
+		OrderID: remote.OrderID, OrderListID: listID, Remote: &remote}
+	_, e.Err = r.Orders.Adopt(remote)
+	return e
+}
+
+// orphan reports the open order, it is canceled with CancelOrphans
+func (r *Reconciler) orphan(ctx context.Context, remote Order) Event {
+	e := Event{Type: EventOrphaned, Symbol: remote.Symbol, ClientOrderID: remote.ClientOrderID,
+		OrderID: remote.OrderID, OrderListID: noOrderList, Remote: &remote}
+	if !r.CancelOrphans {
+		return e
+	}
+
+	_, e.Err = r.Client.CancelOrder(ctx, models.OrderCancelRequest{Symbol: remote.Symbol, OrderID: remote.OrderID})
+	e.Canceled = e.Err == nil
+	return e
+}
+
+// orphanLists reports open OCO lists without any tracked order, they are canceled with CancelOrphans
+func (r *Reconciler) orphanLists(ctx context.Context, lists []models.QueryOpenOCOResponse) []Event {
+	var events []Event
+	for _, l := range lists {
+		owned := false
+		for _, o := range l.Orders {
+			if _, ok := r.Orders.Order(o.ClientOrderId); ok {
+				owned = true
+				break
+			}
+		}
+		if owned {
+			continue
+		}
+
+		e := Event{Type: EventOrphaned, Symbol: l.Symbol, ClientOrderID: l.ListClientOrderId, OrderListID: int64(l.OrderListId)}
+		if r.CancelOrphans {
+			listID := l.OrderListId
+			_, e.Err = r.Client.CancelOCO(ctx, models.CancelOCORequest{Symbol: l.Symbol, OrderListID: &listID})
+			e.Canceled = e.Err == nil
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// history looks the orders up by GetAllOrders, from the oldest order id known or the oldest submit time.
+// Pages are requested until every order is found or Binance has no more orders.
+func (r *Reconciler) history(ctx context.Context, symbol string, orders []Order) (map[string]Order, error) {
+	limit := allOrdersLimit
+	req := models.AllOpenOrdersRequest{Symbol: symbol, Limit: &limit}
+
+	var fromID, fromTime int64
+	acknowledged := true
+	for _, o := range orders {
+		if o.OrderID == 0 {
+			acknowledged = false
+		} else if fromID == 0 || o.OrderID < fromID {
+			fromID = o.OrderID
+		}
+		if fromTime == 0 || o.CreateTime < fromTime {
+			fromTime = o.CreateTime
+		}
+	}
+	if acknowledged {
+		req.OrderID = &fromID
+	} else {
+		// Local clock may be ahead of Binance
+		startTime := fromTime - time.Minute.Milliseconds()
+		req.StartTime = &startTime
+	}
+
+	found := make(map[string]Order, len(orders))
+	for {
+		response, err := r.Client.GetAllOrders(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastID int64
+		for _, o := range *response {
+			found[o.ClientOrderId] = Order{
+				ClientOrderID:      o.ClientOrderId,
+				OrderID:            int64(o.OrderId),
+				Symbol:             o.Symbol,
+				Side:               o.Side,
+				Type:               o.Type,
+				TimeInForce:        o.TimeInForce,
+				Price:              o.Price,
+				Quantity:           o.OrigQty,
+				Status:             Status(o.Status),
+				ExecutedQty:        o.ExecutedQty,
+				CumulativeQuoteQty: o.CummulativeQuoteQty,
+				CreateTime:         o.Time,
+				UpdateTime:         o.UpdateTime,
+			}
+			if int64(o.OrderId) > lastID {
+				lastID = int64(o.OrderId)
+			}
+		}
+		if len(*response) < limit || foundAll(found, orders) {
+			return found, nil
+		}
+
+		// Next page starts after the last order, by order id also when the first page was by time
+		nextID := lastID + 1
+		req.OrderID = &nextID
+		req.StartTime = nil
+	}
+}
+
+// foundAll every order is in found
+func foundAll(found map[string]Order, orders []Order) bool {
+	for _, o := range orders {
+		if _, ok := found[o.ClientOrderID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// remoteOrder state of the order on Binance
+func remoteOrder(o models.GetOrderResponse) Order {
+	return Order{
+		ClientOrderID:      o.ClientOrderId,
+		OrderID:            int64(o.OrderId),
+		Symbol:             o.Symbol,
+		Side:               o.Side,
+		Type:               o.Type,
+		TimeInForce:        o.TimeInForce,
+		Price:              o.Price,
+		Quantity:           o.OrigQty,
+		Status:             Status(o.Status),
+		ExecutedQty:        o.ExecutedQty,
+		CumulativeQuoteQty: o.CummulativeQuoteQty,
+		CreateTime:         o.Time,
+		UpdateTime:         o.UpdateTime,
+	}
+}
+
+func remoteUpdate(o Order) update {
+	return update{
+		clientOrderID:      o.ClientOrderID,
+		orderID:            o.OrderID,
+		status:             models.OrderStatus(o.Status),
+		executedQty:        o.ExecutedQty,
+		cumulativeQuoteQty: o.CumulativeQuoteQty,
+		time:               o.UpdateTime,
+	}
+}
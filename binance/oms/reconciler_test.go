@@ -0,0 +1,247 @@
+package oms
+
+import (
+	"context"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"testing"
+)
+
+func newTestReconciler(t *testing.T) (*Reconciler, *binancetest.Server) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+	s.SetDepth("BTCUSDT", 1, [][2]string{{"99", "10"}}, [][2]string{{"101", "10"}})
+
+	client := v3.NewBinanceClient("key", "secret", v3.WithBaseURL(s.URL()), v3.WithRateLimiter(nil))
+	r := NewReconciler(client, NewManager())
+	r.PendingGrace = 0
+	return r, s
+}
+
+// place submits the order to the manager, sends it and applies the response
+func place(t *testing.T, r *Reconciler, clientOrderID string) Order {
+	t.Helper()
+	req := request(clientOrderID, models.SideBuy, "2")
+	if _, err := r.Orders.Submit(req); err != nil {
+		t.Fatal(err)
+	}
+	response, err := r.Client.NewOrder(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := r.Orders.ApplyNewOrder(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func reconcile(t *testing.T, r *Reconciler) []Event {
+	t.Helper()
+	events, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestReconcileInSync(t *testing.T) {
+	r, _ := newTestReconciler(t)
+	place(t, r, "a")
+
+	if events := reconcile(t, r); len(events) != 0 {
+		t.Fatalf("events = %v, want none", events)
+	}
+}
+
+func TestReconcileRepairsMissedFills(t *testing.T) {
+	r, s := newTestReconciler(t)
+	partial := place(t, r, "partial")
+	filled := place(t, r, "filled")
+
+	// Execution reports of the fills are missed
+	if err := s.Fill("BTCUSDT", partial.OrderID, d("0.5"), d("100")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Fill("BTCUSDT", filled.OrderID, d("2"), d("100")); err != nil {
+		t.Fatal(err)
+	}
+
+	var handled []Event
+	r.OnEvent(func(e Event) { handled = append(handled, e) })
+	events := reconcile(t, r)
+	if len(events) != 2 || len(handled) != 2 {
+		t.Fatalf("events = %v, want 2 mismatches", events)
+	}
+	for _, e := range events {
+		if e.Type != EventMismatched || !e.Repaired || e.Local.Status != StatusNew {
+			t.Fatalf("event = %v, want repaired mismatch of a NEW order", e)
+		}
+	}
+
+	if o, _ := r.Orders.Order("partial"); o.Status != StatusPartiallyFilled || !o.ExecutedQty.Equal(d("0.5")) {
+		t.Fatalf("partially filled order = %+v", o)
+	}
+	// The filled order is not open anymore and is found by GetAllOrders
+	if o, _ := r.Orders.Order("filled"); o.Status != StatusFilled {
+		t.Fatalf("filled order = %+v", o)
+	}
+	if p := r.Orders.Position("BTCUSDT"); !p.BuyQty.Equal(d("2.5")) {
+		t.Fatalf("position = %+v, want 2.5 bought", p)
+	}
+
+	if events := reconcile(t, r); len(events) != 0 {
+		t.Fatalf("events after repair = %v, want none", events)
+	}
+}
+
+func TestReconcileReportsMissingOrders(t *testing.T) {
+	r, _ := newTestReconciler(t)
+
+	// Submitted but never reached Binance
+	if _, err := r.Orders.Submit(request("lost", models.SideBuy, "1")); err != nil {
+		t.Fatal(err)
+	}
+
+	events := reconcile(t, r)
+	if len(events) != 1 || events[0].Type != EventMissing || events[0].ClientOrderID != "lost" {
+		t.Fatalf("events = %v, want the lost order missing", events)
+	}
+
+	// Orders within the grace period may still be in flight
+	r.PendingGrace = DefaultPendingGrace
+	if events := reconcile(t, r); len(events) != 0 {
+		t.Fatalf("events = %v, want none within the grace period", events)
+	}
+}
+
+func TestReconcileCancelsOrphans(t *testing.T) {
+	r, s := newTestReconciler(t)
+	ctx := context.Background()
+
+	orphan, err := r.Client.NewOrder(ctx, request("orphan", models.SideBuy, "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := reconcile(t, r)
+	if len(events) != 1 || events[0].Type != EventOrphaned || events[0].Canceled {
+		t.Fatalf("events = %v, want the orphan reported", events)
+	}
+
+	r.CancelOrphans = true
+	events = reconcile(t, r)
+	if len(events) != 1 || !events[0].Canceled {
+		t.Fatalf("events = %v, want the orphan canceled", events)
+	}
+	if o, _ := s.Order("BTCUSDT", orphan.OrderId); o.Status != string(models.OrderStatusCanceled) {
+		t.Fatalf("orphan status = %s, want CANCELED", o.Status)
+	}
+}
+
+func TestReconcileAdoptsOrdersOfEarlierRuns(t *testing.T) {
+	r, s := newTestReconciler(t)
+	ctx := context.Background()
+
+	ids, err := binance.NewClientOrderIDGenerator("gw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ClientOrderIDs = ids
+	r.CancelOrphans = true
+
+	// Placed before a restart, the generator of the earlier run had the same prefix
+	earlier, err := binance.NewClientOrderIDGenerator("gw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	own, err := r.Client.NewOrder(ctx, request(earlier.Next(), models.SideBuy, "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := r.Client.NewOrder(ctx, request("other-1-1", models.SideBuy, "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := reconcile(t, r)
+	if len(events) != 2 {
+		t.Fatalf("events = %v, want the own order adopted and the foreign one canceled", events)
+	}
+	for _, e := range events {
+		switch e.ClientOrderID {
+		case own.ClientOrderId:
+			if e.Type != EventAdopted || e.Canceled || e.Err != nil {
+				t.Fatalf("own order event = %v, want adopted", e)
+			}
+		case foreign.ClientOrderId:
+			if e.Type != EventOrphaned || !e.Canceled {
+				t.Fatalf("foreign order event = %v, want canceled orphan", e)
+			}
+		}
+	}
+	if o, _ := s.Order("BTCUSDT", own.OrderId); o.Status != string(models.OrderStatusNew) {
+		t.Fatalf("own order status = %s, want NEW", o.Status)
+	}
+
+	// Fills of the adopted order are repaired like those of any tracked order
+	if err := s.Fill("BTCUSDT", own.OrderId, d("0.5"), d("100")); err != nil {
+		t.Fatal(err)
+	}
+	events = reconcile(t, r)
+	if len(events) != 1 || events[0].Type != EventMismatched || !events[0].Repaired {
+		t.Fatalf("events = %v, want the fill of the adopted order repaired", events)
+	}
+	if o, _ := r.Orders.Order(own.ClientOrderId); o.Status != StatusPartiallyFilled || o.OrderID != own.OrderId {
+		t.Fatalf("adopted order = %+v", o)
+	}
+}
+
+func TestReconcilePagesOrderHistory(t *testing.T) {
+	r, s := newTestReconciler(t)
+	limit := allOrdersLimit
+	allOrdersLimit = 2
+	t.Cleanup(func() { allOrdersLimit = limit })
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		o := place(t, r, id)
+		if err := s.Fill("BTCUSDT", o.OrderID, d("2"), d("100")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Never acknowledged, looked up from its submit time
+	if _, err := r.Orders.Submit(request("lost", models.SideBuy, "1")); err != nil {
+		t.Fatal(err)
+	}
+
+	events := reconcile(t, r)
+	var filled, missing int
+	for _, e := range events {
+		switch {
+		case e.Type == EventMismatched && e.Repaired:
+			filled++
+		case e.Type == EventMissing && e.ClientOrderID == "lost":
+			missing++
+		}
+	}
+	if filled != 4 || missing != 1 || len(events) != 5 {
+		t.Fatalf("events = %v, want 4 repaired fills and the lost order missing", events)
+	}
+
+	// Pages of 2 orders until an empty page, the first from the submit time, the others after the last order id
+	var pages []binancetest.Request
+	for _, req := range s.Requests() {
+		if req.Path == "/api/v3/allOrders" {
+			pages = append(pages, req)
+		}
+	}
+	if len(pages) != 3 {
+		t.Fatalf("GetAllOrders requests = %d, want 3", len(pages))
+	}
+	if pages[0].Query["startTime"] == "" || pages[1].Query["orderId"] != "3" || pages[2].Query["orderId"] != "5" {
+		t.Fatalf("pages = %v", pages)
+	}
+}
@@ -0,0 +1,244 @@
+package orderbook
+
+import (
+	"gateaway/binance/models"
+	wsmodels "gateaway/binance/ws/models"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxBufferedEvents events kept while the book is not synced, the oldest are dropped first.
+// A snapshot newer than the dropped events can still be joined with the rest.
+const maxBufferedEvents = 1000
+
+// Level is a single price level of the book
+type Level struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+}
+
+// Book is a local order book of one symbol kept in sync with Binance diff depth stream
+type Book struct {
+	Symbol string
+
+	mu           sync.RWMutex
+	bids         []Level // sorted by price descending
+	asks         []Level // sorted by price ascending
+	lastUpdateID int64
+	synced       bool
+	resyncing    bool
+	buffer       []*wsmodels.DepthEvent // events received while the book is not synced
+}
+
+func newBook(symbol string) *Book {
+	return &Book{Symbol: symbol}
+}
+
+// BestBid returns the highest bid, false if there are no bids
+func (b *Book) BestBid() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.bids) == 0 {
+		return Level{}, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the lowest ask, false if there are no asks
+func (b *Book) BestAsk() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.asks) == 0 {
+		return Level{}, false
+	}
+	return b.asks[0], true
+}
+
+// Mid returns the price between the best bid and ask, false unless the book is synced with both sides
+func (b *Book) Mid() (decimal.Decimal, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.synced || len(b.bids) == 0 || len(b.asks) == 0 {
+		return decimal.Zero, false
+	}
+	return b.bids[0].Price.Add(b.asks[0].Price).Div(decimal.NewFromInt(2)), true
+}
+
+// Bids returns a copy of the top n bid levels, all levels if n <= 0
+func (b *Book) Bids(n int) []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.bids, n)
+}
+
+// Asks returns a copy of the top n ask levels, all levels if n <= 0
+func (b *Book) Asks(n int) []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.asks, n)
+}
+
+// LastUpdateID is the last update id applied to the book
+func (b *Book) LastUpdateID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastUpdateID
+}
+
+// Synced reports whether the book is consistent with the exchange
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// handle applies diff event to the book. It returns whether the book changed
+// and whether a gap was found so the book must be re-synced with a new snapshot.
+func (b *Book) handle(e *wsmodels.DepthEvent) (changed, gap bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.synced {
+		b.bufferEvent(e)
+		return false, false
+	}
+
+	// Event is older than the book
+	if e.LastUpdateID <= b.lastUpdateID {
+		return false, false
+	}
+
+	// Some events were missed
+	if e.FirstUpdateID > b.lastUpdateID+1 {
+		b.synced = false
+		b.buffer = []*wsmodels.DepthEvent{e}
+		return false, true
+	}
+
+	b.apply(e)
+	return true, false
+}
+
+// bufferEvent keeps the event until the next snapshot, the buffer does not grow while re-syncs keep failing
+func (b *Book) bufferEvent(e *wsmodels.DepthEvent) {
+	if len(b.buffer) >= maxBufferedEvents {
+		n := copy(b.buffer, b.buffer[len(b.buffer)-maxBufferedEvents+1:])
+		b.buffer = b.buffer[:n]
+	}
+	b.buffer = append(b.buffer, e)
+}
+
+// loadSnapshot replaces the book with REST snapshot and replays buffered events on top of it.
+// It returns false when the snapshot cannot be joined with buffered events and a newer one is needed,
+// the book is left as it was then.
+func (b *Book) loadSnapshot(snapshot *models.DepthResponse) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The join is checked before the levels are replaced
+	lastUpdateID := int64(snapshot.LastUpdateId)
+	for i, e := range b.buffer {
+		// Drop any event where u is <= lastUpdateId in the snapshot
+		if e.LastUpdateID <= lastUpdateID {
+			continue
+		}
+		// The first processed event should have U <= lastUpdateId+1 AND u >= lastUpdateId+1,
+		// each next event U should be equal to the previous event u+1
+		if e.FirstUpdateID > lastUpdateID+1 {
+			b.buffer = b.buffer[i:]
+			return false
+		}
+		lastUpdateID = e.LastUpdateID
+	}
+
+	b.bids = b.bids[:0]
+	b.asks = b.asks[:0]
+	for _, bid := range snapshot.Bids {
+		b.bids = update(b.bids, bid.Price, bid.Quantity, true)
+	}
+	for _, ask := range snapshot.Asks {
+		b.asks = update(b.asks, ask.Price, ask.Quantity, false)
+	}
+	b.lastUpdateID = int64(snapshot.LastUpdateId)
+
+	for _, e := range b.buffer {
+		if e.LastUpdateID > b.lastUpdateID {
+			b.apply(e)
+		}
+	}
+
+	b.buffer = nil
+	b.synced = true
+	b.resyncing = false
+	return true
+}
+
+// startResync marks the book as being re-synced, false if it is already in progress
+func (b *Book) startResync() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.resyncing {
+		return false
+	}
+	b.synced = false
+	b.resyncing = true
+	return true
+}
+
+// desync marks the book as inconsistent until it is re-synced
+func (b *Book) desync() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.synced = false
+}
+
+func (b *Book) apply(e *wsmodels.DepthEvent) {
+	for _, bid := range e.Bids {
+		b.bids = update(b.bids, bid.Price, bid.Quantity, true)
+	}
+	for _, ask := range e.Asks {
+		b.asks = update(b.asks, ask.Price, ask.Quantity, false)
+	}
+	b.lastUpdateID = e.LastUpdateID
+}
+
+// update sets the absolute quantity of the price level, zero quantity removes the level
+func update(levels []Level, price, quantity decimal.Decimal, desc bool) []Level {
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price.LessThanOrEqual(price)
+		}
+		return levels[i].Price.GreaterThanOrEqual(price)
+	})
+	found := i < len(levels) && levels[i].Price.Equal(price)
+
+	switch {
+	case quantity.IsZero() && found:
+		return append(levels[:i], levels[i+1:]...)
+	case quantity.IsZero():
+		return levels
+	case found:
+		levels[i].Quantity = quantity
+		return levels
+	}
+
+	levels = append(levels, Level{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = Level{Price: price, Quantity: quantity}
+	return levels
+}
+
+func top(levels []Level, n int) []Level {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	output := make([]Level, n)
+	copy(output, levels[:n])
+	return output
+}
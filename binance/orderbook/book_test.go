@@ -0,0 +1,195 @@
+package orderbook
+
+import (
+	"gateaway/binance/models"
+	wsmodels "gateaway/binance/ws/models"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func event(firstUpdateID, lastUpdateID int64, bids, asks [][2]string) *wsmodels.DepthEvent {
+	e := &wsmodels.DepthEvent{Symbol: "BTCUSDT", FirstUpdateID: firstUpdateID, LastUpdateID: lastUpdateID}
+	for _, bid := range bids {
+		e.Bids = append(e.Bids, wsmodels.OrderBook{Price: d(bid[0]), Quantity: d(bid[1])})
+	}
+	for _, ask := range asks {
+		e.Asks = append(e.Asks, wsmodels.OrderBook{Price: d(ask[0]), Quantity: d(ask[1])})
+	}
+	return e
+}
+
+func snapshot(lastUpdateID int, bids, asks [][2]string) *models.DepthResponse {
+	s := &models.DepthResponse{LastUpdateId: lastUpdateID}
+	for _, bid := range bids {
+		s.Bids = append(s.Bids, models.Order{Price: d(bid[0]), Quantity: d(bid[1])})
+	}
+	for _, ask := range asks {
+		s.Asks = append(s.Asks, models.Order{Price: d(ask[0]), Quantity: d(ask[1])})
+	}
+	return s
+}
+
+func syncedBook(t *testing.T) *Book {
+	t.Helper()
+	b := newBook("BTCUSDT")
+	if !b.loadSnapshot(snapshot(100, [][2]string{{"99", "1"}, {"98", "2"}}, [][2]string{{"101", "1"}, {"102", "2"}})) {
+		t.Fatal("snapshot without buffered events is not loaded")
+	}
+	return b
+}
+
+func TestBookReplaysBufferedEventsOnSnapshot(t *testing.T) {
+	b := newBook("BTCUSDT")
+
+	// Events received before the snapshot are buffered
+	for _, e := range []*wsmodels.DepthEvent{
+		event(95, 99, [][2]string{{"97", "5"}}, nil),
+		event(100, 102, [][2]string{{"99", "3"}}, nil),
+		event(103, 103, nil, [][2]string{{"101", "0"}}),
+	} {
+		if changed, gap := b.handle(e); changed || gap {
+			t.Fatalf("event before the snapshot: changed %v, gap %v", changed, gap)
+		}
+	}
+	if b.Synced() {
+		t.Fatal("book is synced before the snapshot")
+	}
+
+	if !b.loadSnapshot(snapshot(100, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}, {"102", "2"}})) {
+		t.Fatal("snapshot is not joined with buffered events")
+	}
+	if !b.Synced() || b.LastUpdateID() != 103 {
+		t.Fatalf("synced %v, last update %d, want 103", b.Synced(), b.LastUpdateID())
+	}
+	// u <= lastUpdateId of the snapshot is dropped, the rest is applied
+	if bids := b.Bids(0); len(bids) != 1 || !bids[0].Quantity.Equal(d("3")) {
+		t.Fatalf("bids = %v, want 99 x 3", bids)
+	}
+	if ask, _ := b.BestAsk(); !ask.Price.Equal(d("102")) {
+		t.Fatalf("best ask = %s, want 102", ask.Price)
+	}
+}
+
+func TestBookSnapshotOlderThanBufferedEventsIsRejected(t *testing.T) {
+	b := newBook("BTCUSDT")
+	b.handle(event(105, 110, nil, nil))
+
+	if b.loadSnapshot(snapshot(100, nil, nil)) {
+		t.Fatal("snapshot with a gap before the first buffered event is loaded")
+	}
+	if b.Synced() {
+		t.Fatal("book is synced after a rejected snapshot")
+	}
+
+	// A newer snapshot is joined with the events kept
+	b.handle(event(111, 112, [][2]string{{"99", "1"}}, nil))
+	if !b.loadSnapshot(snapshot(108, nil, nil)) {
+		t.Fatal("newer snapshot is not loaded")
+	}
+	if b.LastUpdateID() != 112 {
+		t.Fatalf("last update = %d, want 112", b.LastUpdateID())
+	}
+}
+
+func TestBookKeepsLevelsWhenSnapshotIsRejected(t *testing.T) {
+	b := syncedBook(t)
+	if _, gap := b.handle(event(105, 110, [][2]string{{"97", "1"}}, nil)); !gap {
+		t.Fatal("gap is not detected")
+	}
+
+	if b.loadSnapshot(snapshot(103, [][2]string{{"50", "1"}}, [][2]string{{"150", "1"}})) {
+		t.Fatal("snapshot with a gap before the buffered event is loaded")
+	}
+	if bids := b.Bids(0); len(bids) != 2 || !bids[0].Price.Equal(d("99")) {
+		t.Fatalf("bids = %v, want the levels before the snapshot", bids)
+	}
+	if asks := b.Asks(0); len(asks) != 2 || !asks[0].Price.Equal(d("101")) {
+		t.Fatalf("asks = %v, want the levels before the snapshot", asks)
+	}
+	if b.LastUpdateID() != 100 {
+		t.Fatalf("last update = %d, want 100", b.LastUpdateID())
+	}
+}
+
+func TestBookAppliesEventsInSequence(t *testing.T) {
+	b := syncedBook(t)
+
+	if changed, gap := b.handle(event(101, 101, [][2]string{{"99", "0"}, {"100", "1"}}, nil)); !changed || gap {
+		t.Fatalf("next event: changed %v, gap %v", changed, gap)
+	}
+	if bid, _ := b.BestBid(); !bid.Price.Equal(d("100")) {
+		t.Fatalf("best bid = %s, want 100", bid.Price)
+	}
+	if mid, ok := b.Mid(); !ok || !mid.Equal(d("100.5")) {
+		t.Fatalf("mid = %s, %v, want 100.5", mid, ok)
+	}
+
+	// Old events are ignored
+	if changed, gap := b.handle(event(95, 101, [][2]string{{"90", "1"}}, nil)); changed || gap {
+		t.Fatalf("old event: changed %v, gap %v", changed, gap)
+	}
+	if len(b.Bids(0)) != 2 {
+		t.Fatalf("bids = %v, old event applied", b.Bids(0))
+	}
+}
+
+func TestBookDetectsGap(t *testing.T) {
+	b := syncedBook(t)
+
+	if changed, gap := b.handle(event(102, 103, nil, nil)); changed || !gap {
+		t.Fatalf("event after a gap: changed %v, gap %v", changed, gap)
+	}
+	if b.Synced() {
+		t.Fatal("book is synced after a gap")
+	}
+	if _, ok := b.Mid(); ok {
+		t.Fatal("mid of a book which is not synced")
+	}
+
+	// The event after the gap is kept for the next snapshot
+	if !b.loadSnapshot(snapshot(102, nil, nil)) || b.LastUpdateID() != 103 {
+		t.Fatalf("last update = %d, want 103", b.LastUpdateID())
+	}
+}
+
+func TestBookBufferIsCapped(t *testing.T) {
+	b := newBook("BTCUSDT")
+	for i := int64(1); i <= maxBufferedEvents+10; i++ {
+		b.handle(event(i, i, nil, nil))
+	}
+
+	if len(b.buffer) != maxBufferedEvents {
+		t.Fatalf("buffered %d events, want %d", len(b.buffer), maxBufferedEvents)
+	}
+	if first := b.buffer[0].FirstUpdateID; first != 11 {
+		t.Fatalf("oldest buffered event = %d, want 11", first)
+	}
+}
+
+func TestUpdateKeepsLevelsSorted(t *testing.T) {
+	var bids []Level
+	for _, price := range []string{"100", "102", "101"} {
+		bids = update(bids, d(price), d("1"), true)
+	}
+	bids = update(bids, d("101"), d("2"), true)
+	bids = update(bids, d("103"), decimal.Zero, true)
+
+	want := []string{"102", "101", "100"}
+	if len(bids) != len(want) {
+		t.Fatalf("bids = %v", bids)
+	}
+	for i, price := range want {
+		if !bids[i].Price.Equal(d(price)) {
+			t.Fatalf("bids = %v, want prices %v", bids, want)
+		}
+	}
+	if !bids[1].Quantity.Equal(d("2")) {
+		t.Fatalf("quantity of 101 = %s, want 2", bids[1].Quantity)
+	}
+	if top := top(bids, 2); len(top) != 2 {
+		t.Fatalf("top 2 = %v", top)
+	}
+}
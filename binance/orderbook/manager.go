@@ -0,0 +1,238 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	wsmodels "gateaway/binance/ws/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+// ChangeHandler is called every time the book is changed
+type ChangeHandler func(b *Book)
+
+// subscription is the book with the context which keeps its stream and re-syncs alive
+type subscription struct {
+	book   *Book
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Manager keeps local order books in sync using REST depth snapshots and websocket diff depth stream
+type Manager struct {
+	SnapshotLimit int           // depth of REST snapshot
+	ResyncDelay   time.Duration // delay between failed snapshot attempts
+
+	rest   *v3.BinanceClient
+	stream *ws.BinanceWsClient
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	onChange      ChangeHandler
+}
+
+func NewManager(rest *v3.BinanceClient, stream *ws.BinanceWsClient) *Manager {
+	m := &Manager{
+		SnapshotLimit: 1000,
+		ResyncDelay:   time.Second,
+		rest:          rest,
+		stream:        stream,
+		subscriptions: make(map[string]*subscription),
+	}
+	stream.OnConnectionEvent(m.handleConnection)
+	return m
+}
+
+// OnChange sets the callback called after each change of any book
+func (m *Manager) OnChange(handler ChangeHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = handler
+}
+
+// Subscribe starts keeping the book of the symbol, the book is synced in background until ctx is cancelled.
+// The lock is not held while the stream is subscribed, the connection delivers events of other books meanwhile.
+func (m *Manager) Subscribe(ctx context.Context, symbol string) (*Book, error) {
+	symbol = strings.ToUpper(symbol)
+
+	m.mu.Lock()
+	if s, ok := m.subscriptions[symbol]; ok {
+		m.mu.Unlock()
+		return s.book, nil
+	}
+
+	b := newBook(symbol)
+	ctx, cancel := context.WithCancel(ctx)
+	s := &subscription{book: b, ctx: ctx, cancel: cancel}
+	// Reserved so that concurrent subscribers of the symbol share the book
+	m.subscriptions[symbol] = s
+	m.mu.Unlock()
+
+	handler := func(e *wsmodels.DepthEvent) {
+		m.handle(b, e)
+	}
+
+	// Stream is opened before the snapshot is requested so that no diffs are missed
+	err, _ := m.stream.SubscribeDepth(ctx, symbol, handler)
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("%s book unsubscribed: %w", symbol, ctx.Err())
+	}
+	if err != nil {
+		cancel()
+		m.mu.Lock()
+		if m.subscriptions[symbol] == s {
+			delete(m.subscriptions, symbol)
+		}
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	if b.startResync() {
+		go m.resync(s)
+	}
+
+	return b, nil
+}
+
+// Unsubscribe stops the depth stream of the symbol and forgets its book
+func (m *Manager) Unsubscribe(symbol string) error {
+	symbol = strings.ToUpper(symbol)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.subscriptions[symbol]
+	if !ok {
+		return fmt.Errorf("symbol %s is not subscribed", symbol)
+	}
+
+	s.cancel()
+	delete(m.subscriptions, symbol)
+	return nil
+}
+
+// Book returns the book of the symbol, nil if it is not subscribed
+func (m *Manager) Book(symbol string) *Book {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.subscriptions[strings.ToUpper(symbol)]; ok {
+		return s.book
+	}
+	return nil
+}
+
+// MidPrice returns the mid price of the subscribed symbol, false if its book is not synced.
+// It can be used as risk.PriceFunc reference of the price band.
+func (m *Manager) MidPrice(symbol string) (decimal.Decimal, bool) {
+	b := m.Book(symbol)
+	if b == nil {
+		return decimal.Zero, false
+	}
+	return b.Mid()
+}
+
+// Close stops all subscriptions
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for symbol, s := range m.subscriptions {
+		s.cancel()
+		delete(m.subscriptions, symbol)
+	}
+}
+
+func (m *Manager) handle(b *Book, e *wsmodels.DepthEvent) {
+	changed, gap := b.handle(e)
+
+	if gap {
+		log.Warn().Msg(fmt.Sprintf("Gap in %s depth stream, re-syncing the book", b.Symbol))
+		if s := m.subscription(b.Symbol); s != nil && b.startResync() {
+			go m.resync(s)
+		}
+	}
+
+	if changed {
+		m.notify(b)
+	}
+}
+
+// handleConnection re-syncs books whose diffs could be missed while the stream was disconnected
+func (m *Manager) handleConnection(e ws.ConnectionEvent) {
+	for _, stream := range e.Streams {
+		m.mu.Lock()
+		var s *subscription
+		for symbol, sub := range m.subscriptions {
+			if ws.DepthStream(symbol) == stream {
+				s = sub
+				break
+			}
+		}
+		m.mu.Unlock()
+
+		if s == nil {
+			continue
+		}
+
+		switch e.Type {
+		case ws.Disconnected:
+			s.book.desync()
+		case ws.Reconnected:
+			log.Warn().Msg(fmt.Sprintf("%s depth stream reconnected, re-syncing the book", s.book.Symbol))
+			if s.book.startResync() {
+				go m.resync(s)
+			}
+		}
+	}
+}
+
+// resync requests snapshots until one of them can be joined with the buffered diffs
+func (m *Manager) resync(s *subscription) {
+	b := s.book
+
+	for {
+		snapshot, err := m.rest.GetDepth(s.ctx, models.DepthRequest{
+			Symbol: b.Symbol,
+			Limit:  m.SnapshotLimit,
+		})
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			log.Error().Msg(fmt.Sprintf("Failed to get %s depth snapshot: %s", b.Symbol, err))
+		} else if b.loadSnapshot(snapshot) {
+			m.notify(b)
+			return
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(m.ResyncDelay):
+		}
+	}
+}
+
+func (m *Manager) subscription(symbol string) *subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.subscriptions[symbol]
+}
+
+func (m *Manager) notify(b *Book) {
+	m.mu.Lock()
+	handler := m.onChange
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(b)
+	}
+}
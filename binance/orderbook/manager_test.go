@@ -0,0 +1,81 @@
+package orderbook
+
+import (
+	"context"
+	"gateaway/binance/binancetest"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) (*Manager, *binancetest.Server) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+
+	rest := v3.NewBinanceClient("key", "secret", v3.WithBaseURL(s.URL()), v3.WithRateLimiter(nil))
+	stream := ws.NewBinanceWsClient("key", "secret", ws.WithBaseURL(s.WsURL()))
+	m := NewManager(rest, stream)
+	m.ResyncDelay = 10 * time.Millisecond
+	t.Cleanup(m.Close)
+	return m, s
+}
+
+// eventually polls the condition until it holds or a second passes
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestManagerResyncsAfterGap(t *testing.T) {
+	m, s := newTestManager(t)
+	s.SetDepth("BTCUSDT", 100, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})
+
+	b, err := m.Subscribe(context.Background(), "btcusdt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "book is not synced with the snapshot", b.Synced)
+
+	s.UpdateDepth("BTCUSDT", [][2]string{{"100", "2"}}, nil)
+	eventually(t, "diff is not applied", func() bool { return b.LastUpdateID() == 101 })
+	if mid, _ := m.MidPrice("BTCUSDT"); !mid.Equal(d("100.5")) {
+		t.Fatalf("mid = %s, want 100.5", mid)
+	}
+
+	// Updates 102-104 are lost, the server book moves on
+	s.SetDepth("BTCUSDT", 105, [][2]string{{"98", "1"}}, [][2]string{{"103", "1"}})
+	s.PushDepth("BTCUSDT", 105, 105, nil, nil)
+
+	eventually(t, "book is not re-synced after the gap", func() bool {
+		return b.Synced() && b.LastUpdateID() == 105
+	})
+	if bid, _ := b.BestBid(); !bid.Price.Equal(d("98")) {
+		t.Fatalf("best bid = %s, want 98 of the new snapshot", bid.Price)
+	}
+}
+
+func TestManagerResyncsAfterReconnect(t *testing.T) {
+	m, s := newTestManager(t)
+	s.SetDepth("BTCUSDT", 100, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})
+
+	b, err := m.Subscribe(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "book is not synced with the snapshot", b.Synced)
+
+	s.SetDepth("BTCUSDT", 200, [][2]string{{"97", "1"}}, [][2]string{{"101", "1"}})
+	s.DropConnections()
+
+	eventually(t, "book is not re-synced after reconnect", func() bool {
+		return b.Synced() && b.LastUpdateID() == 200
+	})
+}
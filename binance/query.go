@@ -0,0 +1,57 @@
+package binance
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+	"github.com/shopspring/decimal"
+)
+
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+// QueryValues encodes request params, decimals are written in plain notation which go-querystring does not support
+func QueryValues(params interface{}) (url.Values, error) {
+	q, err := query.Values(params)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return q, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return q, nil
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		name, opts, _ := strings.Cut(v.Type().Field(i).Tag.Get("url"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() || field.Type().Elem() != decimalType {
+				continue
+			}
+			field = field.Elem()
+		} else if field.Type() != decimalType {
+			continue
+		}
+
+		d := field.Interface().(decimal.Decimal)
+		if d.IsZero() && v.Field(i).Kind() != reflect.Ptr && strings.Contains(opts, "omitempty") {
+			q.Del(name)
+			continue
+		}
+		q.Set(name, d.String())
+	}
+
+	return q, nil
+}
@@ -0,0 +1,248 @@
+package risk
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultRateWindow of MaxOrders
+const DefaultRateWindow = time.Second
+
+// Limits of a single order, zero disables the limit
+type Limits struct {
+	MaxQuantity decimal.Decimal // fat finger limit of the base asset quantity
+	MaxNotional decimal.Decimal // quote asset value
+	PriceBand   decimal.Decimal // relative distance of the limit price from the reference price, e.g. 0.05 is 5%
+}
+
+// Engine runs the built-in checks configured by its fields and the custom Checks.
+// Fields are set before the engine is used, Halt and Resume may be called any time.
+type Engine struct {
+	Limits       Limits                     // of symbols without SymbolLimits
+	SymbolLimits map[string]Limits          // by symbol, e.g. BTCUSDT
+	MaxPositions map[string]decimal.Decimal // absolute net position by base asset, e.g. BTC, after the order is filled
+	MaxOrders    int                        // orders accepted per RateWindow, zero disables the cap
+	RateWindow   time.Duration
+
+	Prices    PriceSource                        // reference of the price band and price of market orders
+	Positions PositionSource                     // current positions checked against MaxPositions
+	Working   WorkingSource                      // open orders counted as filled by MaxPositions, nil counts positions only
+	BaseAsset func(symbol string) (string, bool) // base asset of the symbol, e.g. SymbolRegistry.BaseAsset of the client
+	Checks    []Checker                          // custom checks, run after the built-in ones
+
+	mu       sync.Mutex
+	halted   bool
+	haltedBy string
+	accepted []time.Time // times of orders accepted within the rate window, oldest first
+}
+
+func NewEngine() *Engine {
+	return &Engine{
+		RateWindow: DefaultRateWindow,
+	}
+}
+
+// Halt is the kill switch, all orders are rejected until Resume
+func (e *Engine) Halt(reason string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.halted = true
+	e.haltedBy = reason
+}
+
+// Resume accepts orders again after Halt
+func (e *Engine) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.halted = false
+	e.haltedBy = ""
+}
+
+// Halted reports whether the kill switch is on and why
+func (e *Engine) Halted() (bool, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.halted, e.haltedBy
+}
+
+// Check runs all checks, the order rate is counted only for orders passing the others
+func (e *Engine) Check(o Order) error {
+	if halted, reason := e.Halted(); halted {
+		return reject(ReasonKillSwitch, o, "trading is halted: %s", reason)
+	}
+
+	limits, ok := e.SymbolLimits[o.Symbol]
+	if !ok {
+		limits = e.Limits
+	}
+	ref, hasRef := e.price(o.Symbol)
+
+	if err := e.checkQuantity(o, limits, ref, hasRef); err != nil {
+		return err
+	}
+	if err := e.checkNotional(o, limits, ref, hasRef); err != nil {
+		return err
+	}
+	if err := e.checkPriceBand(o, limits, ref, hasRef); err != nil {
+		return err
+	}
+	if err := e.checkPosition(o, ref, hasRef); err != nil {
+		return err
+	}
+
+	for _, check := range e.Checks {
+		if err := check.Check(o); err != nil {
+			if _, ok := AsRejection(err); ok {
+				return err
+			}
+			return reject(ReasonCustom, o, "%s", err)
+		}
+	}
+
+	return e.checkRate(o)
+}
+
+func (e *Engine) price(symbol string) (decimal.Decimal, bool) {
+	if e.Prices == nil {
+		return decimal.Zero, false
+	}
+	price, ok := e.Prices.Price(symbol)
+	return price, ok && price.IsPositive()
+}
+
+// quantity of the base asset, market orders by quoteOrderQty are estimated at the reference price
+func quantity(o Order, ref decimal.Decimal, hasRef bool) (decimal.Decimal, bool) {
+	if o.Quantity.IsPositive() {
+		return o.Quantity, true
+	}
+	if o.QuoteOrderQty.IsPositive() && hasRef {
+		return o.QuoteOrderQty.Div(ref), true
+	}
+	return decimal.Zero, false
+}
+
+func (e *Engine) checkQuantity(o Order, limits Limits, ref decimal.Decimal, hasRef bool) error {
+	if !limits.MaxQuantity.IsPositive() {
+		return nil
+	}
+	qty, ok := quantity(o, ref, hasRef)
+	if !ok {
+		return reject(ReasonNoPrice, o, "quantity of quoteOrderQty %s is not known without a reference price", o.QuoteOrderQty)
+	}
+	if qty.GreaterThan(limits.MaxQuantity) {
+		return reject(ReasonFatFinger, o, "quantity %s is above %s", qty, limits.MaxQuantity)
+	}
+	return nil
+}
+
+// checkNotional values the order at its highest price, market orders at the reference price
+func (e *Engine) checkNotional(o Order, limits Limits, ref decimal.Decimal, hasRef bool) error {
+	if !limits.MaxNotional.IsPositive() {
+		return nil
+	}
+
+	notional := o.QuoteOrderQty
+	if !notional.IsPositive() {
+		price := decimal.Max(o.Price, o.StopPrice)
+		if !price.IsPositive() {
+			if !hasRef {
+				return reject(ReasonNoPrice, o, "notional of %s order is not known without a reference price", o.Type)
+			}
+			price = ref
+		}
+		notional = o.Quantity.Mul(price)
+	}
+
+	if notional.GreaterThan(limits.MaxNotional) {
+		return reject(ReasonMaxNotional, o, "notional %s is above %s", notional, limits.MaxNotional)
+	}
+	return nil
+}
+
+// checkPriceBand limit price must be within the band around the reference price
+func (e *Engine) checkPriceBand(o Order, limits Limits, ref decimal.Decimal, hasRef bool) error {
+	if !limits.PriceBand.IsPositive() || !o.Price.IsPositive() {
+		return nil
+	}
+	if !hasRef {
+		return reject(ReasonNoPrice, o, "price band requires a reference price")
+	}
+
+	deviation := o.Price.Sub(ref).Abs().Div(ref)
+	if deviation.GreaterThan(limits.PriceBand) {
+		return reject(ReasonPriceBand, o, "price %s is %s%% from reference %s, band is %s%%",
+			o.Price, deviation.Mul(decimal.NewFromInt(100)).StringFixed(2), ref, limits.PriceBand.Mul(decimal.NewFromInt(100)))
+	}
+	return nil
+}
+
+// checkPosition checks the position if the order and the open orders of its side are filled.
+// Orders reducing the position are accepted even above the limit.
+func (e *Engine) checkPosition(o Order, ref decimal.Decimal, hasRef bool) error {
+	if len(e.MaxPositions) == 0 {
+		return nil
+	}
+	if e.BaseAsset == nil {
+		return reject(ReasonMaxPosition, o, "base asset of %s is not known", o.Symbol)
+	}
+	asset, ok := e.BaseAsset(o.Symbol)
+	if !ok {
+		return reject(ReasonMaxPosition, o, "base asset of %s is not known", o.Symbol)
+	}
+	limit, ok := e.MaxPositions[strings.ToUpper(asset)]
+	if !ok {
+		return nil
+	}
+
+	qty, ok := quantity(o, ref, hasRef)
+	if !ok {
+		return reject(ReasonNoPrice, o, "quantity of quoteOrderQty %s is not known without a reference price", o.QuoteOrderQty)
+	}
+	if !o.IsBuy() {
+		qty = qty.Neg()
+	}
+
+	current := decimal.Zero
+	if e.Positions != nil {
+		current = e.Positions.Position(asset)
+	}
+	if e.Working != nil {
+		buy, sell := e.Working.Working(asset, o.ClientOrderID)
+		if o.IsBuy() {
+			current = current.Add(buy)
+		} else {
+			current = current.Sub(sell)
+		}
+	}
+	after := current.Add(qty)
+	if after.Abs().GreaterThan(limit) && after.Abs().GreaterThan(current.Abs()) {
+		return reject(ReasonMaxPosition, o, "%s position would be %s with open orders, limit is %s", asset, after, limit)
+	}
+	return nil
+}
+
+// checkRate counts the order when it is accepted
+func (e *Engine) checkRate(o Order) error {
+	if e.MaxOrders <= 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	expired := 0
+	for expired < len(e.accepted) && now.Sub(e.accepted[expired]) >= e.RateWindow {
+		expired++
+	}
+	e.accepted = e.accepted[expired:]
+
+	if len(e.accepted) >= e.MaxOrders {
+		return reject(ReasonOrderRate, o, "%d orders in %s", len(e.accepted), e.RateWindow)
+	}
+	e.accepted = append(e.accepted, now)
+	return nil
+}
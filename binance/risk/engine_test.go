@@ -0,0 +1,252 @@
+package risk
+
+import (
+	"errors"
+	"gateaway/binance/models"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func buy(price, quantity string) Order {
+	o := Order{Source: "NewOrder", Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderTypeLimit, Quantity: d(quantity)}
+	if price != "" {
+		o.Price = d(price)
+	}
+	return o
+}
+
+func sell(price, quantity string) Order {
+	o := buy(price, quantity)
+	o.Side = models.SideSell
+	return o
+}
+
+func prices(price string) PriceFunc {
+	return func(symbol string) (decimal.Decimal, bool) {
+		if price == "" {
+			return decimal.Zero, false
+		}
+		return d(price), true
+	}
+}
+
+// expect checks the error is nil when reason is empty, a rejection of the reason otherwise
+func expect(t *testing.T, err error, reason Reason) {
+	t.Helper()
+	if reason == "" {
+		if err != nil {
+			t.Fatalf("unexpected rejection: %v", err)
+		}
+		return
+	}
+	rejection, ok := AsRejection(err)
+	if !ok || rejection.Reason != reason {
+		t.Fatalf("error = %v, want %s rejection", err, reason)
+	}
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf("%v does not match ErrRejected", err)
+	}
+}
+
+func TestKillSwitch(t *testing.T) {
+	e := NewEngine()
+
+	e.Halt("manual")
+	if halted, reason := e.Halted(); !halted || reason != "manual" {
+		t.Fatalf("Halted = %v, %q", halted, reason)
+	}
+	expect(t, e.Check(buy("100", "1")), ReasonKillSwitch)
+
+	e.Resume()
+	expect(t, e.Check(buy("100", "1")), "")
+}
+
+func TestMaxQuantity(t *testing.T) {
+	e := NewEngine()
+	e.Limits.MaxQuantity = d("2")
+	e.SymbolLimits = map[string]Limits{"ETHUSDT": {MaxQuantity: d("20")}}
+
+	expect(t, e.Check(buy("100", "2")), "")
+	expect(t, e.Check(buy("100", "2.1")), ReasonFatFinger)
+
+	eth := buy("100", "10")
+	eth.Symbol = "ETHUSDT"
+	expect(t, e.Check(eth), "")
+
+	// Quantity of quoteOrderQty needs the reference price
+	market := Order{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderTypeMarket, QuoteOrderQty: d("300")}
+	expect(t, e.Check(market), ReasonNoPrice)
+	e.Prices = prices("100")
+	expect(t, e.Check(market), ReasonFatFinger)
+}
+
+func TestMaxNotional(t *testing.T) {
+	e := NewEngine()
+	e.Limits.MaxNotional = d("1000")
+
+	expect(t, e.Check(buy("100", "10")), "")
+	expect(t, e.Check(buy("100", "10.01")), ReasonMaxNotional)
+
+	// OCO is valued at the higher price of its legs
+	oco := sell("90", "10")
+	oco.StopPrice = d("101")
+	expect(t, e.Check(oco), ReasonMaxNotional)
+
+	market := buy("", "10")
+	market.Type = models.OrderTypeMarket
+	expect(t, e.Check(market), ReasonNoPrice)
+	e.Prices = prices("99")
+	expect(t, e.Check(market), "")
+}
+
+func TestPriceBand(t *testing.T) {
+	e := NewEngine()
+	e.Limits.PriceBand = d("0.05")
+
+	expect(t, e.Check(buy("100", "1")), ReasonNoPrice)
+
+	e.Prices = prices("100")
+	expect(t, e.Check(buy("105", "1")), "")
+	expect(t, e.Check(sell("94.9", "1")), ReasonPriceBand)
+	expect(t, e.Check(buy("", "1")), "")
+
+	// A zero price is not a reference
+	e.Prices = prices("0")
+	expect(t, e.Check(buy("100", "1")), ReasonNoPrice)
+}
+
+func TestMaxPosition(t *testing.T) {
+	e := NewEngine()
+	e.MaxPositions = map[string]decimal.Decimal{"BTC": d("5")}
+
+	expect(t, e.Check(buy("100", "1")), ReasonMaxPosition)
+
+	e.BaseAsset = func(symbol string) (string, bool) {
+		if symbol == "BTCUSDT" {
+			return "BTC", true
+		}
+		return "", false
+	}
+	position := d("4")
+	e.Positions = PositionFunc(func(asset string) decimal.Decimal { return position })
+
+	expect(t, e.Check(buy("100", "1")), "")
+	expect(t, e.Check(buy("100", "1.5")), ReasonMaxPosition)
+	expect(t, e.Check(sell("100", "9")), "")
+	expect(t, e.Check(sell("100", "9.1")), ReasonMaxPosition)
+
+	// Orders reducing the position are accepted above the limit
+	position = d("7")
+	expect(t, e.Check(sell("100", "1")), "")
+
+	other := buy("100", "100")
+	other.Symbol = "XRPUSDT"
+	expect(t, e.Check(other), ReasonMaxPosition)
+}
+
+func TestMaxPositionCountsWorkingOrders(t *testing.T) {
+	e := NewEngine()
+	e.MaxPositions = map[string]decimal.Decimal{"BTC": d("5")}
+	e.BaseAsset = func(string) (string, bool) { return "BTC", true }
+	e.Positions = PositionFunc(func(string) decimal.Decimal { return d("2") })
+
+	var excluded string
+	e.Working = WorkingFunc(func(asset, clientOrderID string) (buy, sell decimal.Decimal) {
+		excluded = clientOrderID
+		return d("2.5"), d("6")
+	})
+
+	o := buy("100", "1")
+	o.ClientOrderID = "order-1"
+	expect(t, e.Check(o), ReasonMaxPosition)
+	if excluded != "order-1" {
+		t.Fatalf("working orders exclude %q, want the order being checked", excluded)
+	}
+	expect(t, e.Check(buy("100", "0.5")), "")
+
+	// Working sells count only against sells
+	expect(t, e.Check(sell("100", "1")), "")
+	expect(t, e.Check(sell("100", "1.1")), ReasonMaxPosition)
+}
+
+func TestOrderRate(t *testing.T) {
+	e := NewEngine()
+	e.MaxOrders = 2
+	e.RateWindow = 50 * time.Millisecond
+	e.Limits.MaxQuantity = d("1")
+
+	expect(t, e.Check(buy("100", "1")), "")
+	// Rejected orders are not counted
+	expect(t, e.Check(buy("100", "2")), ReasonFatFinger)
+	expect(t, e.Check(buy("100", "1")), "")
+	expect(t, e.Check(buy("100", "1")), ReasonOrderRate)
+
+	time.Sleep(e.RateWindow)
+	expect(t, e.Check(buy("100", "1")), "")
+}
+
+func TestCustomChecks(t *testing.T) {
+	e := NewEngine()
+	e.Checks = []Checker{
+		CheckFunc(func(o Order) error {
+			if o.Type == models.OrderTypeMarket {
+				return errors.New("market orders are not allowed")
+			}
+			return nil
+		}),
+		CheckFunc(func(o Order) error {
+			if o.Symbol == "ETHUSDT" {
+				return &Rejection{Reason: ReasonKillSwitch, Symbol: o.Symbol, Msg: "ETH is halted"}
+			}
+			return nil
+		}),
+	}
+
+	expect(t, e.Check(buy("100", "1")), "")
+
+	market := buy("", "1")
+	market.Type = models.OrderTypeMarket
+	expect(t, e.Check(market), ReasonCustom)
+
+	// Rejections of custom checks keep their reason
+	eth := buy("100", "1")
+	eth.Symbol = "ETHUSDT"
+	expect(t, e.Check(eth), ReasonKillSwitch)
+}
+
+func TestOrdersOfRequests(t *testing.T) {
+	stopLimitPrice := d("89")
+	limitClientOrderID := "limit-1"
+	o := NewOCO(models.NewOCORequest{
+		Symbol:             "btcusdt",
+		Side:               models.SideSell,
+		Quantity:           d("1"),
+		Price:              d("110"),
+		StopPrice:          d("90"),
+		StopLimitPrice:     &stopLimitPrice,
+		LimitClientOrderId: &limitClientOrderID,
+	})
+	if o.Symbol != "BTCUSDT" || o.Type != models.OrderTypeLimitMaker || !o.StopPrice.Equal(stopLimitPrice) || o.ClientOrderID != "limit-1" {
+		t.Fatalf("OCO order = %+v", o)
+	}
+
+	n := NewOrder(models.OrderRequest{Symbol: "ethusdt", Side: models.SideBuy, NewClientOrderID: "new-1"})
+	if n.Symbol != "ETHUSDT" || n.ClientOrderID != "new-1" || !n.IsBuy() {
+		t.Fatalf("NewOrder order = %+v", n)
+	}
+}
+
+func TestLastPrices(t *testing.T) {
+	p := NewLastPrices()
+	if _, ok := p.Price("BTCUSDT"); ok {
+		t.Fatal("price known before the first trade")
+	}
+	p.Set("btcusdt", d("100"))
+	if price, ok := p.Price("BTCUSDT"); !ok || !price.Equal(d("100")) {
+		t.Fatalf("Price = %s, %v, want 100", price, ok)
+	}
+}
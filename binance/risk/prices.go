@@ -0,0 +1,72 @@
+package risk
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceSource reference price of a symbol, e.g. its last trade or the mid of its book
+type PriceSource interface {
+	Price(symbol string) (decimal.Decimal, bool)
+}
+
+// PriceFunc adapts a function to PriceSource, e.g. orderbook.Manager.MidPrice
+type PriceFunc func(symbol string) (decimal.Decimal, bool)
+
+func (f PriceFunc) Price(symbol string) (decimal.Decimal, bool) {
+	return f(symbol)
+}
+
+// LastPrices keeps the last trade price of every symbol, feed it from trade streams:
+//
+//	wsClient.SubscribeTrade(ctx, "btcusdt", func(e *models.TradeEvent) { prices.Set(e.Symbol, e.Price) })
+type LastPrices struct {
+	mu     sync.RWMutex
+	prices map[string]decimal.Decimal
+}
+
+func NewLastPrices() *LastPrices {
+	return &LastPrices{prices: make(map[string]decimal.Decimal)}
+}
+
+// Set stores the price of the last trade of the symbol
+func (p *LastPrices) Set(symbol string, price decimal.Decimal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prices[strings.ToUpper(symbol)] = price
+}
+
+// Price returns the last trade price, false before the first trade
+func (p *LastPrices) Price(symbol string) (decimal.Decimal, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	price, ok := p.prices[strings.ToUpper(symbol)]
+	return price, ok
+}
+
+// PositionSource net position of an asset, negative when more was sold than bought
+type PositionSource interface {
+	Position(asset string) decimal.Decimal
+}
+
+// PositionFunc adapts a function to PositionSource
+type PositionFunc func(asset string) decimal.Decimal
+
+func (f PositionFunc) Position(asset string) decimal.Decimal {
+	return f(asset)
+}
+
+// WorkingSource quantity of the asset on open orders which is not filled yet, by side.
+// The order being checked may already be tracked as open, the source leaves out the order of clientOrderID.
+type WorkingSource interface {
+	Working(asset, clientOrderID string) (buy, sell decimal.Decimal)
+}
+
+// WorkingFunc adapts a function to WorkingSource
+type WorkingFunc func(asset, clientOrderID string) (buy, sell decimal.Decimal)
+
+func (f WorkingFunc) Working(asset, clientOrderID string) (buy, sell decimal.Decimal) {
+	return f(asset, clientOrderID)
+}
@@ -0,0 +1,161 @@
+// Package risk checks orders before they are sent to the matching engine.
+//
+// The v3 and wsapi clients pass every order of NewOrder, CancelReplace, NewOCO and NewSOR through
+// the Checker set by WithRisk, a rejected order is not sent and the error is a *Rejection:
+//
+//	engine := risk.NewEngine()
+//	engine.Limits = risk.Limits{MaxNotional: decimal.NewFromInt(10000), PriceBand: decimal.RequireFromString("0.05")}
+//	engine.Prices = risk.PriceFunc(books.MidPrice)
+//	client := v3.NewBinanceClient(apiKey, secretKey, v3.WithRisk(engine))
+//
+//	_, err := client.NewOrder(ctx, request)
+//	if rejection, ok := risk.AsRejection(err); ok && rejection.Reason == risk.ReasonPriceBand { ... }
+//
+// Engine.Halt is a kill switch rejecting all orders until Resume.
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"gateaway/binance/models"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reason why an order was rejected
+type Reason string
+
+const (
+	ReasonKillSwitch  Reason = "KILL_SWITCH"  // trading is halted
+	ReasonFatFinger   Reason = "FAT_FINGER"   // quantity above the limit
+	ReasonMaxNotional Reason = "MAX_NOTIONAL" // quote value above the limit
+	ReasonPriceBand   Reason = "PRICE_BAND"   // price too far from the reference price
+	ReasonMaxPosition Reason = "MAX_POSITION" // position of the base asset would exceed the limit
+	ReasonOrderRate   Reason = "ORDER_RATE"   // too many orders in the rate window
+	ReasonNoPrice     Reason = "NO_PRICE"     // reference price required by a check is not known
+	ReasonCustom      Reason = "CUSTOM"       // rejected by a check outside of this package
+)
+
+// ErrRejected every Rejection matches it with errors.Is
+var ErrRejected = errors.New("order rejected by risk check")
+
+// Rejection is returned for orders which did not pass a check
+type Rejection struct {
+	Reason Reason
+	Symbol string
+	Msg    string
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("risk check %s rejected %s order: %s", r.Reason, r.Symbol, r.Msg)
+}
+
+func (r *Rejection) Is(target error) bool {
+	return target == ErrRejected
+}
+
+func reject(reason Reason, o Order, format string, args ...interface{}) *Rejection {
+	return &Rejection{Reason: reason, Symbol: o.Symbol, Msg: fmt.Sprintf(format, args...)}
+}
+
+// AsRejection unwraps Rejection from the error chain
+func AsRejection(err error) (*Rejection, bool) {
+	var rejection *Rejection
+	if errors.As(err, &rejection) {
+		return rejection, true
+	}
+	return nil, false
+}
+
+// Checker decides whether the order may be sent, it returns *Rejection otherwise
+type Checker interface {
+	Check(o Order) error
+}
+
+// CheckFunc adapts a function to Checker
+type CheckFunc func(o Order) error
+
+func (f CheckFunc) Check(o Order) error {
+	return f(o)
+}
+
+// Order is what the checks see of every order request
+type Order struct {
+	Source        string // client method placing the order, e.g. NewOrder
+	ClientOrderID string // newClientOrderId, empty when Binance generates it
+	Symbol        string
+	Side          models.Side
+	Type          models.OrderType // LIMIT_MAKER of OCO, its limit leg
+	Price         decimal.Decimal  // limit price, zero for market orders
+	StopPrice     decimal.Decimal  // OCO stop leg, its limit price when it has one
+	Quantity      decimal.Decimal  // zero for market orders by quoteOrderQty
+	QuoteOrderQty decimal.Decimal
+}
+
+// IsBuy reports whether the order buys the base asset
+func (o Order) IsBuy() bool {
+	return o.Side == models.SideBuy
+}
+
+// NewOrder order of NewOrder request
+func NewOrder(r models.OrderRequest) Order {
+	return Order{
+		Source:        "NewOrder",
+		ClientOrderID: r.NewClientOrderID,
+		Symbol:        strings.ToUpper(r.Symbol),
+		Side:          r.Side,
+		Type:          r.Type,
+		Price:         r.Price,
+		Quantity:      r.Quantity,
+		QuoteOrderQty: r.QuoteOrderQty,
+	}
+}
+
+// CancelReplace new order of CancelReplace request, the canceled order is not taken into account
+func CancelReplace(r models.CancelReplaceRequest) Order {
+	return Order{
+		Source:        "CancelReplace",
+		ClientOrderID: r.NewClientOrderId,
+		Symbol:        strings.ToUpper(r.Symbol),
+		Side:          r.Side,
+		Type:          r.Type,
+		Price:         r.Price,
+		Quantity:      r.Quantity,
+		QuoteOrderQty: r.QuoteOrderQty,
+	}
+}
+
+// NewOCO both legs of NewOCO request, only one of them can be filled
+func NewOCO(r models.NewOCORequest) Order {
+	stop := r.StopPrice
+	if r.StopLimitPrice != nil {
+		stop = *r.StopLimitPrice
+	}
+	o := Order{
+		Source:    "NewOCO",
+		Symbol:    strings.ToUpper(r.Symbol),
+		Side:      r.Side,
+		Type:      models.OrderTypeLimitMaker,
+		Price:     r.Price,
+		StopPrice: stop,
+		Quantity:  r.Quantity,
+	}
+	if r.LimitClientOrderId != nil {
+		o.ClientOrderID = *r.LimitClientOrderId
+	}
+	return o
+}
+
+// NewSOR order of NewSOR request
+func NewSOR(r models.NewSORRequest) Order {
+	return Order{
+		Source:        "NewSOR",
+		ClientOrderID: r.NewClientOrderId,
+		Symbol:        strings.ToUpper(r.Symbol),
+		Side:          r.Side,
+		Type:          r.Type,
+		Price:         r.Price,
+		Quantity:      r.Quantity,
+	}
+}
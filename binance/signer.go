@@ -0,0 +1,137 @@
+package binance
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Key types of Binance API keys
+// https://www.binance.com/en/support/faq/how-to-generate-an-ed25519-key-pair-to-send-api-requests-on-binance-6b9a63f1e3384cf48a2eedb82767a69a
+const (
+	KeyTypeHMAC    = "HMAC"
+	KeyTypeRSA     = "RSA"
+	KeyTypeEd25519 = "ED25519"
+)
+
+// Signer signs the payload of signed requests, the result is sent as signature parameter
+type Signer interface {
+	Sign(payload string) (string, error)
+}
+
+// HMACSigner HMAC-SHA256 with the secret key, signature is hex encoded
+type HMACSigner struct {
+	Secret string
+}
+
+func NewHMACSigner(secret string) *HMACSigner {
+	return &HMACSigner{Secret: secret}
+}
+
+func (s *HMACSigner) Sign(payload string) (string, error) {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(payload))
+	return fmt.Sprintf("%x", mac.Sum(nil)), nil
+}
+
+// RSASigner RSASSA-PKCS1-v1_5 with SHA-256, signature is base64 encoded
+type RSASigner struct {
+	key *rsa.PrivateKey
+}
+
+// NewRSASigner parses PKCS#8 PEM private key, PKCS#1 "RSA PRIVATE KEY" is accepted as well
+func NewRSASigner(privateKeyPEM []byte) (*RSASigner, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("RSA private key is not PEM encoded")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &RSASigner{key: key}, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is %T, not RSA", parsed)
+	}
+	return &RSASigner{key: key}, nil
+}
+
+func (s *RSASigner) Sign(payload string) (string, error) {
+	hash := sha256.Sum256([]byte(payload))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Ed25519Signer Ed25519 signature, base64 encoded. Required by session.logon of WebSocket API.
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer parses PKCS#8 PEM private key
+func NewEd25519Signer(privateKeyPEM []byte) (*Ed25519Signer, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("Ed25519 private key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is %T, not Ed25519", parsed)
+	}
+	return &Ed25519Signer{key: key}, nil
+}
+
+func (s *Ed25519Signer) Sign(payload string) (string, error) {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, []byte(payload))), nil
+}
+
+// NewSigner creates a signer of the key type, HMAC uses the secret and RSA and Ed25519 read PEM private key from the file
+func NewSigner(keyType, secret, privateKeyPath string) (Signer, error) {
+	switch strings.ToUpper(keyType) {
+	case "", KeyTypeHMAC:
+		if secret == "" {
+			return nil, errors.New("secret key is required by HMAC signature")
+		}
+		return NewHMACSigner(secret), nil
+	case KeyTypeRSA, KeyTypeEd25519:
+		if privateKeyPath == "" {
+			return nil, fmt.Errorf("private key file is required by %s signature", keyType)
+		}
+		data, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(keyType, KeyTypeRSA) {
+			return NewRSASigner(data)
+		}
+		return NewEd25519Signer(data)
+	default:
+		return nil, fmt.Errorf("unknown key type %s", keyType)
+	}
+}
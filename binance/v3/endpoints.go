@@ -2,9 +2,21 @@ package v3
 
 const (
 	// Market Data
-	exchangeInfo = "/api/v3/exchangeInfo"
-	depth        = "/api/v3/depth"
-	trades       = "/api/v3/trades"
+	ping             = "/api/v3/ping"
+	serverTime       = "/api/v3/time"
+	exchangeInfo     = "/api/v3/exchangeInfo"
+	depth            = "/api/v3/depth"
+	trades           = "/api/v3/trades"
+	historicalTrades = "/api/v3/historicalTrades"
+	aggTrades        = "/api/v3/aggTrades"
+	klines           = "/api/v3/klines"
+	uiKlines         = "/api/v3/uiKlines"
+	avgPrice         = "/api/v3/avgPrice"
+	ticker24hr       = "/api/v3/ticker/24hr"
+	tickerTradingDay = "/api/v3/ticker/tradingDay"
+	tickerPrice      = "/api/v3/ticker/price"
+	tickerBookTicker = "/api/v3/ticker/bookTicker"
+	ticker           = "/api/v3/ticker"
 
 	// Account
 	testOrder     = "/api/v3/order/test"
@@ -18,4 +30,14 @@ const (
 	openOrderList = "/api/v3/openOrderList"
 	newSOR        = "/api/v3/sor/order"
 	testNewSOR    = "/api/v3/sor/order/test"
+
+	// Account information
+	account            = "/api/v3/account"
+	myTrades           = "/api/v3/myTrades"
+	rateLimitOrder     = "/api/v3/rateLimit/order"
+	myPreventedMatches = "/api/v3/myPreventedMatches"
+	myAllocations      = "/api/v3/myAllocations"
+
+	// User Data Stream
+	userDataStream = "/api/v3/userDataStream"
 )
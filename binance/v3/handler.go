@@ -1,9 +1,12 @@
 package v3
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"gateaway/binance"
 	"gateaway/binance/models"
+	"gateaway/binance/risk"
 
 	//"github.com/charmbracelet/log"
 	"github.com/rs/zerolog/log"
@@ -11,52 +14,99 @@ import (
 	"io"
 	"net/http"
 	urlib "net/url"
+	"sync/atomic"
+	"time"
+)
 
-	"github.com/google/go-querystring/query"
+const (
+	// listenKeyKeepAlive Binance recommends to send a ping about every 30 minutes
+	listenKeyKeepAlive = 30 * time.Minute
+	// shutdownTimeout limits requests made after the caller context is cancelled
+	shutdownTimeout = 5 * time.Second
+	// defaultRecvWindow Binance default, the request is rejected if it arrives later
+	defaultRecvWindow = 5000
 )
 
 type BinanceClient struct {
-	APIKey  string
-	Secret  string
-	BaseURL string
-	client  http.Client
-}
-
-func NewBinanceClient(apiKey, secretKey string) *BinanceClient {
-	return &BinanceClient{
-		APIKey:  apiKey,
-		Secret:  secretKey,
-		BaseURL: "https://api.binance.com",
-		client:  http.Client{},
-	}
-}
-
-func (c *BinanceClient) executeRequest(method, endpoint string, body io.Reader, target interface{}, sign bool, params interface{}) error {
+	APIKey           string
+	Secret           string
+	Signer           binance.Signer // signs signed requests, HMAC with Secret by default
+	BaseURL          string
+	RecvWindow       int64                           // milliseconds, sent with signed requests which do not set it
+	RateLimiter      *RateLimiter                    // nil disables client side rate limiting
+	ClientOrderIDs   *binance.ClientOrderIDGenerator // generates newClientOrderId of orders without it, nil leaves it to Binance
+	OrderLookups     int                             // attempts to find an order whose request failed with unknown execution status
+	OrderLookupDelay time.Duration                   // before each lookup, the order may still be in flight
+	Risk             risk.Checker                    // checks every order before it is sent, nil sends orders unchecked
+	Rules            *models.SymbolRegistry          // symbol rules orders are checked against, loaded by GetExchangeInfo
+	client           http.Client
+	timeOffset       atomic.Int64 // server time minus local time in milliseconds
+	timeSyncing      atomic.Bool
+}
+
+// NewBinanceClient creates a client of production REST API unless options select another environment
+func NewBinanceClient(apiKey, secretKey string, opts ...Option) *BinanceClient {
+	c := &BinanceClient{
+		APIKey:           apiKey,
+		Secret:           secretKey,
+		Signer:           binance.NewHMACSigner(secretKey),
+		BaseURL:          binance.Production.RESTURL,
+		RecvWindow:       defaultRecvWindow,
+		RateLimiter:      NewRateLimiter(),
+		OrderLookups:     defaultOrderLookups,
+		OrderLookupDelay: defaultOrderLookupDelay,
+		Rules:            models.NewSymbolRegistry(),
+		client:           http.Client{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *BinanceClient) executeRequest(ctx context.Context, method, endpoint string, body io.Reader, target interface{}, security securityType, params interface{}) error {
 	// Parse the base URL
 	u, err := urlib.Parse(endpoint)
 	if err != nil {
 		return err
 	}
 
-	q, err := query.Values(params)
+	q, err := binance.QueryValues(params)
 	if err != nil {
 		return err
 	}
 
+	// Wait for the rate limit before the timestamp is taken so that it is not outdated
+	if c.RateLimiter != nil {
+		weight, orderCount := endpointWeight(method, c.endpointPath(u), params)
+		if err := c.RateLimiter.wait(ctx, weight, orderCount); err != nil {
+			return err
+		}
+	}
+
+	if security == securitySigned {
+		c.setTimestamp(q)
+	}
+
 	u.RawQuery = q.Encode()
 
-	if sign {
-		u.RawQuery = fmt.Sprintf("%s&signature=%s", u.RawQuery, signature(u.RawQuery, c.Secret))
+	if security == securitySigned {
+		sig, err := c.Signer.Sign(u.RawQuery)
+		if err != nil {
+			return err
+		}
+		// RSA and Ed25519 signatures are base64 and have to be escaped
+		u.RawQuery = fmt.Sprintf("%s&signature=%s", u.RawQuery, urlib.QueryEscape(sig))
 	}
 
-	req, err := http.NewRequest(method, u.String(), body)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
 		return err
 	}
 
 	log.Info().Msg(fmt.Sprintf("Requested %s %s", method, u.String()))
 
-	if sign {
+	if security != securityNone {
 		req.Header.Add("X-MBX-APIKEY", c.APIKey)
 	}
 
@@ -66,13 +116,21 @@ func (c *BinanceClient) executeRequest(method, endpoint string, body io.Reader,
 	}
 	defer response.Body.Close()
 
+	if c.RateLimiter != nil {
+		c.RateLimiter.update(response.StatusCode, response.Header)
+	}
+
 	data, err := io.ReadAll(response.Body)
 	if err != nil {
 		return err
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP request failed with status code: %d\n%s", response.StatusCode, data)
+		err = newAPIError(response, data)
+		if binance.IsTimestampOutsideRecvWindow(err) {
+			go c.resyncTime()
+		}
+		return err
 	}
 
 	return json.Unmarshal(data, target)
@@ -80,11 +138,41 @@ func (c *BinanceClient) executeRequest(method, endpoint string, body io.Reader,
 
 // ––––––––––– MARKET DATA –––––––––––
 
-func (c *BinanceClient) getExchangeInfo(url string) (*models.ExchangeInfo, error) {
-	response := &models.ExchangeInfo{}
+// Ping Test connectivity to the REST API
+func (c *BinanceClient) Ping(ctx context.Context) error {
+	url := c.buildURL(ping)
 	var params interface{} // no params needed
-	err := c.executeRequest(http.MethodGet, url, nil, response, false, params)
+	return c.executeRequest(ctx, http.MethodGet, url, nil, &struct{}{}, securityNone, params)
+}
+
+func (c *BinanceClient) getExchangeInfo(ctx context.Context, url string, params models.ExchangeInfoRequest) (*models.ExchangeInfo, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
 
+	response := &models.ExchangeInfo{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.RateLimiter != nil {
+		c.RateLimiter.SetLimits(response.RateLimits)
+	}
+	if c.Rules != nil {
+		c.Rules.Load(response)
+	}
+
+	return response, nil
+}
+
+// GetServerTime Current server time, used to calibrate timestamps of signed requests
+func (c *BinanceClient) GetServerTime(ctx context.Context) (*models.ServerTimeResponse, error) {
+	url := c.buildURL(serverTime)
+	response := &models.ServerTimeResponse{}
+	var params interface{} // no params needed
+	err := c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
 	if err != nil {
 		return nil, err
 	}
@@ -92,20 +180,37 @@ func (c *BinanceClient) getExchangeInfo(url string) (*models.ExchangeInfo, error
 	return response, nil
 }
 
-// GetExchangeInfo Current exchange trading rules and symbol information
-func (c *BinanceClient) GetExchangeInfo() (*models.ExchangeInfo, error) {
+// GetExchangeInfo Current exchange trading rules and symbol information, an empty request returns all symbols
+func (c *BinanceClient) GetExchangeInfo(ctx context.Context, r models.ExchangeInfoRequest) (*models.ExchangeInfo, error) {
 	url := c.buildURL(exchangeInfo)
-	return c.getExchangeInfo(url)
+	return c.getExchangeInfo(ctx, url, r)
+}
+
+// GetSymbolInfo trading rules of the symbol, exchange info is requested only if the rules are not cached yet
+func (c *BinanceClient) GetSymbolInfo(ctx context.Context, symbol string) (*models.SymbolInfo, error) {
+	if info, ok := c.Rules.Get(symbol); ok {
+		return info, nil
+	}
+
+	if _, err := c.GetExchangeInfo(ctx, models.ExchangeInfoRequest{}); err != nil {
+		return nil, err
+	}
+
+	info, ok := c.Rules.Get(symbol)
+	if !ok {
+		return nil, fmt.Errorf("symbol %s is not found", symbol)
+	}
+	return info, nil
 }
 
-func (c *BinanceClient) getDepth(url string, params models.DepthRequest) (*models.DepthResponse, error) {
+func (c *BinanceClient) getDepth(ctx context.Context, url string, params models.DepthRequest) (*models.DepthResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.DepthResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, false, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
 	if err != nil {
 		return nil, err
 	}
@@ -113,19 +218,19 @@ func (c *BinanceClient) getDepth(url string, params models.DepthRequest) (*model
 	return response, nil
 }
 
-func (c *BinanceClient) GetDepth(r models.DepthRequest) (*models.DepthResponse, error) {
+func (c *BinanceClient) GetDepth(ctx context.Context, r models.DepthRequest) (*models.DepthResponse, error) {
 	url := c.buildURL(depth)
-	return c.getDepth(url, r)
+	return c.getDepth(ctx, url, r)
 }
 
-func (c *BinanceClient) getTrades(url string, params models.TradesRequest) (*[]models.TradesResponse, error) {
+func (c *BinanceClient) getTrades(ctx context.Context, url string, params models.TradesRequest) (*[]models.TradesResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &[]models.TradesResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, false, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
 	if err != nil {
 		return nil, err
 	}
@@ -133,9 +238,207 @@ func (c *BinanceClient) getTrades(url string, params models.TradesRequest) (*[]m
 	return response, nil
 }
 
-func (c *BinanceClient) GetTrades(r models.TradesRequest) (*[]models.TradesResponse, error) {
+func (c *BinanceClient) GetTrades(ctx context.Context, r models.TradesRequest) (*[]models.TradesResponse, error) {
 	url := c.buildURL(trades)
-	return c.getTrades(url, r)
+	return c.getTrades(ctx, url, r)
+}
+
+func (c *BinanceClient) getHistoricalTrades(ctx context.Context, url string, params models.HistoricalTradesRequest) (*[]models.TradesResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.TradesResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityAPIKey, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetHistoricalTrades Older trades of the symbol, requires API key but no signature
+func (c *BinanceClient) GetHistoricalTrades(ctx context.Context, r models.HistoricalTradesRequest) (*[]models.TradesResponse, error) {
+	url := c.buildURL(historicalTrades)
+	return c.getHistoricalTrades(ctx, url, r)
+}
+
+func (c *BinanceClient) getAggTrades(ctx context.Context, url string, params models.AggTradesRequest) (*[]models.AggTradesResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.AggTradesResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetAggTrades Trades filled at the same time, from the same order and at the same price are aggregated
+func (c *BinanceClient) GetAggTrades(ctx context.Context, r models.AggTradesRequest) (*[]models.AggTradesResponse, error) {
+	url := c.buildURL(aggTrades)
+	return c.getAggTrades(ctx, url, r)
+}
+
+func (c *BinanceClient) getKlines(ctx context.Context, url string, params models.KlinesRequest) (*[]models.Kline, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.Kline{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetKlines Candlesticks of the symbol identified by open time
+func (c *BinanceClient) GetKlines(ctx context.Context, r models.KlinesRequest) (*[]models.Kline, error) {
+	url := c.buildURL(klines)
+	return c.getKlines(ctx, url, r)
+}
+
+// GetUIKlines Candlesticks modified for presentation of candlestick charts
+func (c *BinanceClient) GetUIKlines(ctx context.Context, r models.KlinesRequest) (*[]models.Kline, error) {
+	url := c.buildURL(uiKlines)
+	return c.getKlines(ctx, url, r)
+}
+
+func (c *BinanceClient) getAvgPrice(ctx context.Context, url string, params models.AvgPriceRequest) (*models.AvgPriceResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AvgPriceResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+	if c.Rules != nil {
+		c.Rules.SetAvgPrice(params.Symbol, response.Price)
+	}
+
+	return response, nil
+}
+
+// GetAvgPrice Current average price of the symbol
+func (c *BinanceClient) GetAvgPrice(ctx context.Context, r models.AvgPriceRequest) (*models.AvgPriceResponse, error) {
+	url := c.buildURL(avgPrice)
+	return c.getAvgPrice(ctx, url, r)
+}
+
+func (c *BinanceClient) getTicker24hr(ctx context.Context, url string, params models.Ticker24hrRequest) (*models.Ticker24hrList, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.Ticker24hrList{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetTicker24hr 24 hour rolling window price change statistics, a single symbol is returned as a list of one
+func (c *BinanceClient) GetTicker24hr(ctx context.Context, r models.Ticker24hrRequest) (*models.Ticker24hrList, error) {
+	url := c.buildURL(ticker24hr)
+	return c.getTicker24hr(ctx, url, r)
+}
+
+func (c *BinanceClient) getTradingDayTicker(ctx context.Context, url string, params models.TradingDayTickerRequest) (*models.TickerList, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.TickerList{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetTradingDayTicker Price change statistics within the trading day
+func (c *BinanceClient) GetTradingDayTicker(ctx context.Context, r models.TradingDayTickerRequest) (*models.TickerList, error) {
+	url := c.buildURL(tickerTradingDay)
+	return c.getTradingDayTicker(ctx, url, r)
+}
+
+func (c *BinanceClient) getRollingTicker(ctx context.Context, url string, params models.RollingTickerRequest) (*models.TickerList, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.TickerList{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetRollingTicker Price change statistics within the requested window
+func (c *BinanceClient) GetRollingTicker(ctx context.Context, r models.RollingTickerRequest) (*models.TickerList, error) {
+	url := c.buildURL(ticker)
+	return c.getRollingTicker(ctx, url, r)
+}
+
+func (c *BinanceClient) getTickerPrice(ctx context.Context, url string, params models.TickerRequest) (*models.PriceTickerList, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PriceTickerList{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetTickerPrice Latest price of symbols
+func (c *BinanceClient) GetTickerPrice(ctx context.Context, r models.TickerRequest) (*models.PriceTickerList, error) {
+	url := c.buildURL(tickerPrice)
+	return c.getTickerPrice(ctx, url, r)
+}
+
+func (c *BinanceClient) getBookTicker(ctx context.Context, url string, params models.TickerRequest) (*models.BookTickerList, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.BookTickerList{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securityNone, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetBookTicker Best price and quantity on the order book of symbols
+func (c *BinanceClient) GetBookTicker(ctx context.Context, r models.TickerRequest) (*models.BookTickerList, error) {
+	url := c.buildURL(tickerBookTicker)
+	return c.getBookTicker(ctx, url, r)
 }
 
 // ––––––––––– SPOT TRADING –––––––––––
@@ -143,27 +446,55 @@ func (c *BinanceClient) GetTrades(r models.TradesRequest) (*[]models.TradesRespo
 // NewOrderTest
 // Test new order creation and signature/recvWindow long.
 // Creates and validates a new order but does not send it into the matching engine.
-func (c *BinanceClient) NewOrderTest(r models.OrderRequest) (*models.OrderResponseFull, error) {
+func (c *BinanceClient) NewOrderTest(ctx context.Context, r models.OrderRequest) (*models.OrderResponseFull, error) {
 	url := c.buildURL(testOrder)
-	return c.newOrder(url, r)
+	return c.newOrder(ctx, url, r)
 }
 
-func (c *BinanceClient) NewOrder(r models.OrderRequest) (*models.OrderResponseFull, error) {
+// NewOrder places the order, ClientOrderIDs generates newClientOrderId when the request has none.
+// A request failing with unknown execution status, e.g. a timeout, is not sent again: the order is looked up
+// by its client order id and returned if Binance has it, the error wraps binance.ErrOrderNotPlaced if not.
+func (c *BinanceClient) NewOrder(ctx context.Context, r models.OrderRequest) (*models.OrderResponseFull, error) {
+	if r.NewClientOrderID == "" && c.ClientOrderIDs != nil {
+		r.NewClientOrderID = c.ClientOrderIDs.Next()
+	}
+
+	// Invalid orders are refused before the risk check, they do not count against its limits
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if err := r.CheckRules(c.Rules); err != nil {
+		return nil, err
+	}
+	if err := c.checkRisk(risk.NewOrder(r)); err != nil {
+		return nil, err
+	}
+
 	url := c.buildURL(order)
-	return c.newOrder(url, r)
+	response, err := c.newOrder(ctx, url, r)
+	if err != nil && r.NewClientOrderID != "" && binance.IsExecutionUnknown(err) {
+		response, err = c.resolveOrder(ctx, r.Symbol, r.NewClientOrderID, err)
+	}
+	if err == nil && response.Status.IsOpen() && c.Rules != nil {
+		c.Rules.AddOpenOrders(r.Symbol, 1)
+	}
+	return response, err
 }
 
-func (c *BinanceClient) newOrder(url string, params models.OrderRequest) (*models.OrderResponseFull, error) {
+func (c *BinanceClient) newOrder(ctx context.Context, url string, params models.OrderRequest) (*models.OrderResponseFull, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
+	if err := params.CheckRules(c.Rules); err != nil {
+		return nil, err
+	}
 
 	// When making the API call, you can specify which response type you want by setting
 	// the newOrderRespType parameter to either ACK, RESULT, or FULL.
 	// If you don't specify a type, the default is RESULT.
 	response := &models.OrderResponseFull{}
-	err = c.executeRequest(http.MethodPost, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodPost, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -171,19 +502,23 @@ func (c *BinanceClient) newOrder(url string, params models.OrderRequest) (*model
 	return response, nil
 }
 
-func (c *BinanceClient) CancelOrder(r models.OrderCancelRequest) (*models.OrderCancelResponse, error) {
+func (c *BinanceClient) CancelOrder(ctx context.Context, r models.OrderCancelRequest) (*models.OrderCancelResponse, error) {
 	url := c.buildURL(order)
-	return c.cancelOrder(url, r)
+	response, err := c.cancelOrder(ctx, url, r)
+	if err == nil && c.Rules != nil {
+		c.Rules.AddOpenOrders(r.Symbol, -1)
+	}
+	return response, err
 }
 
-func (c *BinanceClient) cancelOrder(url string, params models.OrderCancelRequest) (*models.OrderCancelResponse, error) {
+func (c *BinanceClient) cancelOrder(ctx context.Context, url string, params models.OrderCancelRequest) (*models.OrderCancelResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.OrderCancelResponse{}
-	err = c.executeRequest(http.MethodDelete, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodDelete, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -191,14 +526,14 @@ func (c *BinanceClient) cancelOrder(url string, params models.OrderCancelRequest
 	return response, nil
 }
 
-func (c *BinanceClient) cancelAllOpenOrders(url string, params models.CancelAllOrdersRequest) (*models.CancelAllOrdersResponse, error) {
+func (c *BinanceClient) cancelAllOpenOrders(ctx context.Context, url string, params models.CancelAllOrdersRequest) (*models.CancelAllOrdersResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.CancelAllOrdersResponse{}
-	err = c.executeRequest(http.MethodDelete, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodDelete, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -206,19 +541,23 @@ func (c *BinanceClient) cancelAllOpenOrders(url string, params models.CancelAllO
 	return response, nil
 }
 
-func (c *BinanceClient) CancelAllOpenOrders(r models.CancelAllOrdersRequest) (*models.CancelAllOrdersResponse, error) {
+func (c *BinanceClient) CancelAllOpenOrders(ctx context.Context, r models.CancelAllOrdersRequest) (*models.CancelAllOrdersResponse, error) {
 	url := c.buildURL(openOrders)
-	return c.cancelAllOpenOrders(url, r)
+	response, err := c.cancelAllOpenOrders(ctx, url, r)
+	if err == nil && c.Rules != nil {
+		c.Rules.SetOpenOrders(r.Symbol, 0)
+	}
+	return response, err
 }
 
-func (c *BinanceClient) getOrder(url string, params models.GetOrderRequest) (*models.GetOrderResponse, error) {
+func (c *BinanceClient) getOrder(ctx context.Context, url string, params models.GetOrderRequest) (*models.GetOrderResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.GetOrderResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -226,19 +565,19 @@ func (c *BinanceClient) getOrder(url string, params models.GetOrderRequest) (*mo
 	return response, nil
 }
 
-func (c *BinanceClient) GetOrder(r models.GetOrderRequest) (*models.GetOrderResponse, error) {
+func (c *BinanceClient) GetOrder(ctx context.Context, r models.GetOrderRequest) (*models.GetOrderResponse, error) {
 	url := c.buildURL(order)
-	return c.getOrder(url, r)
+	return c.getOrder(ctx, url, r)
 }
 
-func (c *BinanceClient) cancelReplace(url string, params models.CancelReplaceRequest) (*models.CancelReplaceResponse, error) {
+func (c *BinanceClient) cancelReplace(ctx context.Context, url string, params models.CancelReplaceRequest) (*models.CancelReplaceResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.CancelReplaceResponse{}
-	err = c.executeRequest(http.MethodPost, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodPost, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -246,39 +585,72 @@ func (c *BinanceClient) cancelReplace(url string, params models.CancelReplaceReq
 	return response, nil
 }
 
-func (c *BinanceClient) CancelReplace(r models.CancelReplaceRequest) (*models.CancelReplaceResponse, error) {
+func (c *BinanceClient) CancelReplace(ctx context.Context, r models.CancelReplaceRequest) (*models.CancelReplaceResponse, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkRisk(risk.CancelReplace(r)); err != nil {
+		return nil, err
+	}
 	url := c.buildURL(cancelReplace)
-	return c.cancelReplace(url, r)
+	response, err := c.cancelReplace(ctx, url, r)
+	if err == nil && c.Rules != nil {
+		if response.CancelResult == models.CancelReplaceSuccess {
+			c.Rules.AddOpenOrders(r.Symbol, -1)
+		}
+		if response.NewOrderResult == models.CancelReplaceSuccess && response.NewOrderResponse.Status.IsOpen() {
+			c.Rules.AddOpenOrders(r.Symbol, 1)
+		}
+	}
+	return response, err
 }
 
-func (c *BinanceClient) getOpenOrders(url string, params models.OpenOrdersRequest) (*[]models.OpenOrdersResponse, error) {
+func (c *BinanceClient) getOpenOrders(ctx context.Context, url string, params models.OpenOrdersRequest) (*[]models.OpenOrdersResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &[]models.OpenOrdersResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
+	c.cacheOpenOrders(params.Symbol, *response)
 
 	return response, nil
 }
 
-func (c *BinanceClient) GetOpenOrders(r models.OpenOrdersRequest) (*[]models.OpenOrdersResponse, error) {
+func (c *BinanceClient) GetOpenOrders(ctx context.Context, r models.OpenOrdersRequest) (*[]models.OpenOrdersResponse, error) {
 	url := c.buildURL(openOrders)
-	return c.getOpenOrders(url, r)
+	return c.getOpenOrders(ctx, url, r)
+}
+
+// cacheOpenOrders counts open orders by symbol for MAX_NUM_ORDERS checks, all symbols if symbol is empty
+func (c *BinanceClient) cacheOpenOrders(symbol string, orders []models.OpenOrdersResponse) {
+	if c.Rules == nil {
+		return
+	}
+	if symbol != "" {
+		c.Rules.SetOpenOrders(symbol, len(orders))
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, o := range orders {
+		counts[o.Symbol]++
+	}
+	c.Rules.LoadOpenOrders(counts)
 }
 
-func (c *BinanceClient) getAllOrders(url string, params models.AllOpenOrdersRequest) (*[]models.AllOpenOrdersResponse, error) {
+func (c *BinanceClient) getAllOrders(ctx context.Context, url string, params models.AllOpenOrdersRequest) (*[]models.AllOpenOrdersResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &[]models.AllOpenOrdersResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -286,19 +658,22 @@ func (c *BinanceClient) getAllOrders(url string, params models.AllOpenOrdersRequ
 	return response, nil
 }
 
-func (c *BinanceClient) GetAllOrders(r models.AllOpenOrdersRequest) (*[]models.AllOpenOrdersResponse, error) {
+func (c *BinanceClient) GetAllOrders(ctx context.Context, r models.AllOpenOrdersRequest) (*[]models.AllOpenOrdersResponse, error) {
 	url := c.buildURL(allOrders)
-	return c.getAllOrders(url, r)
+	return c.getAllOrders(ctx, url, r)
 }
 
-func (c *BinanceClient) newOCO(url string, params models.NewOCORequest) (*models.NewOCOResponse, error) {
+func (c *BinanceClient) newOCO(ctx context.Context, url string, params models.NewOCORequest) (*models.NewOCOResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
+	if err := params.CheckRules(c.Rules); err != nil {
+		return nil, err
+	}
 
 	response := &models.NewOCOResponse{}
-	err = c.executeRequest(http.MethodPost, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodPost, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -306,19 +681,32 @@ func (c *BinanceClient) newOCO(url string, params models.NewOCORequest) (*models
 	return response, nil
 }
 
-func (c *BinanceClient) NewOCO(r models.NewOCORequest) (*models.NewOCOResponse, error) {
+func (c *BinanceClient) NewOCO(ctx context.Context, r models.NewOCORequest) (*models.NewOCOResponse, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if err := r.CheckRules(c.Rules); err != nil {
+		return nil, err
+	}
+	if err := c.checkRisk(risk.NewOCO(r)); err != nil {
+		return nil, err
+	}
 	url := c.buildURL(oco)
-	return c.newOCO(url, r)
+	response, err := c.newOCO(ctx, url, r)
+	if err == nil && c.Rules != nil {
+		c.Rules.AddOpenOrders(r.Symbol, len(response.Orders))
+	}
+	return response, err
 }
 
-func (c *BinanceClient) cancelOCO(url string, params models.CancelOCORequest) (*models.CancelOCOResponse, error) {
+func (c *BinanceClient) cancelOCO(ctx context.Context, url string, params models.CancelOCORequest) (*models.CancelOCOResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.CancelOCOResponse{}
-	err = c.executeRequest(http.MethodDelete, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodDelete, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -326,19 +714,19 @@ func (c *BinanceClient) cancelOCO(url string, params models.CancelOCORequest) (*
 	return response, nil
 }
 
-func (c *BinanceClient) CancelOCO(r models.CancelOCORequest) (*models.CancelOCOResponse, error) {
+func (c *BinanceClient) CancelOCO(ctx context.Context, r models.CancelOCORequest) (*models.CancelOCOResponse, error) {
 	url := c.buildURL(orderList)
-	return c.cancelOCO(url, r)
+	return c.cancelOCO(ctx, url, r)
 }
 
-func (c *BinanceClient) getOCO(url string, params models.GetOCORequest) (*models.GetOCOResponse, error) {
+func (c *BinanceClient) getOCO(ctx context.Context, url string, params models.GetOCORequest) (*models.GetOCOResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &models.GetOCOResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -346,19 +734,19 @@ func (c *BinanceClient) getOCO(url string, params models.GetOCORequest) (*models
 	return response, nil
 }
 
-func (c *BinanceClient) GetOCO(r models.GetOCORequest) (*models.GetOCOResponse, error) {
+func (c *BinanceClient) GetOCO(ctx context.Context, r models.GetOCORequest) (*models.GetOCOResponse, error) {
 	url := c.buildURL(orderList)
-	return c.getOCO(url, r)
+	return c.getOCO(ctx, url, r)
 }
 
-func (c *BinanceClient) allOCOList(url string, params models.AllOCOListRequest) (*[]models.AllOCOListResponse, error) {
+func (c *BinanceClient) allOCOList(ctx context.Context, url string, params models.AllOCOListRequest) (*[]models.AllOCOListResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &[]models.AllOCOListResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -366,19 +754,19 @@ func (c *BinanceClient) allOCOList(url string, params models.AllOCOListRequest)
 	return response, nil
 }
 
-func (c *BinanceClient) AllOCOList(r models.AllOCOListRequest) (*[]models.AllOCOListResponse, error) {
+func (c *BinanceClient) AllOCOList(ctx context.Context, r models.AllOCOListRequest) (*[]models.AllOCOListResponse, error) {
 	url := c.buildURL(allOrderList)
-	return c.allOCOList(url, r)
+	return c.allOCOList(ctx, url, r)
 }
 
-func (c *BinanceClient) queryOCOList(url string, params models.QueryOpenOCORequest) (*[]models.QueryOpenOCOResponse, error) {
+func (c *BinanceClient) queryOCOList(ctx context.Context, url string, params models.QueryOpenOCORequest) (*[]models.QueryOpenOCOResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
 
 	response := &[]models.QueryOpenOCOResponse{}
-	err = c.executeRequest(http.MethodGet, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -386,19 +774,22 @@ func (c *BinanceClient) queryOCOList(url string, params models.QueryOpenOCOReque
 	return response, nil
 }
 
-func (c *BinanceClient) QueryOCOList(r models.QueryOpenOCORequest) (*[]models.QueryOpenOCOResponse, error) {
+func (c *BinanceClient) QueryOCOList(ctx context.Context, r models.QueryOpenOCORequest) (*[]models.QueryOpenOCOResponse, error) {
 	url := c.buildURL(openOrderList)
-	return c.queryOCOList(url, r)
+	return c.queryOCOList(ctx, url, r)
 }
 
-func (c *BinanceClient) newSOR(url string, params models.NewSORRequest) (*[]models.NewSORResponse, error) {
+func (c *BinanceClient) newSOR(ctx context.Context, url string, params models.NewSORRequest) (*[]models.NewSORResponse, error) {
 	err := params.Validate()
 	if err != nil {
 		return nil, err
 	}
+	if err := params.CheckRules(c.Rules); err != nil {
+		return nil, err
+	}
 
 	response := &[]models.NewSORResponse{}
-	err = c.executeRequest(http.MethodPost, url, nil, response, true, params)
+	err = c.executeRequest(ctx, http.MethodPost, url, nil, response, securitySigned, params)
 	if err != nil {
 		return nil, err
 	}
@@ -406,12 +797,217 @@ func (c *BinanceClient) newSOR(url string, params models.NewSORRequest) (*[]mode
 	return response, nil
 }
 
-func (c *BinanceClient) NewSOR(r models.NewSORRequest) (*[]models.NewSORResponse, error) {
+func (c *BinanceClient) NewSOR(ctx context.Context, r models.NewSORRequest) (*[]models.NewSORResponse, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if err := r.CheckRules(c.Rules); err != nil {
+		return nil, err
+	}
+	if err := c.checkRisk(risk.NewSOR(r)); err != nil {
+		return nil, err
+	}
 	url := c.buildURL(newSOR)
-	return c.newSOR(url, r)
+	response, err := c.newSOR(ctx, url, r)
+	if err == nil && c.Rules != nil {
+		for _, o := range *response {
+			if o.Status.IsOpen() {
+				c.Rules.AddOpenOrders(r.Symbol, 1)
+			}
+		}
+	}
+	return response, err
 }
 
-func (c *BinanceClient) TestNewSOR(r models.NewSORRequest) (*[]models.NewSORResponse, error) {
+func (c *BinanceClient) TestNewSOR(ctx context.Context, r models.NewSORRequest) (*[]models.NewSORResponse, error) {
 	url := c.buildURL(testNewSOR)
-	return c.newSOR(url, r)
+	return c.newSOR(ctx, url, r)
+}
+
+// ––––––––––– ACCOUNT –––––––––––
+
+func (c *BinanceClient) getAccount(ctx context.Context, url string, params models.AccountRequest) (*models.AccountResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AccountResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetAccount returns balances, commissions and permissions of the account
+func (c *BinanceClient) GetAccount(ctx context.Context, r models.AccountRequest) (*models.AccountResponse, error) {
+	url := c.buildURL(account)
+	return c.getAccount(ctx, url, r)
+}
+
+func (c *BinanceClient) getMyTrades(ctx context.Context, url string, params models.MyTradesRequest) (*[]models.MyTradesResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.MyTradesResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetMyTrades returns trades of the account on the symbol
+func (c *BinanceClient) GetMyTrades(ctx context.Context, r models.MyTradesRequest) (*[]models.MyTradesResponse, error) {
+	url := c.buildURL(myTrades)
+	return c.getMyTrades(ctx, url, r)
+}
+
+func (c *BinanceClient) getOrderRateLimit(ctx context.Context, url string, params models.OrderRateLimitRequest) (*[]models.OrderRateLimitResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.OrderRateLimitResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetOrderRateLimit returns the current unfilled order count of all ORDERS intervals
+func (c *BinanceClient) GetOrderRateLimit(ctx context.Context, r models.OrderRateLimitRequest) (*[]models.OrderRateLimitResponse, error) {
+	url := c.buildURL(rateLimitOrder)
+	return c.getOrderRateLimit(ctx, url, r)
+}
+
+func (c *BinanceClient) getPreventedMatches(ctx context.Context, url string, params models.PreventedMatchesRequest) (*[]models.PreventedMatchesResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.PreventedMatchesResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetPreventedMatches returns orders expired by self-trade prevention
+func (c *BinanceClient) GetPreventedMatches(ctx context.Context, r models.PreventedMatchesRequest) (*[]models.PreventedMatchesResponse, error) {
+	url := c.buildURL(myPreventedMatches)
+	return c.getPreventedMatches(ctx, url, r)
+}
+
+func (c *BinanceClient) getAllocations(ctx context.Context, url string, params models.AllocationsRequest) (*[]models.AllocationsResponse, error) {
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &[]models.AllocationsResponse{}
+	err = c.executeRequest(ctx, http.MethodGet, url, nil, response, securitySigned, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetAllocations returns allocations of orders placed by SOR
+func (c *BinanceClient) GetAllocations(ctx context.Context, r models.AllocationsRequest) (*[]models.AllocationsResponse, error) {
+	url := c.buildURL(myAllocations)
+	return c.getAllocations(ctx, url, r)
+}
+
+// ––––––––––– USER DATA STREAM –––––––––––
+
+// CreateListenKey Start a new user data stream. The stream will close after 60 minutes unless a keepalive is sent.
+func (c *BinanceClient) CreateListenKey(ctx context.Context) (*models.ListenKeyResponse, error) {
+	url := c.buildURL(userDataStream)
+	response := &models.ListenKeyResponse{}
+	var params interface{} // no params needed
+	err := c.executeRequest(ctx, http.MethodPost, url, nil, response, securityAPIKey, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// KeepAliveListenKey Extends the validity of the listenKey for 60 minutes
+func (c *BinanceClient) KeepAliveListenKey(ctx context.Context, r models.ListenKeyRequest) error {
+	url := c.buildURL(userDataStream)
+	return c.listenKey(ctx, http.MethodPut, url, r)
+}
+
+// CloseListenKey Closes out the user data stream
+func (c *BinanceClient) CloseListenKey(ctx context.Context, r models.ListenKeyRequest) error {
+	url := c.buildURL(userDataStream)
+	return c.listenKey(ctx, http.MethodDelete, url, r)
+}
+
+func (c *BinanceClient) listenKey(ctx context.Context, method, url string, params models.ListenKeyRequest) error {
+	err := params.Validate()
+	if err != nil {
+		return err
+	}
+
+	response := &struct{}{}
+	return c.executeRequest(ctx, method, url, nil, response, securityAPIKey, params)
+}
+
+// StartUserDataStream creates a listenKey and keeps it alive in background every 30 minutes.
+// Closing done channel or cancelling ctx stops keepalive and closes the listenKey.
+func (c *BinanceClient) StartUserDataStream(ctx context.Context) (string, chan<- struct{}, error) {
+	response, err := c.CreateListenKey(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	done := make(chan struct{})
+	r := models.ListenKeyRequest{ListenKey: response.ListenKey}
+
+	go func() {
+		ticker := time.NewTicker(listenKeyKeepAlive)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				c.closeListenKey(r)
+				return
+			case <-ctx.Done():
+				c.closeListenKey(r)
+				return
+			case <-ticker.C:
+				if err := c.KeepAliveListenKey(ctx, r); err != nil {
+					log.Error().Msg(fmt.Sprintf("Failed to keep alive listenKey: %s", err))
+				}
+			}
+		}
+	}()
+
+	return response.ListenKey, done, nil
+}
+
+// closeListenKey closes the listenKey on shutdown when the caller context may already be cancelled
+func (c *BinanceClient) closeListenKey(r models.ListenKeyRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := c.CloseListenKey(ctx, r); err != nil {
+		log.Error().Msg(fmt.Sprintf("Failed to close listenKey: %s", err))
+	}
 }
@@ -0,0 +1,85 @@
+package v3
+
+import (
+	"context"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func newTestClient(t *testing.T, opts ...Option) (*BinanceClient, *binancetest.Server) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+
+	opts = append([]Option{WithBaseURL(s.URL())}, opts...)
+	c := NewBinanceClient("key", "secret", opts...)
+	c.OrderLookupDelay = time.Millisecond
+	return c, s
+}
+
+func limitOrder(price, quantity string) models.OrderRequest {
+	return models.OrderRequest{
+		Symbol:      "BTCUSDT",
+		Side:        models.SideBuy,
+		Type:        models.OrderTypeLimit,
+		TimeInForce: models.TimeInForceGTC,
+		Price:       d(price),
+		Quantity:    d(quantity),
+	}
+}
+
+func limitOrderWithID(clientOrderID string) models.OrderRequest {
+	r := limitOrder("100", "1")
+	r.NewClientOrderID = clientOrderID
+	return r
+}
+
+func TestSignedRequestIsAccepted(t *testing.T) {
+	c, s := newTestClient(t)
+
+	if _, err := c.GetAccount(context.Background(), models.AccountRequest{}); err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+
+	requests := s.Requests()
+	last := requests[len(requests)-1]
+	if last.APIKey != "key" || last.Query["signature"] == "" || last.Query["timestamp"] == "" {
+		t.Fatalf("request = %+v, want API key, timestamp and signature", last)
+	}
+}
+
+func TestSignatureWithWrongSecretIsRejected(t *testing.T) {
+	s := binancetest.NewServer("key", "secret")
+	defer s.Close()
+	c := NewBinanceClient("key", "other", WithBaseURL(s.URL()))
+
+	_, err := c.GetAccount(context.Background(), models.AccountRequest{})
+	apiErr, ok := binance.AsAPIError(err)
+	if !ok || apiErr.Code != binance.CodeInvalidSignature {
+		t.Fatalf("error = %v, want invalid signature", err)
+	}
+}
+
+func TestScriptedErrorIsReturned(t *testing.T) {
+	c, s := newTestClient(t)
+	s.Script(http.MethodPost, order, binancetest.Error(http.StatusBadRequest, binance.CodeNewOrderRejected,
+		"Account has insufficient balance for requested action."))
+
+	_, err := c.NewOrder(context.Background(), limitOrder("100", "1"))
+	if !binance.IsInsufficientBalance(err) {
+		t.Fatalf("error = %v, want insufficient balance", err)
+	}
+
+	// Scripted responses are used once
+	if _, err := c.NewOrder(context.Background(), limitOrder("100", "1")); err != nil {
+		t.Fatalf("second order: %v", err)
+	}
+}
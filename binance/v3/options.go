@@ -0,0 +1,70 @@
+package v3
+
+import (
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+)
+
+// Option configures BinanceClient created by NewBinanceClient
+type Option func(*BinanceClient)
+
+// WithEnvironment sends requests to REST API of the environment, e.g. binance.Testnet
+func WithEnvironment(env binance.Environment) Option {
+	return func(c *BinanceClient) {
+		if env.RESTURL != "" {
+			c.BaseURL = env.RESTURL
+		}
+	}
+}
+
+// WithBaseURL sends requests to a custom REST API URL, e.g. a proxy or binancetest.Server
+func WithBaseURL(baseURL string) Option {
+	return func(c *BinanceClient) {
+		c.BaseURL = baseURL
+	}
+}
+
+// WithRecvWindow sets recvWindow in milliseconds sent with signed requests
+func WithRecvWindow(recvWindow int64) Option {
+	return func(c *BinanceClient) {
+		c.RecvWindow = recvWindow
+	}
+}
+
+// WithRateLimiter replaces the default rate limiter, nil disables client side rate limiting
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *BinanceClient) {
+		c.RateLimiter = limiter
+	}
+}
+
+// WithSigner signs requests with RSA or Ed25519 key instead of HMAC with the secret key, nil keeps HMAC
+func WithSigner(signer binance.Signer) Option {
+	return func(c *BinanceClient) {
+		if signer != nil {
+			c.Signer = signer
+		}
+	}
+}
+
+// WithClientOrderIDs generates newClientOrderId of orders which do not set it
+func WithClientOrderIDs(generator *binance.ClientOrderIDGenerator) Option {
+	return func(c *BinanceClient) {
+		c.ClientOrderIDs = generator
+	}
+}
+
+// WithRisk checks every order of NewOrder, CancelReplace, NewOCO and NewSOR before it is sent
+func WithRisk(checker risk.Checker) Option {
+	return func(c *BinanceClient) {
+		c.Risk = checker
+	}
+}
+
+// WithRules checks orders against the registry, e.g. shared with wsapi.Client, nil disables the checks
+func WithRules(rules *models.SymbolRegistry) Option {
+	return func(c *BinanceClient) {
+		c.Rules = rules
+	}
+}
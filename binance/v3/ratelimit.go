@@ -0,0 +1,377 @@
+package v3
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	requestWeight = "REQUEST_WEIGHT"
+	orders        = "ORDERS"
+	rawRequests   = "RAW_REQUESTS"
+
+	usedWeightHeader = "X-MBX-USED-WEIGHT-"
+	orderCountHeader = "X-MBX-ORDER-COUNT-"
+)
+
+// defaultRateLimits are used until limits are seeded by GetExchangeInfo
+var defaultRateLimits = []models.RateLimit{
+	{RateLimitType: requestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 6000},
+	{RateLimitType: orders, Interval: "SECOND", IntervalNum: 10, Limit: 100},
+	{RateLimitType: orders, Interval: "DAY", IntervalNum: 1, Limit: 200000},
+	{RateLimitType: rawRequests, Interval: "MINUTE", IntervalNum: 5, Limit: 61000},
+}
+
+var intervals = map[string]time.Duration{
+	"SECOND": time.Second,
+	"MINUTE": time.Minute,
+	"HOUR":   time.Hour,
+	"DAY":    24 * time.Hour,
+}
+
+var intervalLetters = map[string]string{
+	"S": "SECOND",
+	"M": "MINUTE",
+	"H": "HOUR",
+	"D": "DAY",
+}
+
+// RateLimitUsage current usage of the limit, used for dashboards
+type RateLimitUsage struct {
+	models.RateLimit
+	Used    int
+	ResetAt time.Time
+}
+
+// window counts usage of a single limit, Binance resets counters at the start of each interval
+type window struct {
+	limit    models.RateLimit
+	interval time.Duration
+	used     int
+	start    time.Time
+}
+
+func (w *window) roll(now time.Time) {
+	if start := now.Truncate(w.interval); start.After(w.start) {
+		w.start = start
+		w.used = 0
+	}
+}
+
+func (w *window) cost(weight, orderCount int) int {
+	switch w.limit.RateLimitType {
+	case requestWeight:
+		return weight
+	case orders:
+		return orderCount
+	case rawRequests:
+		return 1
+	}
+	return 0
+}
+
+// RateLimiter tracks request weight and order count reported by Binance
+// and stops requests which would exceed a limit before they are sent.
+type RateLimiter struct {
+	Throttle bool // wait for the next interval instead of rejecting the request
+
+	mu          sync.Mutex
+	windows     []*window
+	bannedUntil time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	l := &RateLimiter{Throttle: true}
+	l.SetLimits(defaultRateLimits)
+	return l
+}
+
+// SetLimits replaces the limits keeping the usage of the known ones
+func (l *RateLimiter) SetLimits(limits []models.RateLimit) {
+	if len(limits) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	windows := make([]*window, 0, len(limits))
+	for _, limit := range limits {
+		unit, ok := intervals[limit.Interval]
+		if !ok || limit.IntervalNum <= 0 {
+			continue
+		}
+
+		w := &window{limit: limit, interval: time.Duration(limit.IntervalNum) * unit}
+		if prev := l.find(limit.RateLimitType, w.interval); prev != nil {
+			w.used, w.start = prev.used, prev.start
+		}
+		windows = append(windows, w)
+	}
+	l.windows = windows
+}
+
+// Usage returns the current usage of every limit
+func (l *RateLimiter) Usage() []RateLimitUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	usage := make([]RateLimitUsage, 0, len(l.windows))
+	for _, w := range l.windows {
+		w.roll(now)
+		usage = append(usage, RateLimitUsage{
+			RateLimit: w.limit,
+			Used:      w.used,
+			ResetAt:   w.start.Add(w.interval),
+		})
+	}
+	return usage
+}
+
+// BannedUntil time until which requests are not allowed after 418 or 429 response
+func (l *RateLimiter) BannedUntil() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bannedUntil
+}
+
+// wait reserves weight and order count of the request, throttling or rejecting it if a limit would be exceeded
+func (l *RateLimiter) wait(ctx context.Context, weight, orderCount int) error {
+	for {
+		delay, err := l.reserve(weight, orderCount)
+		if err == nil {
+			return nil
+		}
+		if !l.Throttle || delay <= 0 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (l *RateLimiter) reserve(weight, orderCount int) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.bannedUntil) {
+		return l.bannedUntil.Sub(now), fmt.Errorf("%w: requests are not allowed until %s",
+			binance.ErrRateLimitExceeded, l.bannedUntil.Format(time.RFC3339))
+	}
+
+	var delay time.Duration
+	var err error
+	for _, w := range l.windows {
+		w.roll(now)
+		cost := w.cost(weight, orderCount)
+		if cost > w.limit.Limit {
+			return 0, fmt.Errorf("%w: request cost %d is above %s limit %d", binance.ErrRateLimitExceeded,
+				cost, w.limit.RateLimitType, w.limit.Limit)
+		}
+		if cost > 0 && w.used+cost > w.limit.Limit {
+			if reset := w.start.Add(w.interval).Sub(now); reset > delay {
+				delay = reset
+				err = fmt.Errorf("%w: %s %d/%d per %d %s", binance.ErrRateLimitExceeded,
+					w.limit.RateLimitType, w.used, w.limit.Limit, w.limit.IntervalNum, w.limit.Interval)
+			}
+		}
+	}
+	if err != nil {
+		return delay, err
+	}
+
+	for _, w := range l.windows {
+		w.used += w.cost(weight, orderCount)
+	}
+	return 0, nil
+}
+
+// update sets the usage reported in response headers and honours Retry-After of 418 and 429 responses
+func (l *RateLimiter) update(status int, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for name, values := range header {
+		name = strings.ToUpper(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		var limitType string
+		switch {
+		case strings.HasPrefix(name, usedWeightHeader):
+			limitType = requestWeight
+		case strings.HasPrefix(name, orderCountHeader):
+			limitType = orders
+		default:
+			continue
+		}
+
+		interval, ok := parseInterval(name[strings.LastIndex(name, "-")+1:])
+		if !ok {
+			continue
+		}
+		used, err := strconv.Atoi(values[0])
+		if err != nil {
+			continue
+		}
+
+		if w := l.find(limitType, interval); w != nil {
+			w.roll(now)
+			w.used = used
+		}
+	}
+
+	if status == http.StatusTooManyRequests || status == binance.StatusIPBanned {
+		if retryAfter := parseRetryAfter(header); retryAfter > 0 {
+			l.bannedUntil = now.Add(retryAfter)
+		}
+	}
+}
+
+func (l *RateLimiter) find(limitType string, interval time.Duration) *window {
+	for _, w := range l.windows {
+		if w.limit.RateLimitType == limitType && w.interval == interval {
+			return w
+		}
+	}
+	return nil
+}
+
+// parseInterval parses header interval such as 1M or 10S
+func parseInterval(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+
+	num, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return 0, false
+	}
+	unit, ok := intervals[intervalLetters[value[len(value)-1:]]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(num) * unit, true
+}
+
+func parseRetryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// endpointWeight returns request weight and number of orders placed by the endpoint
+func endpointWeight(method, endpoint string, params interface{}) (int, int) {
+	switch endpoint {
+	case exchangeInfo, allOrders, allOrderList, account, myAllocations:
+		return 20, 0
+	case myTrades:
+		if r, ok := params.(models.MyTradesRequest); ok && r.OrderID != nil {
+			return 5, 0
+		}
+		return 20, 0
+	case rateLimitOrder:
+		return 40, 0
+	case myPreventedMatches:
+		if r, ok := params.(models.PreventedMatchesRequest); ok && r.PreventedMatchID != nil {
+			return 2, 0
+		}
+		return 20, 0
+	case depth:
+		if r, ok := params.(models.DepthRequest); ok {
+			switch {
+			case r.Limit > 1000:
+				return 250, 0
+			case r.Limit > 500:
+				return 50, 0
+			case r.Limit > 100:
+				return 25, 0
+			}
+		}
+		return 5, 0
+	case trades, historicalTrades:
+		return 25, 0
+	case aggTrades, klines, uiKlines, avgPrice:
+		return 2, 0
+	case ticker24hr:
+		if r, ok := params.(models.Ticker24hrRequest); ok {
+			switch {
+			case r.Symbol != "":
+				return 2, 0
+			case len(r.Symbols) > 0 && len(r.Symbols) <= 20:
+				return 2, 0
+			case len(r.Symbols) > 20 && len(r.Symbols) <= 100:
+				return 40, 0
+			}
+		}
+		return 80, 0
+	case tickerTradingDay:
+		if r, ok := params.(models.TradingDayTickerRequest); ok {
+			return tickerWeight(len(r.Symbols)), 0
+		}
+	case ticker:
+		if r, ok := params.(models.RollingTickerRequest); ok {
+			return tickerWeight(len(r.Symbols)), 0
+		}
+	case tickerPrice, tickerBookTicker:
+		if r, ok := params.(models.TickerRequest); ok && r.Symbol != "" {
+			return 2, 0
+		}
+		return 4, 0
+	case order:
+		switch method {
+		case http.MethodGet:
+			return 4, 0
+		case http.MethodPost:
+			return 1, 1
+		}
+	case openOrders:
+		if r, ok := params.(models.OpenOrdersRequest); ok && method == http.MethodGet {
+			if r.Symbol == "" {
+				return 80, 0
+			}
+			return 6, 0
+		}
+	case cancelReplace, newSOR:
+		return 1, 1
+	case oco:
+		return 1, 2
+	case orderList:
+		if method == http.MethodGet {
+			return 4, 0
+		}
+	case openOrderList:
+		return 6, 0
+	case userDataStream:
+		return 2, 0
+	}
+	return 1, 0
+}
+
+// tickerWeight weight of trading day and rolling window tickers, 4 per symbol up to 200
+func tickerWeight(symbols int) int {
+	if symbols <= 1 {
+		return 4
+	}
+	if symbols > 50 {
+		return 200
+	}
+	return 4 * symbols
+}
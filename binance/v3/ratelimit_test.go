@@ -0,0 +1,151 @@
+package v3
+
+import (
+	"context"
+	"errors"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(limits ...models.RateLimit) *RateLimiter {
+	l := &RateLimiter{}
+	l.SetLimits(limits)
+	return l
+}
+
+func TestRateLimiterRejectsRequestAboveLimit(t *testing.T) {
+	l := newTestLimiter(models.RateLimit{RateLimitType: requestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 10})
+	ctx := context.Background()
+
+	if err := l.wait(ctx, 6, 0); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if err := l.wait(ctx, 6, 0); !errors.Is(err, binance.ErrRateLimitExceeded) {
+		t.Fatalf("second request error = %v, want ErrRateLimitExceeded", err)
+	}
+	if err := l.wait(ctx, 4, 0); err != nil {
+		t.Fatalf("request within the limit: %v", err)
+	}
+	if err := l.wait(ctx, 11, 0); !errors.Is(err, binance.ErrRateLimitExceeded) {
+		t.Fatalf("request above the whole limit error = %v, want ErrRateLimitExceeded", err)
+	}
+
+	usage := l.Usage()
+	if len(usage) != 1 || usage[0].Used != 10 {
+		t.Fatalf("usage = %+v, want 10 used", usage)
+	}
+}
+
+func TestRateLimiterCountsOrdersSeparately(t *testing.T) {
+	l := newTestLimiter(
+		models.RateLimit{RateLimitType: requestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 100},
+		models.RateLimit{RateLimitType: orders, Interval: "SECOND", IntervalNum: 10, Limit: 1},
+	)
+	ctx := context.Background()
+
+	if err := l.wait(ctx, 1, 1); err != nil {
+		t.Fatalf("first order: %v", err)
+	}
+	if err := l.wait(ctx, 1, 0); err != nil {
+		t.Fatalf("request without orders: %v", err)
+	}
+	if err := l.wait(ctx, 1, 1); !errors.Is(err, binance.ErrRateLimitExceeded) {
+		t.Fatalf("second order error = %v, want ErrRateLimitExceeded", err)
+	}
+}
+
+func TestRateLimiterThrottlesUntilNextWindow(t *testing.T) {
+	l := newTestLimiter(models.RateLimit{RateLimitType: rawRequests, Interval: "SECOND", IntervalNum: 1, Limit: 1})
+	l.Throttle = true
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := l.wait(ctx, 0, 0); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	start := time.Now()
+	if err := l.wait(ctx, 0, 0); err != nil {
+		t.Fatalf("throttled request: %v", err)
+	}
+	if waited := time.Since(start); waited > 1100*time.Millisecond {
+		t.Fatalf("waited %s, want at most one window", waited)
+	}
+}
+
+func TestRateLimiterThrottleStopsWithContext(t *testing.T) {
+	l := newTestLimiter(models.RateLimit{RateLimitType: rawRequests, Interval: "MINUTE", IntervalNum: 1, Limit: 1})
+	l.Throttle = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := l.wait(ctx, 0, 0); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if err := l.wait(ctx, 0, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRateLimiterUpdatesUsageFromHeaders(t *testing.T) {
+	l := newTestLimiter(models.RateLimit{RateLimitType: requestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 6000})
+
+	header := http.Header{}
+	header.Set("X-Mbx-Used-Weight-1m", "5990")
+	l.update(http.StatusOK, header)
+
+	if usage := l.Usage(); usage[0].Used != 5990 {
+		t.Fatalf("used = %d, want 5990", usage[0].Used)
+	}
+	if err := l.wait(context.Background(), 20, 0); !errors.Is(err, binance.ErrRateLimitExceeded) {
+		t.Fatalf("error = %v, want ErrRateLimitExceeded", err)
+	}
+}
+
+func TestRateLimiterHonoursRetryAfter(t *testing.T) {
+	l := newTestLimiter(models.RateLimit{RateLimitType: requestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 6000})
+
+	header := http.Header{}
+	header.Set("Retry-After", "30")
+	l.update(http.StatusTooManyRequests, header)
+
+	if until := time.Until(l.BannedUntil()); until < 29*time.Second {
+		t.Fatalf("banned for %s, want 30s", until)
+	}
+	if err := l.wait(context.Background(), 1, 0); !errors.Is(err, binance.ErrRateLimitExceeded) {
+		t.Fatalf("error = %v, want ErrRateLimitExceeded", err)
+	}
+}
+
+func TestSetLimitsKeepsUsage(t *testing.T) {
+	weight := models.RateLimit{RateLimitType: requestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 10}
+	l := newTestLimiter(weight)
+	if err := l.wait(context.Background(), 5, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	weight.Limit = 20
+	l.SetLimits([]models.RateLimit{weight})
+	if usage := l.Usage(); usage[0].Used != 5 || usage[0].Limit != 20 {
+		t.Fatalf("usage = %+v, want 5 of 20", usage[0])
+	}
+}
+
+func TestEndpointWeightUsesPathRelativeToBaseURL(t *testing.T) {
+	c := NewBinanceClient("key", "secret", WithBaseURL("http://localhost/binance"))
+	u, err := url.Parse(c.buildURL(exchangeInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := c.endpointPath(u)
+	if path != exchangeInfo {
+		t.Fatalf("path = %s, want %s", path, exchangeInfo)
+	}
+	if weight, _ := endpointWeight(http.MethodGet, path, models.ExchangeInfoRequest{}); weight != 20 {
+		t.Fatalf("weight = %d, want 20", weight)
+	}
+}
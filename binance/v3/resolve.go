@@ -0,0 +1,71 @@
+package v3
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultOrderLookups attempts to find an order whose request failed with unknown execution status
+	defaultOrderLookups = 3
+	// defaultOrderLookupDelay Binance may still be processing the order when the request fails
+	defaultOrderLookupDelay = time.Second
+)
+
+// resolveOrder looks up the order whose request failed with unknown execution status by its client order id.
+// Lookups stop when ctx is done, the order status stays unknown then.
+func (c *BinanceClient) resolveOrder(ctx context.Context, symbol, clientOrderID string, sendErr error) (*models.OrderResponseFull, error) {
+	log.Warn().Msg(fmt.Sprintf("Execution status of order %s is unknown, looking it up: %s", clientOrderID, sendErr))
+
+	lookupErr := sendErr
+	for attempt := 0; attempt < c.OrderLookups; attempt++ {
+		timer := time.NewTimer(c.OrderLookupDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %s: %s, lookup: %s", binance.ErrOrderStatusUnknown, clientOrderID, sendErr, ctx.Err())
+		case <-timer.C:
+		}
+
+		order, err := c.GetOrder(ctx, models.GetOrderRequest{Symbol: symbol, OrigClientOrderID: clientOrderID})
+		if err == nil {
+			log.Info().Msg(fmt.Sprintf("Order %s was placed with status %s", clientOrderID, order.Status))
+			return placedOrder(order), nil
+		}
+		lookupErr = err
+		if !binance.IsUnknownOrder(err) && !binance.IsExecutionUnknown(err) && !binance.IsRateLimited(err) {
+			break
+		}
+	}
+
+	if binance.IsUnknownOrder(lookupErr) {
+		return nil, fmt.Errorf("%w: %s not found after %s", binance.ErrOrderNotPlaced, clientOrderID, sendErr)
+	}
+	return nil, fmt.Errorf("%w: %s: %s, lookup: %s", binance.ErrOrderStatusUnknown, clientOrderID, sendErr, lookupErr)
+}
+
+// placedOrder response of NewOrder from the order found by GetOrder, fills are not known
+func placedOrder(o *models.GetOrderResponse) *models.OrderResponseFull {
+	return &models.OrderResponseFull{
+		Symbol:                  o.Symbol,
+		OrderId:                 int64(o.OrderId),
+		OrderListId:             int64(o.OrderListId),
+		ClientOrderId:           o.ClientOrderId,
+		TransactTime:            o.Time,
+		Price:                   o.Price,
+		OrigQty:                 o.OrigQty,
+		ExecutedQty:             o.ExecutedQty,
+		CummulativeQuoteQty:     o.CummulativeQuoteQty,
+		Status:                  o.Status,
+		TimeInForce:             o.TimeInForce,
+		Type:                    o.Type,
+		Side:                    o.Side,
+		WorkingTime:             o.WorkingTime,
+		SelfTradePreventionMode: o.SelfTradePreventionMode,
+	}
+}
@@ -0,0 +1,62 @@
+package v3
+
+import (
+	"context"
+	"errors"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewOrderNotFoundAfterUnknownExecution(t *testing.T) {
+	c, s := newTestClient(t)
+	s.Script(http.MethodPost, order, binancetest.Error(http.StatusServiceUnavailable, binance.CodeUnknown, "Service unavailable"))
+
+	r := limitOrder("100", "1")
+	r.NewClientOrderID = "resolve-1"
+	_, err := c.NewOrder(context.Background(), r)
+	if !errors.Is(err, binance.ErrOrderNotPlaced) {
+		t.Fatalf("error = %v, want ErrOrderNotPlaced", err)
+	}
+}
+
+func TestNewOrderFoundAfterUnknownExecution(t *testing.T) {
+	c, s := newTestClient(t)
+	ctx := context.Background()
+
+	// The order reaches the exchange but its response is lost
+	if _, err := c.newOrder(ctx, c.buildURL(order), limitOrderWithID("resolve-2")); err != nil {
+		t.Fatal(err)
+	}
+	s.Script(http.MethodPost, order, binancetest.Error(http.StatusGatewayTimeout, binance.CodeTimeout,
+		"Timeout waiting for response from backend server."))
+
+	response, err := c.NewOrder(ctx, limitOrderWithID("resolve-2"))
+	if err != nil {
+		t.Fatalf("NewOrder: %v", err)
+	}
+	if response.ClientOrderId != "resolve-2" || response.Status != models.OrderStatusNew {
+		t.Fatalf("response = %+v, want the placed order", response)
+	}
+}
+
+func TestOrderLookupStopsWithContext(t *testing.T) {
+	c, s := newTestClient(t)
+	c.OrderLookupDelay = time.Hour
+	s.Script(http.MethodPost, order, binancetest.Error(http.StatusInternalServerError, binance.CodeUnknown, "Internal error"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.NewOrder(ctx, limitOrderWithID("resolve-3"))
+	if !errors.Is(err, binance.ErrOrderStatusUnknown) {
+		t.Fatalf("error = %v, want ErrOrderStatusUnknown", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("lookup took %s after the context was done", elapsed)
+	}
+}
@@ -0,0 +1,43 @@
+package v3
+
+import (
+	"context"
+	"errors"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+	"testing"
+	"time"
+)
+
+func TestInvalidOrdersAreNotCountedByRiskChecks(t *testing.T) {
+	engine := risk.NewEngine()
+	engine.MaxOrders = 1
+	engine.RateWindow = time.Minute
+	c, s := newTestClient(t, WithRateLimiter(nil), WithRisk(engine))
+	ctx := context.Background()
+	s.SetDepth("BTCUSDT", 1, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})
+	if _, err := c.GetExchangeInfo(ctx, models.ExchangeInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := limitOrder("100", "1")
+	invalid.Side = ""
+	if _, err := c.NewOrder(ctx, invalid); err == nil || errors.Is(err, risk.ErrRejected) {
+		t.Fatalf("invalid order error = %v, want validation error", err)
+	}
+	if _, err := c.NewOrder(ctx, limitOrder("100", "0.000001")); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("LOT_SIZE error = %v, want filter failure", err)
+	}
+	if _, err := c.CancelReplace(ctx, models.CancelReplaceRequest{Symbol: "BTCUSDT"}); err == nil || errors.Is(err, risk.ErrRejected) {
+		t.Fatalf("invalid cancel-replace error = %v, want validation error", err)
+	}
+
+	// The only order of the window is the valid one
+	if _, err := c.NewOrder(ctx, limitOrder("100", "1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.NewOrder(ctx, limitOrder("100", "1")); !errors.Is(err, risk.ErrRejected) {
+		t.Fatalf("second order error = %v, want order rate rejection", err)
+	}
+}
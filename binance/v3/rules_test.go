@@ -0,0 +1,83 @@
+package v3
+
+import (
+	"context"
+	"errors"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"testing"
+	"time"
+)
+
+func TestOrdersAreCheckedAgainstClientRules(t *testing.T) {
+	c, s := newTestClient(t, WithRateLimiter(nil))
+	ctx := context.Background()
+	s.SetDepth("BTCUSDT", 1, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})
+
+	// Rules are not loaded yet
+	tiny := limitOrder("100", "0.000001")
+	if err := tiny.CheckRules(c.Rules); err != nil {
+		t.Fatalf("order checked before rules are loaded: %v", err)
+	}
+
+	if _, err := c.GetExchangeInfo(ctx, models.ExchangeInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.NewOrder(ctx, tiny); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("LOT_SIZE error = %v, want filter failure", err)
+	}
+
+	if other := NewBinanceClient("key", "secret", WithBaseURL(s.URL())); other.Rules == c.Rules {
+		t.Fatal("clients share rules")
+	} else if _, ok := other.Rules.Get("BTCUSDT"); ok {
+		t.Fatal("rules loaded by one client are visible to another")
+	}
+}
+
+func TestPercentPriceBySideUsesCachedAvgPrice(t *testing.T) {
+	c, s := newTestClient(t, WithRateLimiter(nil))
+	ctx := context.Background()
+	s.SetDepth("BTCUSDT", 1, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})
+	s.AddTrade("BTCUSDT", d("100"), d("1"), time.Now(), false)
+
+	if _, err := c.GetExchangeInfo(ctx, models.ExchangeInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	// Average price is not known yet
+	if _, err := c.NewOrder(ctx, limitOrder("10", "1")); err != nil {
+		t.Fatalf("order without average price: %v", err)
+	}
+
+	if _, err := c.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: "BTCUSDT"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.NewOrder(ctx, limitOrder("10", "1")); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("error = %v, want PERCENT_PRICE_BY_SIDE failure", err)
+	}
+	if _, err := c.NewOrder(ctx, limitOrder("90", "1")); err != nil {
+		t.Fatalf("order within the band: %v", err)
+	}
+}
+
+func TestMaxNumOrdersUsesCachedOpenOrders(t *testing.T) {
+	c, _ := newTestClient(t, WithRateLimiter(nil))
+	ctx := context.Background()
+
+	if _, err := c.GetExchangeInfo(ctx, models.ExchangeInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := c.Rules.Get("BTCUSDT")
+	info.Filters.MaxNumOrders.MaxNumOrders = 2
+
+	if _, err := c.GetOpenOrders(ctx, models.OpenOrdersRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.NewOrder(ctx, limitOrder("100", "1")); err != nil {
+			t.Fatalf("order %d: %v", i, err)
+		}
+	}
+	if _, err := c.NewOrder(ctx, limitOrder("100", "1")); !errors.Is(err, binance.ErrFilterFailure) {
+		t.Fatalf("error = %v, want MAX_NUM_ORDERS failure", err)
+	}
+}
@@ -0,0 +1,34 @@
+package v3
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"testing"
+)
+
+func TestEd25519SignatureIsAccepted(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := binance.NewEd25519Signer(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, s := newTestClient(t, WithSigner(signer))
+	s.PublicKey = public
+
+	if _, err := c.GetAccount(context.Background(), models.AccountRequest{}); err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package v3
+
+import (
+	"context"
+	"fmt"
+	urlib "net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// timeSyncInterval how often the offset to the server clock is calibrated by StartTimeSync
+const timeSyncInterval = 10 * time.Minute
+
+// TimeOffset difference between Binance server clock and the local clock
+func (c *BinanceClient) TimeOffset() time.Duration {
+	return time.Duration(c.timeOffset.Load()) * time.Millisecond
+}
+
+// SyncTime calibrates timestamps of signed requests against /api/v3/time.
+// The server time is compared with the middle of the round trip.
+func (c *BinanceClient) SyncTime(ctx context.Context) error {
+	start := time.Now()
+	response, err := c.GetServerTime(ctx)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+
+	local := start.Add(end.Sub(start) / 2).UnixMilli()
+	c.timeOffset.Store(response.ServerTime - local)
+	return nil
+}
+
+// StartTimeSync calibrates the clock offset now and then every 10 minutes in background.
+// Closing done channel or cancelling ctx stops calibration.
+func (c *BinanceClient) StartTimeSync(ctx context.Context) (chan<- struct{}, error) {
+	if err := c.SyncTime(ctx); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(timeSyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.SyncTime(ctx); err != nil {
+					log.Error().Msg(fmt.Sprintf("Failed to sync server time: %s", err))
+				}
+			}
+		}
+	}()
+
+	return done, nil
+}
+
+// resyncTime calibrates the clock after a request was rejected with -1021
+func (c *BinanceClient) resyncTime() {
+	if !c.timeSyncing.CompareAndSwap(false, true) {
+		return
+	}
+	defer c.timeSyncing.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := c.SyncTime(ctx); err != nil {
+		log.Error().Msg(fmt.Sprintf("Failed to sync server time: %s", err))
+	}
+}
+
+// setTimestamp adds timestamp and recvWindow to signed request params which do not set them
+func (c *BinanceClient) setTimestamp(q urlib.Values) {
+	if q.Get("timestamp") == "" {
+		q.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+c.timeOffset.Load(), 10))
+	}
+	if q.Get("recvWindow") == "" && c.RecvWindow > 0 {
+		q.Set("recvWindow", strconv.FormatInt(c.RecvWindow, 10))
+	}
+}
@@ -1,18 +1,52 @@
 package v3
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/risk"
+	"net/http"
+	"net/url"
+	"strings"
 )
 
-func signature(message, secret string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write([]byte(message))
-	signingKey := fmt.Sprintf("%x", mac.Sum(nil))
-	return signingKey
-}
+// securityType defines what an endpoint requires to be called
+type securityType int
+
+const (
+	securityNone   securityType = iota // public endpoint
+	securityAPIKey                     // X-MBX-APIKEY header only
+	securitySigned                     // X-MBX-APIKEY header and signature of BinanceClient.Signer
+)
 
 func (c *BinanceClient) buildURL(endpoint string) string {
 	return fmt.Sprintf("%s%s", c.BaseURL, endpoint)
 }
+
+// endpointPath path of the request relative to BaseURL, e.g. /api/v3/order when BaseURL has a path prefix
+func (c *BinanceClient) endpointPath(u *url.URL) string {
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return u.Path
+	}
+	return strings.TrimPrefix(u.Path, strings.TrimSuffix(base.Path, "/"))
+}
+
+// newAPIError parses Binance error response, the raw body is kept as message if it is not JSON
+func newAPIError(response *http.Response, data []byte) error {
+	apiErr := &binance.APIError{}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Msg == "" {
+		apiErr.Msg = string(data)
+	}
+	apiErr.HTTPStatus = response.StatusCode
+	apiErr.RetryAfter = parseRetryAfter(response.Header)
+	return apiErr
+}
+
+// checkRisk passes the order through the risk checker before it is sent
+func (c *BinanceClient) checkRisk(o risk.Order) error {
+	if c.Risk == nil {
+		return nil
+	}
+	return c.Risk.Check(o)
+}
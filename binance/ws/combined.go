@@ -0,0 +1,355 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultMaxStreamsPerConnection = 200 // Binance allows up to 1024 streams on a single connection
+	requestTimeout                 = 10 * time.Second
+	messageInterval                = 200 * time.Millisecond // Binance limits incoming messages to 5 per second
+)
+
+var (
+	errNotConnected   = errors.New("websocket is not connected")
+	errConnectionLost = errors.New("connection is lost")
+)
+
+// request is a live subscribing message of combined stream connection
+type request struct {
+	Method string   `json:"method"`
+	Params []string `json:"params,omitempty"`
+	ID     int64    `json:"id"`
+}
+
+// message is either a response to the request or a stream payload wrapped into {"stream":..,"data":..}
+type message struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Code   int             `json:"code"`
+	Msg    string          `json:"msg"`
+	Error  *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func (m *message) err(method string) error {
+	switch {
+	case m.Error != nil:
+		return fmt.Errorf("%s failed with code %d: %s", method, m.Error.Code, m.Error.Msg)
+	case m.Msg != "":
+		return fmt.Errorf("%s failed with code %d: %s", method, m.Code, m.Msg)
+	}
+	return nil
+}
+
+// route is a handler of the streams subscribed together
+type route struct {
+	handler func(message []byte) error
+}
+
+// connection is a supervised combined stream connection which is re-established until it is stopped
+type connection struct {
+	baseURL string
+
+	mu         sync.Mutex
+	conn       *websocket.Conn     // nil while the connection is re-established
+	routes     map[string][]*route // handlers by stream name
+	active     map[string]bool     // streams Binance sends on the current conn
+	pending    map[int64]chan *message
+	nextID     int64
+	supervised bool // dialed once, supervise re-establishes it from then on
+	stopped    bool
+	stopCh     chan struct{}
+
+	dialMu sync.Mutex // the first dial is made by one subscriber, others wait for it
+
+	writeMu   sync.Mutex
+	lastWrite time.Time
+}
+
+func newConnection(baseURL string) *connection {
+	return &connection{
+		baseURL: baseURL,
+		routes:  make(map[string][]*route),
+		active:  make(map[string]bool),
+		pending: make(map[int64]chan *message),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// url of combined stream which subscribes the streams on connect
+func (s *connection) url(streams []string) string {
+	return fmt.Sprintf("%s%s?streams=%s", s.baseURL, combinedStream, strings.Join(streams, "/"))
+}
+
+func (s *connection) streams() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	streams := make([]string, 0, len(s.routes))
+	for stream := range s.routes {
+		streams = append(streams, stream)
+	}
+	sort.Strings(streams)
+	return streams
+}
+
+func (s *connection) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.routes)
+}
+
+func (s *connection) connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+func (s *connection) isSupervised() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.supervised
+}
+
+// inactive returns routed streams of the list which the current conn does not carry
+func (s *connection) inactive(streams []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var inactive []string
+	for _, stream := range streams {
+		if len(s.routes[stream]) > 0 && !s.active[stream] {
+			inactive = append(inactive, stream)
+		}
+	}
+	return inactive
+}
+
+func (s *connection) routed(stream string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.routes[stream]) > 0
+}
+
+// setActive marks streams subscribed on the current conn, or unsubscribed
+func (s *connection) setActive(streams []string, active bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stream := range streams {
+		if active {
+			s.active[stream] = true
+		} else {
+			delete(s.active, stream)
+		}
+	}
+}
+
+// addRoute routes streams to the handler and returns streams which were not routed before
+func (s *connection) addRoute(streams []string, r *route) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var added []string
+	for _, stream := range streams {
+		if len(s.routes[stream]) == 0 {
+			added = append(added, stream)
+		}
+		s.routes[stream] = append(s.routes[stream], r)
+	}
+	return added
+}
+
+// removeRoute removes the handler of the streams and returns streams which are not routed anymore
+func (s *connection) removeRoute(streams []string, r *route) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []string
+	for _, stream := range streams {
+		routes := s.routes[stream]
+		for i := range routes {
+			if routes[i] == r {
+				routes = append(routes[:i], routes[i+1:]...)
+				break
+			}
+		}
+
+		if len(routes) == 0 {
+			delete(s.routes, stream)
+			removed = append(removed, stream)
+		} else {
+			s.routes[stream] = routes
+		}
+	}
+	return removed
+}
+
+// setConn makes conn carrying the streams of its url the current one
+func (s *connection) setConn(conn *websocket.Conn, streams []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		conn.Close()
+		return false
+	}
+	s.conn = conn
+	s.supervised = true
+	s.active = make(map[string]bool, len(streams))
+	for _, stream := range streams {
+		s.active[stream] = true
+	}
+	return true
+}
+
+// clearConn forgets the failed conn, streams subscribed meanwhile wait for the next one
+func (s *connection) clearConn(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == conn {
+		s.conn = nil
+		s.active = make(map[string]bool)
+	}
+}
+
+func (s *connection) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *connection) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.stopCh)
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// request sends a live method to Binance and waits for the response with the same id
+func (s *connection) request(ctx context.Context, method string, params []string) (json.RawMessage, error) {
+	reply := make(chan *message, 1)
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.pending[id] = reply
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	if err := s.write(request{Method: method, Params: params, ID: id}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case m, ok := <-reply:
+		if !ok {
+			return nil, fmt.Errorf("%s failed: %w", method, errConnectionLost)
+		}
+		if err := m.err(method); err != nil {
+			return nil, err
+		}
+		return m.Result, nil
+	case <-time.After(requestTimeout):
+		return nil, fmt.Errorf("%s request timed out", method)
+	case <-s.stopCh:
+		return nil, fmt.Errorf("%s failed: connection is closed", method)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *connection) write(v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if wait := time.Until(s.lastWrite.Add(messageInterval)); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return errNotConnected
+	}
+
+	s.lastWrite = time.Now()
+	return conn.WriteJSON(v)
+}
+
+// failPending releases callers waiting for responses which will never come
+func (s *connection) failPending() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, reply := range s.pending {
+		close(reply)
+		delete(s.pending, id)
+	}
+}
+
+// dispatch routes stream payload to its handlers or passes response to the waiting request
+func (s *connection) dispatch(data []byte) error {
+	m := new(message)
+	if err := json.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("error json parsing %s", err.Error())
+	}
+
+	if m.Stream != "" {
+		s.mu.Lock()
+		routes := make([]*route, len(s.routes[m.Stream]))
+		copy(routes, s.routes[m.Stream])
+		s.mu.Unlock()
+
+		for _, r := range routes {
+			if err := r.handler(m.Data); err != nil {
+				log.Println("WebSocket handler error:", err)
+			}
+		}
+		return nil
+	}
+
+	if m.ID != nil {
+		s.mu.Lock()
+		reply, ok := s.pending[*m.ID]
+		delete(s.pending, *m.ID)
+		s.mu.Unlock()
+
+		if ok {
+			reply <- m
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unexpected message %s", data)
+}
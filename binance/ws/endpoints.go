@@ -1,5 +1,57 @@
 package ws
 
+import (
+	"fmt"
+	"strings"
+)
+
 const (
-	depth = "@depth"
+	combinedStream = "/stream"
+
+	// Live subscribing methods
+	subscribeMethod         = "SUBSCRIBE"
+	unsubscribeMethod       = "UNSUBSCRIBE"
+	listSubscriptionsMethod = "LIST_SUBSCRIPTIONS"
+
+	// Market streams
+	depth          = "@depth"
+	depth100ms     = "@depth@100ms"
+	trade          = "@trade"
+	aggTrade       = "@aggTrade"
+	kline          = "@kline_"
+	miniTicker     = "@miniTicker"
+	ticker         = "@ticker"
+	rollingTicker  = "@ticker_"
+	bookTicker     = "@bookTicker"
+	allMiniTickers = "!miniTicker@arr"
+	allTickers     = "!ticker@arr"
+	allRolling     = "!ticker_%s@arr"
+)
+
+var (
+	klineIntervals = []string{"1s", "1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w", "1M"}
+	tickerWindows  = []string{"1h", "4h", "1d"}
+	depthLevels    = []int{5, 10, 20}
 )
+
+// DepthStream is the name of diff depth stream of the symbol
+func DepthStream(symbol string) string {
+	return strings.ToLower(symbol) + depth
+}
+
+func partialDepthStream(symbol string, levels int) string {
+	return fmt.Sprintf("%s%s%d", strings.ToLower(symbol), depth, levels)
+}
+
+func symbolStream(symbol, stream string) string {
+	return strings.ToLower(symbol) + stream
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,83 +1,258 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"gateaway/binance"
 	"gateaway/binance/ws/models"
 	"github.com/gorilla/websocket"
 	"log"
+	"sort"
+	"sync"
+	"time"
 )
 
 type BinanceWsClient struct {
-	APIKey        string
-	Secret        string
-	baseURL       string
-	connections   *websocket.Conn
-	subscriptions map[string]*websocket.Conn
-	close         chan struct{} // channel to close connection
+	BaseURL                 string // stream endpoint, connections opened later use the new value
+	APIKey                  string
+	Secret                  string
+	ReconnectMinDelay       time.Duration // delay before the first reconnect attempt
+	ReconnectMaxDelay       time.Duration // limit of exponential reconnect delay
+	ConnectionLifetime      time.Duration // connection is re-established before Binance drops it
+	MaxStreamsPerConnection int           // streams are spread over connections not to exceed the limit
+	mu                      sync.Mutex
+	connections             []*connection
+	subscriptions           map[string]*connection // connection carrying the stream
+	handlersMu              sync.Mutex
+	connectionHandlers      []ConnectionHandler
 }
 
-func NewBinanceWsClient(apiKey, secretKey string) *BinanceWsClient {
-	return &BinanceWsClient{
-		baseURL: "wss://stream.binance.com:9443/ws/",
-		APIKey:  apiKey,
-		Secret:  secretKey,
+// NewBinanceWsClient creates a client of production streams unless options select another environment
+func NewBinanceWsClient(apiKey, secretKey string, opts ...Option) *BinanceWsClient {
+	c := &BinanceWsClient{
+		BaseURL:                 binance.Production.StreamURL,
+		APIKey:                  apiKey,
+		Secret:                  secretKey,
+		ReconnectMinDelay:       defaultReconnectMinDelay,
+		ReconnectMaxDelay:       defaultReconnectMaxDelay,
+		ConnectionLifetime:      defaultConnectionLifetime,
+		MaxStreamsPerConnection: defaultMaxStreamsPerConnection,
+		subscriptions:           make(map[string]*connection),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func (c *BinanceWsClient) subscribe(url string, handler func(message []byte) error) (error, chan<- struct{}) {
-	done := make(chan struct{})
+// subscribe routes streams to the handler until ctx is cancelled or done is closed.
+// Streams share combined stream connections, a new connection is opened only when existing ones are full.
+// The client lock is held only while streams are assigned to connections, not while they are subscribed.
+func (c *BinanceWsClient) subscribe(ctx context.Context, streams []string, handler func(message []byte) error) (error, chan<- struct{}) {
+	r := &route{handler: handler}
 
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-	if err != nil {
-		return err, nil
+	c.mu.Lock()
+	// Group streams by the connections carrying them
+	batches := make(map[*connection][]string)
+	added := make(map[*connection][]string)
+	var order []*connection
+	for _, stream := range streams {
+		s, ok := c.subscriptions[stream]
+		if !ok {
+			s = c.available(batches)
+		}
+		if _, ok := batches[s]; !ok {
+			order = append(order, s)
+		}
+		batches[s] = append(batches[s], stream)
 	}
+	for _, s := range order {
+		added[s] = s.addRoute(batches[s], r)
+		for _, stream := range added[s] {
+			c.subscriptions[stream] = s
+		}
+	}
+	c.mu.Unlock()
 
-	go func() {
-		// Wait to close connection in a separate goroutine while getting updates from WS
-		go func() {
-			<-done
-			defer conn.Close()
-		}()
-
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Println("WebSocket read error:", err)
-				return
-			}
-			if err := handler(message); err != nil {
-				log.Println("WebSocket handler error:", err)
-				return
-			}
-			err = handler(message)
-			if err != nil {
-				fmt.Errorf(err.Error())
-				// TODO: better handling
+	for _, s := range order {
+		if err := c.attach(ctx, s, added[s]); err != nil {
+			for _, s := range order {
+				c.detach(s, batches[s], r)
 			}
+			return err, nil
+		}
+	}
+
+	done := make(chan struct{})
+
+	// Wait to close subscription in a separate goroutine while getting updates from WS
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+
+		for _, s := range order {
+			c.detach(s, batches[s], r)
 		}
 	}()
 
 	return nil, done
 }
 
+// available returns a connection which can carry one more stream, new one if all are full
+func (c *BinanceWsClient) available(batches map[*connection][]string) *connection {
+	for _, s := range c.connections {
+		if s.size()+len(batches[s]) < c.MaxStreamsPerConnection {
+			return s
+		}
+	}
+
+	s := newConnection(c.BaseURL)
+	c.connections = append(c.connections, s)
+	return s
+}
+
+// attach subscribes the streams newly routed to the connection on Binance, dialing it the first time
+func (c *BinanceWsClient) attach(ctx context.Context, s *connection, added []string) error {
+	if err := c.connect(ctx, s); err != nil {
+		return err
+	}
+	return c.activate(ctx, s, added)
+}
+
+// connect dials the connection the first time, it is re-established by supervise from then on
+func (c *BinanceWsClient) connect(ctx context.Context, s *connection) error {
+	s.dialMu.Lock()
+	defer s.dialMu.Unlock()
+
+	if s.isSupervised() {
+		return nil
+	}
+
+	streams := s.streams()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, s.url(streams), nil)
+	if err != nil {
+		return err
+	}
+	if !s.setConn(conn, streams) {
+		return errConnectionLost
+	}
+	go c.supervise(s, conn)
+	return nil
+}
+
+// activate subscribes streams which the current conn does not carry yet.
+// Streams of a connection being re-established are queued, supervise subscribes them once it is back.
+func (c *BinanceWsClient) activate(ctx context.Context, s *connection, streams []string) error {
+	inactive := s.inactive(streams)
+	if len(inactive) == 0 || !s.connected() {
+		return nil
+	}
+
+	if _, err := s.request(ctx, subscribeMethod, inactive); err != nil {
+		if errors.Is(err, errNotConnected) || errors.Is(err, errConnectionLost) {
+			return nil
+		}
+		return err
+	}
+	s.setActive(inactive, true)
+	return nil
+}
+
+// detach removes the handler of the streams, unsubscribing streams nobody listens to anymore
+func (c *BinanceWsClient) detach(s *connection, streams []string, r *route) {
+	c.mu.Lock()
+	removed := s.removeRoute(streams, r)
+	for _, stream := range removed {
+		if c.subscriptions[stream] == s {
+			delete(c.subscriptions, stream)
+		}
+	}
+
+	empty := s.size() == 0
+	if empty {
+		for i := range c.connections {
+			if c.connections[i] == s {
+				c.connections = append(c.connections[:i], c.connections[i+1:]...)
+				break
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if empty {
+		s.stop()
+		return
+	}
+
+	// Streams routed again meanwhile stay subscribed
+	var unused []string
+	for _, stream := range removed {
+		if !s.routed(stream) {
+			unused = append(unused, stream)
+		}
+	}
+	if len(unused) > 0 && s.connected() {
+		if _, err := s.request(context.Background(), unsubscribeMethod, unused); err != nil {
+			log.Println("WebSocket unsubscribe error:", err)
+			return
+		}
+		s.setActive(unused, false)
+	}
+}
+
+// ListSubscriptions returns streams subscribed on Binance side over all connections
+func (c *BinanceWsClient) ListSubscriptions(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	connections := make([]*connection, len(c.connections))
+	copy(connections, c.connections)
+	c.mu.Unlock()
+
+	var streams []string
+	for _, s := range connections {
+		result, err := s.request(ctx, listSubscriptionsMethod, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []string
+		if err := json.Unmarshal(result, &list); err != nil {
+			return nil, err
+		}
+		streams = append(streams, list...)
+	}
+
+	sort.Strings(streams)
+	return streams, nil
+}
+
 type handlerEvent func(e *models.DepthEvent)
 
-func (c *BinanceWsClient) serveDepth(url string, handler handlerEvent) (error, chan<- struct{}) {
+func (c *BinanceWsClient) serveDepth(ctx context.Context, streams []string, handler handlerEvent) (error, chan<- struct{}) {
 	wsHandler := func(event []byte) error {
 		depthEventRaw := new(models.DepthEventRaw)
 		if err := json.Unmarshal(event, depthEventRaw); err != nil {
-			fmt.Errorf("error json parsing %s", err.Error())
-			// TODO: better handling
+			return fmt.Errorf("error json parsing %s", err.Error())
 		}
 		depthEvent := depthEventRaw.Transform()
 		handler(depthEvent)
 		return nil
 	}
-	return c.subscribe(url, wsHandler)
+	return c.subscribe(ctx, streams, wsHandler)
+}
+
+func (c *BinanceWsClient) SubscribeDepth(ctx context.Context, symbol string, handler handlerEvent) (error, chan<- struct{}) {
+	return c.serveDepth(ctx, []string{DepthStream(symbol)}, handler)
 }
 
-func (c *BinanceWsClient) SubscribeDepth(symbol string, handler handlerEvent) (error, chan<- struct{}) {
-	url := fmt.Sprintf("%s%s%s", c.baseURL, symbol, depth)
-	return c.serveDepth(url, handler)
+// SubscribeCombinedDepth subscribes diff depth of many symbols sharing as few connections as possible
+func (c *BinanceWsClient) SubscribeCombinedDepth(ctx context.Context, symbols []string, handler handlerEvent) (error, chan<- struct{}) {
+	streams := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		streams = append(streams, DepthStream(symbol))
+	}
+	return c.serveDepth(ctx, streams, handler)
 }
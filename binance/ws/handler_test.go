@@ -0,0 +1,123 @@
+package ws
+
+import (
+	"context"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/ws/models"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T) (*BinanceWsClient, *binancetest.Server) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+
+	c := NewBinanceWsClient("key", "secret", WithBaseURL(s.WsURL()))
+	c.ReconnectMinDelay = 10 * time.Millisecond
+	return c, s
+}
+
+// eventually polls the condition until it holds or a second passes
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, events <-chan *models.DepthEvent) *models.DepthEvent {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no depth event received")
+		return nil
+	}
+}
+
+func TestSubscribeDepthPassesUpdateIDs(t *testing.T) {
+	c, s := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan *models.DepthEvent, 10)
+	if err, _ := c.SubscribeDepth(ctx, "BTCUSDT", func(e *models.DepthEvent) { events <- e }); err != nil {
+		t.Fatal(err)
+	}
+
+	s.PushDepth("BTCUSDT", 10, 12, [][2]string{{"99.5", "1.25"}}, [][2]string{{"101", "0"}})
+	// Gaps are passed through, the book detects them
+	s.PushDepth("BTCUSDT", 20, 20, nil, nil)
+
+	e := receive(t, events)
+	if e.Symbol != "BTCUSDT" || e.FirstUpdateID != 10 || e.LastUpdateID != 12 {
+		t.Fatalf("event = %+v, want U 10 u 12", e)
+	}
+	if len(e.Bids) != 1 || e.Bids[0].Price.String() != "99.5" || e.Bids[0].Quantity.String() != "1.25" {
+		t.Fatalf("bids = %+v, want 99.5 x 1.25", e.Bids)
+	}
+	if len(e.Asks) != 1 || !e.Asks[0].Quantity.IsZero() {
+		t.Fatalf("asks = %+v, want removed level 101", e.Asks)
+	}
+	if e := receive(t, events); e.FirstUpdateID != 20 {
+		t.Fatalf("event after the gap U = %d, want 20", e.FirstUpdateID)
+	}
+}
+
+func TestStreamsShareConnection(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err, _ := c.SubscribeCombinedDepth(ctx, []string{"BTCUSDT", "ETHUSDT"}, func(*models.DepthEvent) {}); err != nil {
+		t.Fatal(err)
+	}
+	c.MaxStreamsPerConnection = 3
+	if err, _ := c.SubscribeDepth(ctx, "BNBUSDT", func(*models.DepthEvent) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err, _ := c.SubscribeDepth(ctx, "XRPUSDT", func(*models.DepthEvent) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	c.mu.Lock()
+	connections := len(c.connections)
+	c.mu.Unlock()
+	if connections != 2 {
+		t.Fatalf("%d connections, want 2 with 3 streams per connection", connections)
+	}
+
+	streams, err := c.ListSubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 4 {
+		t.Fatalf("subscriptions = %v, want 4 streams", streams)
+	}
+}
+
+func TestDoneUnsubscribesStream(t *testing.T) {
+	c, s := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err, _ := c.SubscribeDepth(ctx, "BTCUSDT", func(*models.DepthEvent) {}); err != nil {
+		t.Fatal(err)
+	}
+	err, done := c.SubscribeDepth(ctx, "ETHUSDT", func(*models.DepthEvent) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(done)
+	eventually(t, "stream is still subscribed after done is closed", func() bool {
+		streams := s.Subscriptions()
+		return len(streams) == 1 && streams[0] == DepthStream("BTCUSDT")
+	})
+}
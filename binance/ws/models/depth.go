@@ -1,12 +1,12 @@
 package models
 
 import (
-	"strconv"
+	"github.com/shopspring/decimal"
 )
 
 type OrderBook struct {
-	Price    float32
-	Quantity float32
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
 }
 
 type DepthEventRaw struct {
@@ -29,7 +29,7 @@ type DepthEvent struct {
 	Asks          []OrderBook `json:"a"`
 }
 
-// Transform changes data structure where orderbook is `float`
+// Transform changes data structure where orderbook is `decimal.Decimal`
 func (event *DepthEventRaw) Transform() *DepthEvent {
 	output := &DepthEvent{
 		Event:         event.Event,
@@ -40,15 +40,51 @@ func (event *DepthEventRaw) Transform() *DepthEvent {
 	}
 
 	for _, bid := range event.Bids {
-		price, _ := strconv.ParseFloat(bid[0], 64)
-		quantity, _ := strconv.ParseFloat(bid[1], 64)
-		output.Bids = append(output.Bids, OrderBook{Price: float32(price), Quantity: float32(quantity)})
+		price, _ := decimal.NewFromString(bid[0])
+		quantity, _ := decimal.NewFromString(bid[1])
+		output.Bids = append(output.Bids, OrderBook{Price: price, Quantity: quantity})
 	}
 
 	for _, ask := range event.Asks {
-		price, _ := strconv.ParseFloat(ask[0], 64)
-		quantity, _ := strconv.ParseFloat(ask[1], 64)
-		output.Asks = append(output.Asks, OrderBook{Price: float32(price), Quantity: float32(quantity)})
+		price, _ := decimal.NewFromString(ask[0])
+		quantity, _ := decimal.NewFromString(ask[1])
+		output.Asks = append(output.Asks, OrderBook{Price: price, Quantity: quantity})
+	}
+
+	return output
+}
+
+type PartialDepthEventRaw struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// PartialDepthEvent top levels of <symbol>@depth<levels> stream
+type PartialDepthEvent struct {
+	Symbol       string
+	LastUpdateID int64
+	Bids         []OrderBook
+	Asks         []OrderBook
+}
+
+// Transform changes data structure where orderbook is `decimal.Decimal`, symbol is taken from the stream name
+func (event *PartialDepthEventRaw) Transform(symbol string) *PartialDepthEvent {
+	output := &PartialDepthEvent{
+		Symbol:       symbol,
+		LastUpdateID: event.LastUpdateID,
+	}
+
+	for _, bid := range event.Bids {
+		price, _ := decimal.NewFromString(bid[0])
+		quantity, _ := decimal.NewFromString(bid[1])
+		output.Bids = append(output.Bids, OrderBook{Price: price, Quantity: quantity})
+	}
+
+	for _, ask := range event.Asks {
+		price, _ := decimal.NewFromString(ask[0])
+		quantity, _ := decimal.NewFromString(ask[1])
+		output.Asks = append(output.Asks, OrderBook{Price: price, Quantity: quantity})
 	}
 
 	return output
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// KlineEvent current kline of <symbol>@kline_<interval> stream
+type KlineEvent struct {
+	Event  string `json:"e"`
+	Time   int64  `json:"E"`
+	Symbol string `json:"s"`
+	Kline  Kline  `json:"k"`
+}
+
+type Kline struct {
+	StartTime            int64           `json:"t"`
+	CloseTime            int64           `json:"T"`
+	Symbol               string          `json:"s"`
+	Interval             string          `json:"i"`
+	FirstTradeID         int64           `json:"f"`
+	LastTradeID          int64           `json:"L"`
+	Open                 decimal.Decimal `json:"o"`
+	Close                decimal.Decimal `json:"c"`
+	High                 decimal.Decimal `json:"h"`
+	Low                  decimal.Decimal `json:"l"`
+	Volume               decimal.Decimal `json:"v"`
+	TradeNum             int64           `json:"n"`
+	IsFinal              bool            `json:"x"` // kline is closed
+	QuoteVolume          decimal.Decimal `json:"q"`
+	ActiveBuyVolume      decimal.Decimal `json:"V"`
+	ActiveBuyQuoteVolume decimal.Decimal `json:"Q"`
+}
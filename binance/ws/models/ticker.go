@@ -0,0 +1,74 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// MiniTickerEvent 24hr rolling window mini-ticker of <symbol>@miniTicker and !miniTicker@arr streams
+type MiniTickerEvent struct {
+	Event       string          `json:"e"`
+	Time        int64           `json:"E"`
+	Symbol      string          `json:"s"`
+	Close       decimal.Decimal `json:"c"`
+	Open        decimal.Decimal `json:"o"`
+	High        decimal.Decimal `json:"h"`
+	Low         decimal.Decimal `json:"l"`
+	Volume      decimal.Decimal `json:"v"`
+	QuoteVolume decimal.Decimal `json:"q"`
+}
+
+// TickerEvent 24hr rolling window ticker of <symbol>@ticker and !ticker@arr streams
+type TickerEvent struct {
+	Event              string          `json:"e"`
+	Time               int64           `json:"E"`
+	Symbol             string          `json:"s"`
+	PriceChange        decimal.Decimal `json:"p"`
+	PriceChangePercent decimal.Decimal `json:"P"`
+	WeightedAvgPrice   decimal.Decimal `json:"w"`
+	PrevClosePrice     decimal.Decimal `json:"x"` // first trade(F)-1 price
+	LastPrice          decimal.Decimal `json:"c"`
+	LastQty            decimal.Decimal `json:"Q"`
+	BidPrice           decimal.Decimal `json:"b"`
+	BidQty             decimal.Decimal `json:"B"`
+	AskPrice           decimal.Decimal `json:"a"`
+	AskQty             decimal.Decimal `json:"A"`
+	Open               decimal.Decimal `json:"o"`
+	High               decimal.Decimal `json:"h"`
+	Low                decimal.Decimal `json:"l"`
+	Volume             decimal.Decimal `json:"v"`
+	QuoteVolume        decimal.Decimal `json:"q"`
+	OpenTime           int64           `json:"O"`
+	CloseTime          int64           `json:"C"`
+	FirstTradeID       int64           `json:"F"`
+	LastTradeID        int64           `json:"L"`
+	TradeNum           int64           `json:"n"`
+}
+
+// RollingWindowTickerEvent ticker of <symbol>@ticker_<window> and !ticker_<window>@arr streams
+type RollingWindowTickerEvent struct {
+	Event              string          `json:"e"` // 1hTicker, 4hTicker or 1dTicker
+	Time               int64           `json:"E"`
+	Symbol             string          `json:"s"`
+	PriceChange        decimal.Decimal `json:"p"`
+	PriceChangePercent decimal.Decimal `json:"P"`
+	Open               decimal.Decimal `json:"o"`
+	High               decimal.Decimal `json:"h"`
+	Low                decimal.Decimal `json:"l"`
+	LastPrice          decimal.Decimal `json:"c"`
+	WeightedAvgPrice   decimal.Decimal `json:"w"`
+	Volume             decimal.Decimal `json:"v"`
+	QuoteVolume        decimal.Decimal `json:"q"`
+	OpenTime           int64           `json:"O"`
+	CloseTime          int64           `json:"C"`
+	FirstTradeID       int64           `json:"F"`
+	LastTradeID        int64           `json:"L"`
+	TradeNum           int64           `json:"n"`
+}
+
+// BookTickerEvent best bid and ask of <symbol>@bookTicker stream
+type BookTickerEvent struct {
+	UpdateID int64           `json:"u"`
+	Symbol   string          `json:"s"`
+	BidPrice decimal.Decimal `json:"b"`
+	BidQty   decimal.Decimal `json:"B"`
+	AskPrice decimal.Decimal `json:"a"`
+	AskQty   decimal.Decimal `json:"A"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// TradeEvent raw trade of <symbol>@trade stream
+type TradeEvent struct {
+	Event         string          `json:"e"`
+	Time          int64           `json:"E"`
+	Symbol        string          `json:"s"`
+	TradeID       int64           `json:"t"`
+	Price         decimal.Decimal `json:"p"`
+	Quantity      decimal.Decimal `json:"q"`
+	BuyerOrderID  int64           `json:"b"`
+	SellerOrderID int64           `json:"a"`
+	TradeTime     int64           `json:"T"`
+	IsBuyerMaker  bool            `json:"m"`
+	Ignore        bool            `json:"M"`
+}
+
+// AggTradeEvent trade aggregated for a single taker order of <symbol>@aggTrade stream
+type AggTradeEvent struct {
+	Event        string          `json:"e"`
+	Time         int64           `json:"E"`
+	Symbol       string          `json:"s"`
+	AggTradeID   int64           `json:"a"`
+	Price        decimal.Decimal `json:"p"`
+	Quantity     decimal.Decimal `json:"q"`
+	FirstTradeID int64           `json:"f"`
+	LastTradeID  int64           `json:"l"`
+	TradeTime    int64           `json:"T"`
+	IsBuyerMaker bool            `json:"m"`
+	Ignore       bool            `json:"M"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"gateaway/binance/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// UserDataEvent is one of the user data stream events, only the field matching Event is set
+type UserDataEvent struct {
+	Event            string
+	Time             int64
+	ExecutionReport  *ExecutionReportEvent
+	AccountPosition  *OutboundAccountPositionEvent
+	BalanceUpdate    *BalanceUpdateEvent
+	ListStatus       *ListStatusEvent
+	ListenKeyExpired bool // stream is closed, new listenKey must be created
+}
+
+func (e *UserDataEvent) UnmarshalJSON(data []byte) error {
+	header := struct {
+		Event string `json:"e"`
+		Time  int64  `json:"E"`
+	}{}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	e.Event = header.Event
+	e.Time = header.Time
+
+	switch header.Event {
+	case "executionReport":
+		e.ExecutionReport = new(ExecutionReportEvent)
+		return json.Unmarshal(data, e.ExecutionReport)
+	case "outboundAccountPosition":
+		e.AccountPosition = new(OutboundAccountPositionEvent)
+		return json.Unmarshal(data, e.AccountPosition)
+	case "balanceUpdate":
+		e.BalanceUpdate = new(BalanceUpdateEvent)
+		return json.Unmarshal(data, e.BalanceUpdate)
+	case "listStatus":
+		e.ListStatus = new(ListStatusEvent)
+		return json.Unmarshal(data, e.ListStatus)
+	case "listenKeyExpired":
+		e.ListenKeyExpired = true
+	}
+
+	return nil
+}
+
+// ExecutionReportEvent order update
+type ExecutionReportEvent struct {
+	Event                   string                         `json:"e"`
+	Time                    int64                          `json:"E"`
+	Symbol                  string                         `json:"s"`
+	ClientOrderID           string                         `json:"c"`
+	Side                    models.Side                    `json:"S"`
+	Type                    models.OrderType               `json:"o"`
+	TimeInForce             models.TimeInForce             `json:"f"`
+	Quantity                decimal.Decimal                `json:"q"`
+	Price                   decimal.Decimal                `json:"p"`
+	StopPrice               decimal.Decimal                `json:"P"`
+	IcebergQty              decimal.Decimal                `json:"F"`
+	OrderListID             int64                          `json:"g"`
+	OrigClientOrderID       string                         `json:"C"` // original client order id of the canceled order
+	ExecutionType           models.ExecutionType           `json:"x"`
+	Status                  models.OrderStatus             `json:"X"`
+	RejectReason            string                         `json:"r"`
+	OrderID                 int64                          `json:"i"`
+	LastExecutedQty         decimal.Decimal                `json:"l"`
+	CumulativeFilledQty     decimal.Decimal                `json:"z"`
+	LastExecutedPrice       decimal.Decimal                `json:"L"`
+	Commission              decimal.Decimal                `json:"n"`
+	CommissionAsset         string                         `json:"N"`
+	TransactTime            int64                          `json:"T"`
+	TradeID                 int64                          `json:"t"`
+	PreventedMatchID        int64                          `json:"v"`
+	Ignore                  int64                          `json:"I"`
+	IsWorking               bool                           `json:"w"`
+	IsMaker                 bool                           `json:"m"`
+	IgnoreM                 bool                           `json:"M"`
+	CreateTime              int64                          `json:"O"`
+	CumulativeQuoteQty      decimal.Decimal                `json:"Z"`
+	LastQuoteQty            decimal.Decimal                `json:"Y"`
+	QuoteOrderQty           decimal.Decimal                `json:"Q"`
+	WorkingTime             int64                          `json:"W"`
+	SelfTradePreventionMode models.SelfTradePreventionMode `json:"V"`
+	TrailingDelta           int64                          `json:"d"`
+	TrailingTime            int64                          `json:"D"`
+	StrategyID              int64                          `json:"j"`
+	StrategyType            int64                          `json:"J"`
+	PreventedQuantity       decimal.Decimal                `json:"A"`
+	LastPreventedQuantity   decimal.Decimal                `json:"B"`
+	TradeGroupID            int64                          `json:"u"`
+	CounterOrderID          int64                          `json:"U"`
+	MatchType               string                         `json:"b"`
+	AllocationID            int64                          `json:"a"`
+	WorkingFloor            string                         `json:"k"`
+	UsedSor                 bool                           `json:"uS"`
+}
+
+// OutboundAccountPositionEvent balances of assets changed by an account update
+type OutboundAccountPositionEvent struct {
+	Event          string `json:"e"`
+	Time           int64  `json:"E"`
+	LastUpdateTime int64  `json:"u"`
+	Balances       []struct {
+		Asset  string          `json:"a"`
+		Free   decimal.Decimal `json:"f"`
+		Locked decimal.Decimal `json:"l"`
+	} `json:"B"`
+}
+
+// BalanceUpdateEvent deposit, withdrawal or transfer of an asset
+type BalanceUpdateEvent struct {
+	Event     string          `json:"e"`
+	Time      int64           `json:"E"`
+	Asset     string          `json:"a"`
+	Delta     decimal.Decimal `json:"d"`
+	ClearTime int64           `json:"T"`
+}
+
+// ListStatusEvent order list (OCO) update
+type ListStatusEvent struct {
+	Event             string                 `json:"e"`
+	Time              int64                  `json:"E"`
+	Symbol            string                 `json:"s"`
+	OrderListID       int64                  `json:"g"`
+	ContingencyType   models.ContingencyType `json:"c"`
+	ListStatusType    models.ListStatusType  `json:"l"`
+	ListOrderStatus   models.ListOrderStatus `json:"L"`
+	ListRejectReason  string                 `json:"r"`
+	ListClientOrderID string                 `json:"C"`
+	TransactionTime   int64                  `json:"T"`
+	Orders            []struct {
+		Symbol        string `json:"s"`
+		OrderID       int64  `json:"i"`
+		ClientOrderID string `json:"c"`
+	} `json:"O"`
+}
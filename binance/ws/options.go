@@ -0,0 +1,22 @@
+package ws
+
+import "gateaway/binance"
+
+// Option configures BinanceWsClient created by NewBinanceWsClient
+type Option func(*BinanceWsClient)
+
+// WithEnvironment connects to streams of the environment, e.g. binance.Testnet
+func WithEnvironment(env binance.Environment) Option {
+	return func(c *BinanceWsClient) {
+		if env.StreamURL != "" {
+			c.BaseURL = env.StreamURL
+		}
+	}
+}
+
+// WithBaseURL connects to a custom stream URL, e.g. binancetest.Server
+func WithBaseURL(baseURL string) Option {
+	return func(c *BinanceWsClient) {
+		c.BaseURL = baseURL
+	}
+}
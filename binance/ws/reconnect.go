@@ -0,0 +1,163 @@
+package ws
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultReconnectMinDelay  = 100 * time.Millisecond
+	defaultReconnectMaxDelay  = 30 * time.Second
+	defaultConnectionLifetime = 23*time.Hour + 30*time.Minute // Binance drops every connection after 24 hours
+)
+
+type ConnectionEventType int
+
+const (
+	// Disconnected connection is lost, messages of its streams are missed until it is re-established
+	Disconnected ConnectionEventType = iota
+	// Reconnected connection is re-established, consumers must re-sync the state built from its streams
+	Reconnected
+)
+
+func (t ConnectionEventType) String() string {
+	switch t {
+	case Disconnected:
+		return "DISCONNECTED"
+	case Reconnected:
+		return "RECONNECTED"
+	}
+	return "UNKNOWN"
+}
+
+// ConnectionEvent notifies consumers about a gap in the streams of a connection
+type ConnectionEvent struct {
+	Type    ConnectionEventType
+	Streams []string // streams carried by the connection, e.g. btcusdt@depth
+	Attempt int      // number of reconnect attempts made
+	Err     error    // reason of disconnect, nil if the connection reached its lifetime
+}
+
+type ConnectionHandler func(e ConnectionEvent)
+
+// OnConnectionEvent registers a handler of connection events,
+// handlers are called in order of registration.
+func (c *BinanceWsClient) OnConnectionEvent(handler ConnectionHandler) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.connectionHandlers = append(c.connectionHandlers, handler)
+}
+
+func (c *BinanceWsClient) notify(e ConnectionEvent) {
+	c.handlersMu.Lock()
+	handlers := make([]ConnectionHandler, len(c.connectionHandlers))
+	copy(handlers, c.connectionHandlers)
+	c.handlersMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(e)
+	}
+}
+
+// backoff returns jittered exponential delay before the reconnect attempt
+func (c *BinanceWsClient) backoff(attempt int) time.Duration {
+	delay := c.ReconnectMinDelay
+	for i := 1; i < attempt && delay < c.ReconnectMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.ReconnectMaxDelay {
+		delay = c.ReconnectMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Half of the delay is random so that clients do not reconnect all at once
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// supervise reads the connection and re-establishes it on failure until it is stopped
+func (c *BinanceWsClient) supervise(s *connection, conn *websocket.Conn) {
+	for {
+		start := time.Now()
+		err := c.read(s, conn)
+		if s.isStopped() {
+			return
+		}
+
+		expired := time.Since(start) >= c.ConnectionLifetime
+		if expired {
+			err = nil
+		}
+		c.notify(ConnectionEvent{Type: Disconnected, Streams: s.streams(), Err: err})
+
+		var streams []string
+		attempt := 0
+		for {
+			attempt++
+
+			// Planned reconnect at the end of the lifetime is done without delay
+			if !expired || attempt > 1 {
+				select {
+				case <-s.stopCh:
+					return
+				case <-time.After(c.backoff(attempt)):
+				}
+			}
+
+			if s.isStopped() {
+				return
+			}
+			// Streams subscribed while the connection was alive or down are restored by the url
+			streams = s.streams()
+			conn, _, err = websocket.DefaultDialer.Dial(s.url(streams), nil)
+			if err == nil {
+				break
+			}
+			log.Println("WebSocket reconnect error:", err)
+		}
+
+		if !s.setConn(conn, streams) {
+			return
+		}
+		c.notify(ConnectionEvent{Type: Reconnected, Streams: s.streams(), Attempt: attempt})
+
+		// Streams routed after the url was built, subscribed once the connection is read again
+		if missing := s.inactive(s.streams()); len(missing) > 0 {
+			go func() {
+				if err := c.activate(context.Background(), s, missing); err != nil {
+					log.Println("WebSocket subscribe error:", err)
+				}
+			}()
+		}
+	}
+}
+
+// read passes messages to the handler until the connection fails or reaches its lifetime
+func (c *BinanceWsClient) read(s *connection, conn *websocket.Conn) error {
+	lifetime := time.AfterFunc(c.ConnectionLifetime, func() {
+		conn.Close()
+	})
+	defer lifetime.Stop()
+	defer s.failPending()
+	// Cleared before pending requests fail, so that their callers see the connection is down
+	defer s.clearConn(conn)
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if !s.isStopped() {
+				log.Println("WebSocket read error:", err)
+			}
+			return err
+		}
+		if err := s.dispatch(message); err != nil {
+			log.Println("WebSocket dispatch error:", err)
+		}
+	}
+}
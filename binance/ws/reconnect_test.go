@@ -0,0 +1,39 @@
+package ws
+
+import (
+	"context"
+	"gateaway/binance/ws/models"
+	"testing"
+	"time"
+)
+
+func TestStreamsAreResubscribedAfterReconnect(t *testing.T) {
+	c, s := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connectionEvents := make(chan ConnectionEvent, 10)
+	c.OnConnectionEvent(func(e ConnectionEvent) { connectionEvents <- e })
+
+	events := make(chan *models.DepthEvent, 10)
+	if err, _ := c.SubscribeDepth(ctx, "BTCUSDT", func(e *models.DepthEvent) { events <- e }); err != nil {
+		t.Fatal(err)
+	}
+
+	s.DropConnections()
+	for _, want := range []ConnectionEventType{Disconnected, Reconnected} {
+		select {
+		case e := <-connectionEvents:
+			if e.Type != want || len(e.Streams) != 1 || e.Streams[0] != DepthStream("BTCUSDT") {
+				t.Fatalf("connection event = %+v, want %s of the depth stream", e, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", want)
+		}
+	}
+
+	s.PushDepth("BTCUSDT", 1, 1, nil, nil)
+	if e := receive(t, events); e.LastUpdateID != 1 {
+		t.Fatalf("event after reconnect u = %d, want 1", e.LastUpdateID)
+	}
+}
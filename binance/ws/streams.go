@@ -0,0 +1,218 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gateaway/binance/ws/models"
+	"strings"
+)
+
+type handlerTrade func(e *models.TradeEvent)
+
+type handlerAggTrade func(e *models.AggTradeEvent)
+
+type handlerKline func(e *models.KlineEvent)
+
+type handlerMiniTicker func(e *models.MiniTickerEvent)
+
+type handlerAllMiniTickers func(e []*models.MiniTickerEvent)
+
+type handlerTicker func(e *models.TickerEvent)
+
+type handlerAllTickers func(e []*models.TickerEvent)
+
+type handlerRollingWindowTicker func(e *models.RollingWindowTickerEvent)
+
+type handlerAllRollingWindowTickers func(e []*models.RollingWindowTickerEvent)
+
+type handlerBookTicker func(e *models.BookTickerEvent)
+
+type handlerPartialDepth func(e *models.PartialDepthEvent)
+
+type handlerUserData func(e *models.UserDataEvent)
+
+// SubscribeTrade raw trade information, each trade has a unique buyer and seller
+func (c *BinanceWsClient) SubscribeTrade(ctx context.Context, symbol string, handler handlerTrade) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		tradeEvent := new(models.TradeEvent)
+		if err := json.Unmarshal(event, tradeEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(tradeEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, trade)}, wsHandler)
+}
+
+// SubscribeAggTrade trades that fill at the time, from the same taker order, with the same price
+func (c *BinanceWsClient) SubscribeAggTrade(ctx context.Context, symbol string, handler handlerAggTrade) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		aggTradeEvent := new(models.AggTradeEvent)
+		if err := json.Unmarshal(event, aggTradeEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(aggTradeEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, aggTrade)}, wsHandler)
+}
+
+// SubscribeKline pushes updates to the current kline of the interval every second
+func (c *BinanceWsClient) SubscribeKline(ctx context.Context, symbol, interval string, handler handlerKline) (error, chan<- struct{}) {
+	if !contains(klineIntervals, interval) {
+		return fmt.Errorf("invalid kline interval %s", interval), nil
+	}
+
+	wsHandler := func(event []byte) error {
+		klineEvent := new(models.KlineEvent)
+		if err := json.Unmarshal(event, klineEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(klineEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, kline+interval)}, wsHandler)
+}
+
+// SubscribeMiniTicker 24hr rolling window mini-ticker statistics of the symbol
+func (c *BinanceWsClient) SubscribeMiniTicker(ctx context.Context, symbol string, handler handlerMiniTicker) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		miniTickerEvent := new(models.MiniTickerEvent)
+		if err := json.Unmarshal(event, miniTickerEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(miniTickerEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, miniTicker)}, wsHandler)
+}
+
+// SubscribeAllMiniTickers mini-tickers of all symbols which changed
+func (c *BinanceWsClient) SubscribeAllMiniTickers(ctx context.Context, handler handlerAllMiniTickers) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		var miniTickerEvents []*models.MiniTickerEvent
+		if err := json.Unmarshal(event, &miniTickerEvents); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(miniTickerEvents)
+		return nil
+	}
+	return c.subscribe(ctx, []string{allMiniTickers}, wsHandler)
+}
+
+// SubscribeTicker 24hr rolling window ticker statistics of the symbol
+func (c *BinanceWsClient) SubscribeTicker(ctx context.Context, symbol string, handler handlerTicker) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		tickerEvent := new(models.TickerEvent)
+		if err := json.Unmarshal(event, tickerEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(tickerEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, ticker)}, wsHandler)
+}
+
+// SubscribeAllTickers 24hr tickers of all symbols which changed
+func (c *BinanceWsClient) SubscribeAllTickers(ctx context.Context, handler handlerAllTickers) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		var tickerEvents []*models.TickerEvent
+		if err := json.Unmarshal(event, &tickerEvents); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(tickerEvents)
+		return nil
+	}
+	return c.subscribe(ctx, []string{allTickers}, wsHandler)
+}
+
+// SubscribeRollingWindowTicker ticker statistics of the symbol over 1h, 4h or 1d window
+func (c *BinanceWsClient) SubscribeRollingWindowTicker(ctx context.Context, symbol, window string, handler handlerRollingWindowTicker) (error, chan<- struct{}) {
+	if !contains(tickerWindows, window) {
+		return fmt.Errorf("invalid ticker window %s", window), nil
+	}
+
+	wsHandler := func(event []byte) error {
+		tickerEvent := new(models.RollingWindowTickerEvent)
+		if err := json.Unmarshal(event, tickerEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(tickerEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, rollingTicker+window)}, wsHandler)
+}
+
+// SubscribeAllRollingWindowTickers rolling window tickers of all symbols which changed
+func (c *BinanceWsClient) SubscribeAllRollingWindowTickers(ctx context.Context, window string, handler handlerAllRollingWindowTickers) (error, chan<- struct{}) {
+	if !contains(tickerWindows, window) {
+		return fmt.Errorf("invalid ticker window %s", window), nil
+	}
+
+	wsHandler := func(event []byte) error {
+		var tickerEvents []*models.RollingWindowTickerEvent
+		if err := json.Unmarshal(event, &tickerEvents); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(tickerEvents)
+		return nil
+	}
+	return c.subscribe(ctx, []string{fmt.Sprintf(allRolling, window)}, wsHandler)
+}
+
+// SubscribeBookTicker real-time updates to the best bid or ask price and quantity of the symbol
+func (c *BinanceWsClient) SubscribeBookTicker(ctx context.Context, symbol string, handler handlerBookTicker) (error, chan<- struct{}) {
+	wsHandler := func(event []byte) error {
+		bookTickerEvent := new(models.BookTickerEvent)
+		if err := json.Unmarshal(event, bookTickerEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(bookTickerEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{symbolStream(symbol, bookTicker)}, wsHandler)
+}
+
+// SubscribePartialDepth top 5, 10 or 20 levels of the book pushed every second
+func (c *BinanceWsClient) SubscribePartialDepth(ctx context.Context, symbol string, levels int, handler handlerPartialDepth) (error, chan<- struct{}) {
+	valid := false
+	for _, l := range depthLevels {
+		valid = valid || l == levels
+	}
+	if !valid {
+		return fmt.Errorf("invalid partial depth levels %d", levels), nil
+	}
+
+	wsHandler := func(event []byte) error {
+		depthEventRaw := new(models.PartialDepthEventRaw)
+		if err := json.Unmarshal(event, depthEventRaw); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(depthEventRaw.Transform(strings.ToUpper(symbol)))
+		return nil
+	}
+	return c.subscribe(ctx, []string{partialDepthStream(symbol, levels)}, wsHandler)
+}
+
+// SubscribeDepth100ms diff depth of the symbol pushed every 100ms instead of every second
+func (c *BinanceWsClient) SubscribeDepth100ms(ctx context.Context, symbol string, handler handlerEvent) (error, chan<- struct{}) {
+	return c.serveDepth(ctx, []string{symbolStream(symbol, depth100ms)}, handler)
+}
+
+// SubscribeUserData account, order and balance updates of the listenKey created by BinanceClient.StartUserDataStream
+func (c *BinanceWsClient) SubscribeUserData(ctx context.Context, listenKey string, handler handlerUserData) (error, chan<- struct{}) {
+	if listenKey == "" {
+		return fmt.Errorf("listenKey is required"), nil
+	}
+
+	wsHandler := func(event []byte) error {
+		userDataEvent := new(models.UserDataEvent)
+		if err := json.Unmarshal(event, userDataEvent); err != nil {
+			return fmt.Errorf("error json parsing %s", err.Error())
+		}
+		handler(userDataEvent)
+		return nil
+	}
+	return c.subscribe(ctx, []string{listenKey}, wsHandler)
+}
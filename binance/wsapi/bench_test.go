@@ -0,0 +1,61 @@
+package wsapi_test
+
+import (
+	"context"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/wsapi"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// Signed account request over the WebSocket API and REST against the local server,
+// the WebSocket API reuses one connection while REST pays for the HTTP round trip of each request.
+// Client side rate limiting and request logging are off so only the transport is compared:
+//
+//	go test ./binance/wsapi -run '^$' -bench Account
+func BenchmarkAccountWsAPI(b *testing.B) {
+	defer quiet()()
+	s := binancetest.NewServer("key", "secret")
+	defer s.Close()
+
+	client := wsapi.NewClient("key", "secret", wsapi.WithBaseURL(s.WsAPIURL()))
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Account(ctx, models.AccountRequest{}).Wait(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAccountREST(b *testing.B) {
+	defer quiet()()
+	s := binancetest.NewServer("key", "secret")
+	defer s.Close()
+
+	client := v3.NewBinanceClient("key", "secret", v3.WithBaseURL(s.URL()), v3.WithRateLimiter(nil))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.GetAccount(ctx, models.AccountRequest{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// quiet disables info logs until the returned func restores the level
+func quiet() func() {
+	level := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	return func() { zerolog.SetGlobalLevel(level) }
+}
@@ -0,0 +1,377 @@
+// Package wsapi is a client of Binance WebSocket API which places, cancels and queries orders
+// over a persistent connection.
+//
+// Every call sends a request with a unique id and returns a Future resolved by the response with that id:
+//
+//	c := wsapi.NewClient(apiKey, secretKey, wsapi.WithEnvironment(binance.Testnet))
+//	defer c.Close()
+//	order, err := c.PlaceOrder(ctx, models.OrderRequest{...}).Wait()
+//
+// Unlike REST the connection is opened once, so a request costs a single round trip without
+// TCP and TLS handshakes. After Logon with an Ed25519 key requests are not signed either.
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeTimeout limits writing of a request to the connection
+	writeTimeout = 10 * time.Second
+	// defaultRecvWindow Binance default, the request is rejected if it arrives later
+	defaultRecvWindow = 5000
+)
+
+// securityType defines what a method requires to be called
+type securityType int
+
+const (
+	securityNone   securityType = iota // public method
+	securitySigned                     // apiKey and signature unless the session is logged on
+	securityLogon                      // apiKey and signature always
+)
+
+// numericParams are sent as JSON numbers, other parameters as strings
+var numericParams = map[string]bool{
+	"timestamp":     true,
+	"recvWindow":    true,
+	"orderId":       true,
+	"cancelOrderId": true,
+	"orderListId":   true,
+	"limit":         true,
+	"strategyId":    true,
+	"strategyType":  true,
+	"trailingDelta": true,
+	"fromId":        true,
+	"startTime":     true,
+	"endTime":       true,
+}
+
+// boolParams are sent as JSON booleans
+var boolParams = map[string]bool{
+	"omitZeroBalances": true,
+}
+
+// ErrConnectionClosed is returned for requests which were pending when the connection was lost
+var ErrConnectionClosed = errors.New("websocket API connection closed")
+
+type Client struct {
+	BaseURL     string // WebSocket API endpoint, connections opened later use the new value
+	APIKey      string
+	Signer      binance.Signer         // signs requests, Logon requires Ed25519
+	RecvWindow  int64                  // milliseconds, sent with signed requests which do not set it
+	Risk        risk.Checker           // checks every order before it is sent, nil sends orders unchecked
+	Rules       *models.SymbolRegistry // symbol rules orders are checked against, nil sends orders unchecked
+	mu          sync.Mutex
+	conn        *connection
+	nextID      atomic.Uint64
+	timeOffset  atomic.Int64 // server time minus local time in milliseconds
+	timeSyncing atomic.Bool
+}
+
+// NewClient creates a client of production WebSocket API unless options select another environment.
+// The connection is opened by the first request or by Connect.
+func NewClient(apiKey, secretKey string, opts ...Option) *Client {
+	c := &Client{
+		BaseURL:    binance.Production.WsAPIURL,
+		APIKey:     apiKey,
+		Signer:     binance.NewHMACSigner(secretKey),
+		RecvWindow: defaultRecvWindow,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type request struct {
+	ID     string                 `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type response struct {
+	ID     string          `json:"id"`
+	Status int             `json:"status"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			RetryAfter int64 `json:"retryAfter"` // milliseconds timestamp when the ban is lifted
+		} `json:"data"`
+	} `json:"error"`
+	err error // connection failure
+}
+
+// apiError Binance error of the response, nil if the request succeeded
+func (r *response) apiError() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.Error == nil {
+		return nil
+	}
+
+	apiErr := &binance.APIError{HTTPStatus: r.Status, Code: r.Error.Code, Msg: r.Error.Msg}
+	if r.Error.Data.RetryAfter > 0 {
+		apiErr.RetryAfter = time.Until(time.UnixMilli(r.Error.Data.RetryAfter))
+	}
+	return apiErr
+}
+
+// connection websocket connection with requests waiting for responses
+type connection struct {
+	ws       *websocket.Conn
+	writeMu  sync.Mutex
+	mu       sync.Mutex
+	pending  map[string]func(r *response)
+	closed   bool
+	loggedOn bool
+}
+
+func (c *connection) register(id string, resolve func(r *response)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrConnectionClosed
+	}
+	c.pending[id] = resolve
+	return nil
+}
+
+// unregister drops the request whose caller stopped waiting, its response is ignored
+func (c *connection) unregister(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+func (c *connection) resolve(r *response) {
+	c.mu.Lock()
+	resolve := c.pending[r.ID]
+	delete(c.pending, r.ID)
+	c.mu.Unlock()
+
+	if resolve != nil {
+		resolve(r)
+	}
+}
+
+func (c *connection) write(req request) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.ws.WriteJSON(req)
+}
+
+func (c *connection) isLoggedOn() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.loggedOn
+}
+
+func (c *connection) setLoggedOn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loggedOn = true
+}
+
+// close fails pending requests with the error
+func (c *connection) close(err error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	c.ws.Close()
+	for _, resolve := range pending {
+		resolve(&response{err: err})
+	}
+}
+
+// Connect opens the connection in advance so that the first request does not wait for the handshake
+func (c *Client) Connect(ctx context.Context) error {
+	_, err := c.connect(ctx)
+	return err
+}
+
+// Close closes the connection, pending requests fail with ErrConnectionClosed
+func (c *Client) Close() error {
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.close(ErrConnectionClosed)
+	}
+	return nil
+}
+
+// connect returns the open connection or dials a new one.
+// A new connection is not logged on, its requests are signed until Logon is called again.
+func (c *Client) connect(ctx context.Context) (*connection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	// Rate limits are not returned with every response to keep them small
+	url := c.BaseURL
+	if !strings.Contains(url, "?") {
+		url += "?returnRateLimits=false"
+	}
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &connection{ws: ws, pending: make(map[string]func(r *response))}
+	c.conn = conn
+	go c.readLoop(conn)
+	return conn, nil
+}
+
+// readLoop resolves pending requests until the connection fails, ping frames are answered by the default handler
+func (c *Client) readLoop(conn *connection) {
+	for {
+		_, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			c.mu.Lock()
+			if c.conn == conn {
+				c.conn = nil
+			}
+			c.mu.Unlock()
+
+			conn.close(fmt.Errorf("%w: %v", ErrConnectionClosed, err))
+			return
+		}
+
+		r := &response{}
+		if err := json.Unmarshal(data, r); err != nil {
+			log.Printf("Unexpected websocket API message %s: %v", data, err)
+			continue
+		}
+		conn.resolve(r)
+	}
+}
+
+// send writes the request, resolve is called with the response or the connection failure
+func (c *Client) send(ctx context.Context, method string, params interface{}, security securityType, resolve func(conn *connection, r *response)) (unregister func(), err error) {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := c.params(params, security, conn.isLoggedOn())
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatUint(c.nextID.Add(1), 10)
+	err = conn.register(id, func(r *response) {
+		if binance.IsTimestampOutsideRecvWindow(r.apiError()) {
+			go c.resyncTime()
+		}
+		resolve(conn, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.write(request{ID: id, Method: method, Params: p}); err != nil {
+		conn.close(fmt.Errorf("%w: %v", ErrConnectionClosed, err))
+		return nil, err
+	}
+	return func() { conn.unregister(id) }, nil
+}
+
+// params encodes request params to JSON values, signed requests get timestamp, recvWindow, apiKey and signature
+func (c *Client) params(params interface{}, security securityType, loggedOn bool) (map[string]interface{}, error) {
+	q, err := binance.QueryValues(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if security != securityNone {
+		if q.Get("timestamp") == "" {
+			q.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+c.timeOffset.Load(), 10))
+		}
+		if q.Get("recvWindow") == "" && c.RecvWindow > 0 {
+			q.Set("recvWindow", strconv.FormatInt(c.RecvWindow, 10))
+		}
+
+		if security == securityLogon || !loggedOn {
+			q.Set("apiKey", c.APIKey)
+
+			// Signature payload is params sorted by name and joined as in a query string but without escaping
+			names := make([]string, 0, len(q))
+			for name := range q {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			pairs := make([]string, len(names))
+			for i, name := range names {
+				pairs[i] = name + "=" + q.Get(name)
+			}
+
+			sig, err := c.Signer.Sign(strings.Join(pairs, "&"))
+			if err != nil {
+				return nil, err
+			}
+			q.Set("signature", sig)
+		}
+	}
+
+	if len(q) == 0 {
+		return nil, nil
+	}
+
+	p := make(map[string]interface{}, len(q))
+	for name := range q {
+		value := q.Get(name)
+		switch {
+		case numericParams[name]:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not an integer: %w", name, err)
+			}
+			p[name] = n
+		case boolParams[name]:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a boolean: %w", name, err)
+			}
+			p[name] = b
+		default:
+			p[name] = value
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,189 @@
+package wsapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var d = decimal.RequireFromString
+
+func newTestClient(t *testing.T, opts ...Option) (*Client, *binancetest.Server) {
+	t.Helper()
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+
+	opts = append([]Option{WithBaseURL(s.WsAPIURL())}, opts...)
+	c := NewClient("key", "secret", opts...)
+	t.Cleanup(func() { c.Close() })
+	return c, s
+}
+
+func limitOrder(price, quantity string) models.OrderRequest {
+	return models.OrderRequest{
+		Symbol:      "BTCUSDT",
+		Side:        models.SideBuy,
+		Type:        models.OrderTypeLimit,
+		TimeInForce: models.TimeInForceGTC,
+		Price:       d(price),
+		Quantity:    d(quantity),
+	}
+}
+
+func TestPlaceOrder(t *testing.T) {
+	c, s := newTestClient(t)
+
+	r := limitOrder("100", "1")
+	r.NewClientOrderID = "wsapi-1"
+	response, err := c.PlaceOrder(context.Background(), r).Wait()
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if response.ClientOrderId != "wsapi-1" || response.Status != models.OrderStatusNew {
+		t.Fatalf("response = %+v, want the new order", response)
+	}
+
+	requests := s.Requests()
+	last := requests[len(requests)-1]
+	if last.Method != binancetest.MethodWsAPI || last.Path != "order.place" {
+		t.Fatalf("request = %s %s, want order.place", last.Method, last.Path)
+	}
+	if last.APIKey != "key" || last.Query["signature"] == "" || last.Query["timestamp"] == "" {
+		t.Fatalf("params = %v, want apiKey, timestamp and signature", last.Query)
+	}
+}
+
+func TestSignatureWithWrongSecretIsRejected(t *testing.T) {
+	s := binancetest.NewServer("key", "secret")
+	defer s.Close()
+	c := NewClient("key", "other", WithBaseURL(s.WsAPIURL()))
+	defer c.Close()
+
+	_, err := c.Account(context.Background(), models.AccountRequest{}).Wait()
+	apiErr, ok := binance.AsAPIError(err)
+	if !ok || apiErr.Code != binance.CodeInvalidSignature {
+		t.Fatalf("error = %v, want invalid signature", err)
+	}
+}
+
+func TestScriptedErrorIsReturned(t *testing.T) {
+	c, s := newTestClient(t)
+	s.Script(binancetest.MethodWsAPI, "order.place", binancetest.Error(http.StatusBadRequest, binance.CodeNewOrderRejected,
+		"Account has insufficient balance for requested action."))
+
+	_, err := c.PlaceOrder(context.Background(), limitOrder("100", "1")).Wait()
+	if !binance.IsInsufficientBalance(err) {
+		t.Fatalf("error = %v, want insufficient balance", err)
+	}
+	if _, err := c.PlaceOrder(context.Background(), limitOrder("100", "1")).Wait(); err != nil {
+		t.Fatalf("second order: %v", err)
+	}
+}
+
+func TestLogonRequestsAreNotSigned(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := binance.NewEd25519Signer(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, s := newTestClient(t, WithSigner(signer))
+	s.PublicKey = public
+	ctx := context.Background()
+
+	if _, err := c.Logon(ctx).Wait(); err != nil {
+		t.Fatalf("Logon: %v", err)
+	}
+	if _, err := c.Account(ctx, models.AccountRequest{}).Wait(); err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+
+	requests := s.Requests()
+	if last := requests[len(requests)-1]; last.Query["signature"] != "" {
+		t.Fatalf("params = %v, request of a logged on session is signed", last.Query)
+	}
+}
+
+func TestOrderRejectedBeforeSending(t *testing.T) {
+	engine := risk.NewEngine()
+	engine.Limits.MaxQuantity = d("1")
+	c, s := newTestClient(t, WithRisk(engine))
+
+	_, err := c.PlaceOrder(context.Background(), limitOrder("100", "2")).Wait()
+	if _, ok := risk.AsRejection(err); !ok {
+		t.Fatalf("error = %v, want risk rejection", err)
+	}
+	if requests := s.Requests(); len(requests) != 0 {
+		t.Fatalf("requests = %+v, rejected order is sent", requests)
+	}
+}
+
+func TestWaitDropsCancelledRequest(t *testing.T) {
+	c, s := newTestClient(t)
+	release := make(chan struct{})
+	s.Handle(binancetest.MethodWsAPI, "account.status", func(*http.Request) binancetest.Response {
+		<-release
+		return binancetest.JSON(map[string]interface{}{})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	f := c.Account(ctx, models.AccountRequest{})
+	if _, err := f.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	conn.mu.Lock()
+	pending := len(conn.pending)
+	conn.mu.Unlock()
+	if pending != 0 {
+		t.Fatalf("%d pending requests after Wait gave up, want 0", pending)
+	}
+
+	// The late response is ignored, the connection keeps serving requests
+	s.Handle(binancetest.MethodWsAPI, "account.status", nil)
+	close(release)
+	if _, err := c.Account(context.Background(), models.AccountRequest{}).Wait(); err != nil {
+		t.Fatalf("request after the cancelled one: %v", err)
+	}
+}
+
+func TestPendingRequestsFailWhenConnectionIsLost(t *testing.T) {
+	c, s := newTestClient(t)
+	release := make(chan struct{})
+	defer close(release)
+	s.Handle(binancetest.MethodWsAPI, "account.status", func(*http.Request) binancetest.Response {
+		<-release
+		return binancetest.JSON(map[string]interface{}{})
+	})
+
+	f := c.Account(context.Background(), models.AccountRequest{})
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Wait(); !errors.Is(err, ErrConnectionClosed) {
+		t.Fatalf("error = %v, want ErrConnectionClosed", err)
+	}
+}
@@ -0,0 +1,85 @@
+package wsapi
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Future result of a request which is resolved when its response arrives
+type Future[T any] struct {
+	ctx    context.Context
+	once   sync.Once
+	done   chan struct{}
+	result *T
+	err    error
+
+	unregister func() // drops the pending request of the connection, nil if the request was not sent
+}
+
+func newFuture[T any](ctx context.Context) *Future[T] {
+	return &Future[T]{ctx: ctx, done: make(chan struct{})}
+}
+
+// Done is closed when the response arrives or the request fails
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the response arrives or the context of the request is cancelled.
+// A cancelled request is dropped from the connection and the future fails with the context error.
+func (f *Future[T]) Wait() (*T, error) {
+	select {
+	case <-f.done:
+	case <-f.ctx.Done():
+		if f.unregister != nil {
+			f.unregister()
+		}
+		f.fail(f.ctx.Err())
+	}
+	return f.result, f.err
+}
+
+func (f *Future[T]) complete(r *response) {
+	if err := r.apiError(); err != nil {
+		f.fail(err)
+		return
+	}
+
+	result := new(T)
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		f.fail(err)
+		return
+	}
+	f.once.Do(func() {
+		f.result = result
+		close(f.done)
+	})
+}
+
+func (f *Future[T]) fail(err error) {
+	f.once.Do(func() {
+		f.err = err
+		close(f.done)
+	})
+}
+
+// call sends the request and returns the future of its result
+func call[T any](ctx context.Context, c *Client, method string, params interface{}, security securityType) *Future[T] {
+	f := newFuture[T](ctx)
+	unregister, err := c.send(ctx, method, params, security, func(_ *connection, r *response) {
+		f.complete(r)
+	})
+	if err != nil {
+		f.fail(err)
+	}
+	f.unregister = unregister
+	return f
+}
+
+// failed future of a request which was not sent
+func failed[T any](ctx context.Context, err error) *Future[T] {
+	f := newFuture[T](ctx)
+	f.fail(err)
+	return f
+}
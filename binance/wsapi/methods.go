@@ -0,0 +1,103 @@
+package wsapi
+
+import (
+	"context"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+)
+
+// SessionStatus authentication status of the connection returned by session.logon
+type SessionStatus struct {
+	APIKey           string `json:"apiKey"`
+	AuthorizedSince  int64  `json:"authorizedSince"`
+	ConnectedSince   int64  `json:"connectedSince"`
+	ReturnRateLimits bool   `json:"returnRateLimits"`
+	ServerTime       int64  `json:"serverTime"`
+}
+
+// ServerTime checks connectivity and returns the server time
+func (c *Client) ServerTime(ctx context.Context) *Future[models.ServerTimeResponse] {
+	return call[models.ServerTimeResponse](ctx, c, "time", nil, securityNone)
+}
+
+// Depth returns the order book of the symbol
+func (c *Client) Depth(ctx context.Context, r models.DepthRequest) *Future[models.DepthResponse] {
+	if err := r.Validate(); err != nil {
+		return failed[models.DepthResponse](ctx, err)
+	}
+	return call[models.DepthResponse](ctx, c, "depth", r, securityNone)
+}
+
+// Logon authenticates the connection with Ed25519 key, later requests of the connection are not signed.
+// The session ends with the connection, requests of a new connection are signed until Logon is called again.
+func (c *Client) Logon(ctx context.Context) *Future[SessionStatus] {
+	f := newFuture[SessionStatus](ctx)
+	unregister, err := c.send(ctx, "session.logon", nil, securityLogon, func(conn *connection, r *response) {
+		if r.apiError() == nil {
+			conn.setLoggedOn()
+		}
+		f.complete(r)
+	})
+	if err != nil {
+		f.fail(err)
+	}
+	f.unregister = unregister
+	return f
+}
+
+// PlaceOrder places a new order, the response is FULL for LIMIT and MARKET orders unless newOrderRespType is set
+func (c *Client) PlaceOrder(ctx context.Context, r models.OrderRequest) *Future[models.OrderResponseFull] {
+	if err := r.Validate(); err != nil {
+		return failed[models.OrderResponseFull](ctx, err)
+	}
+	if err := r.CheckRules(c.Rules); err != nil {
+		return failed[models.OrderResponseFull](ctx, err)
+	}
+	if err := c.checkRisk(risk.NewOrder(r)); err != nil {
+		return failed[models.OrderResponseFull](ctx, err)
+	}
+	return call[models.OrderResponseFull](ctx, c, "order.place", r, securitySigned)
+}
+
+// CancelOrder cancels an active order by orderId or origClientOrderId
+func (c *Client) CancelOrder(ctx context.Context, r models.OrderCancelRequest) *Future[models.OrderCancelResponse] {
+	if err := r.Validate(); err != nil {
+		return failed[models.OrderCancelResponse](ctx, err)
+	}
+	return call[models.OrderCancelResponse](ctx, c, "order.cancel", r, securitySigned)
+}
+
+// CancelReplace cancels an existing order and places a new one on the same symbol
+func (c *Client) CancelReplace(ctx context.Context, r models.CancelReplaceRequest) *Future[models.CancelReplaceResponse] {
+	if err := r.Validate(); err != nil {
+		return failed[models.CancelReplaceResponse](ctx, err)
+	}
+	if err := c.checkRisk(risk.CancelReplace(r)); err != nil {
+		return failed[models.CancelReplaceResponse](ctx, err)
+	}
+	return call[models.CancelReplaceResponse](ctx, c, "order.cancelReplace", r, securitySigned)
+}
+
+// OpenOrders returns open orders of the symbol or of all symbols if it is empty
+func (c *Client) OpenOrders(ctx context.Context, r models.OpenOrdersRequest) *Future[[]models.OpenOrdersResponse] {
+	if err := r.Validate(); err != nil {
+		return failed[[]models.OpenOrdersResponse](ctx, err)
+	}
+	return call[[]models.OpenOrdersResponse](ctx, c, "openOrders.status", r, securitySigned)
+}
+
+// Account returns balances and commissions of the account
+func (c *Client) Account(ctx context.Context, r models.AccountRequest) *Future[models.AccountResponse] {
+	if err := r.Validate(); err != nil {
+		return failed[models.AccountResponse](ctx, err)
+	}
+	return call[models.AccountResponse](ctx, c, "account.status", r, securitySigned)
+}
+
+// checkRisk passes the order through the risk checker before it is sent
+func (c *Client) checkRisk(o risk.Order) error {
+	if c.Risk == nil {
+		return nil
+	}
+	return c.Risk.Check(o)
+}
@@ -0,0 +1,56 @@
+package wsapi
+
+import (
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"gateaway/binance/risk"
+)
+
+// Option configures Client created by NewClient
+type Option func(*Client)
+
+// WithEnvironment connects to WebSocket API of the environment, e.g. binance.Testnet
+func WithEnvironment(env binance.Environment) Option {
+	return func(c *Client) {
+		if env.WsAPIURL != "" {
+			c.BaseURL = env.WsAPIURL
+		}
+	}
+}
+
+// WithBaseURL connects to a custom WebSocket API URL, e.g. binancetest.Server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = baseURL
+	}
+}
+
+// WithSigner signs requests with RSA or Ed25519 key instead of HMAC with the secret key, nil keeps HMAC
+func WithSigner(signer binance.Signer) Option {
+	return func(c *Client) {
+		if signer != nil {
+			c.Signer = signer
+		}
+	}
+}
+
+// WithRecvWindow sets recvWindow in milliseconds sent with signed requests
+func WithRecvWindow(recvWindow int64) Option {
+	return func(c *Client) {
+		c.RecvWindow = recvWindow
+	}
+}
+
+// WithRisk checks every order of PlaceOrder and CancelReplace before it is sent
+func WithRisk(checker risk.Checker) Option {
+	return func(c *Client) {
+		c.Risk = checker
+	}
+}
+
+// WithRules checks orders of PlaceOrder against the registry, e.g. Rules of v3.BinanceClient loaded by GetExchangeInfo
+func WithRules(rules *models.SymbolRegistry) Option {
+	return func(c *Client) {
+		c.Rules = rules
+	}
+}
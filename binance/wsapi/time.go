@@ -0,0 +1,50 @@
+package wsapi
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// resyncTimeout limits the server time request made after a request was rejected with -1021
+const resyncTimeout = 5 * time.Second
+
+// TimeOffset difference between Binance server clock and the local clock
+func (c *Client) TimeOffset() time.Duration {
+	return time.Duration(c.timeOffset.Load()) * time.Millisecond
+}
+
+// SetTimeOffset sets the offset, e.g. calibrated by v3.BinanceClient.SyncTime
+func (c *Client) SetTimeOffset(offset time.Duration) {
+	c.timeOffset.Store(offset.Milliseconds())
+}
+
+// SyncTime calibrates timestamps of signed requests against the time method.
+// The server time is compared with the middle of the round trip.
+func (c *Client) SyncTime(ctx context.Context) error {
+	start := time.Now()
+	response, err := c.ServerTime(ctx).Wait()
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+
+	local := start.Add(end.Sub(start) / 2).UnixMilli()
+	c.timeOffset.Store(response.ServerTime - local)
+	return nil
+}
+
+// resyncTime calibrates the clock after a request was rejected with -1021
+func (c *Client) resyncTime() {
+	if !c.timeSyncing.CompareAndSwap(false, true) {
+		return
+	}
+	defer c.timeSyncing.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), resyncTimeout)
+	defer cancel()
+
+	if err := c.SyncTime(ctx); err != nil {
+		log.Printf("Failed to sync server time: %s", err)
+	}
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/models"
+	"gateaway/binance/oms"
+	"gateaway/binance/orderbook"
+	"gateaway/binance/risk"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// bookLevels price levels of each side pushed by book streams and returned by depth by default
+	bookLevels = 20
+	// maxBodySize limits JSON bodies of order requests
+	maxBodySize = 1 << 20
+)
+
+var upgrader = websocket.Upgrader{
+	// The API is served to local strategies, not to browsers
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// errorResponse is returned with every failed request, Binance errors keep their code
+type errorResponse struct {
+	Code int64  `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// depthMessage levels of the local order book
+type depthMessage struct {
+	Symbol       string      `json:"symbol"`
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+func newDepthMessage(b *orderbook.Book, levels int) depthMessage {
+	return depthMessage{
+		Symbol:       b.Symbol,
+		LastUpdateID: b.LastUpdateID(),
+		Bids:         levelPairs(b.Bids(levels)),
+		Asks:         levelPairs(b.Asks(levels)),
+	}
+}
+
+func levelPairs(levels []orderbook.Level) [][2]string {
+	pairs := make([][2]string, len(levels))
+	for i, l := range levels {
+		pairs[i] = [2]string{l.Price.String(), l.Quantity.String()}
+	}
+	return pairs
+}
+
+// routes of the northbound API, paths follow Binance REST API:
+//
+//	GET    /health               environment, rate limit usage and lag of stream clients
+//	GET    /api/v3/depth         local order book, symbol and limit
+//	GET    /api/v3/ticker/price  symbol
+//	GET    /api/v3/klines        symbol, interval, startTime, endTime and limit
+//	POST   /api/v3/order         JSON body of models.OrderRequest
+//	GET    /api/v3/order         symbol and orderId or origClientOrderId
+//	DELETE /api/v3/order         symbol and orderId or origClientOrderId
+//	GET    /api/v3/openOrders    symbol, all symbols without it
+//	GET    /api/v3/account
+//	GET    /oms/orders           orders placed through the gateway, symbol and open=true
+//	GET    /oms/positions        filled quantities of orders placed through the gateway
+//	POST   /oms/reconcile        compares orders with Binance now, returns the differences
+//	GET    /risk                 risk limits and kill switch state
+//	POST   /risk/halt            kill switch rejecting all orders, reason
+//	POST   /risk/resume          accepts orders again
+//	GET    /ws                   websocket of comma separated streams, e.g. ?streams=btcusdt@book,btcusdt@trade,orders
+func (g *gateway) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", g.handleHealth)
+	mux.HandleFunc("/api/v3/depth", g.handleDepth)
+	mux.HandleFunc("/api/v3/ticker/price", g.handleTickerPrice)
+	mux.HandleFunc("/api/v3/klines", g.handleKlines)
+	mux.HandleFunc("/api/v3/order", g.handleOrder)
+	mux.HandleFunc("/api/v3/openOrders", g.handleOpenOrders)
+	mux.HandleFunc("/api/v3/account", g.handleAccount)
+	mux.HandleFunc("/oms/orders", g.handleOmsOrders)
+	mux.HandleFunc("/oms/positions", g.handleOmsPositions)
+	mux.HandleFunc("/oms/reconcile", g.handleReconcile)
+	mux.HandleFunc("/risk", g.handleRisk)
+	mux.HandleFunc("/risk/halt", g.handleHalt)
+	mux.HandleFunc("/risk/resume", g.handleResume)
+	mux.HandleFunc("/ws", g.handleWs)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with the status of Binance error, orders rejected by risk checks are 403,
+// other failures of Binance requests are 502
+func writeError(w http.ResponseWriter, err error) {
+	if rejection, ok := risk.AsRejection(err); ok {
+		writeJSON(w, http.StatusForbidden, errorResponse{Code: binance.CodeNewOrderRejected, Msg: rejection.Error()})
+		return
+	}
+	if apiErr, ok := binance.AsAPIError(err); ok {
+		if apiErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(apiErr.RetryAfter.Seconds()+0.5)))
+		}
+		writeJSON(w, apiErr.HTTPStatus, errorResponse{Code: apiErr.Code, Msg: apiErr.Msg})
+		return
+	}
+	writeJSON(w, http.StatusBadGateway, errorResponse{Code: binance.CodeUnknown, Msg: err.Error()})
+}
+
+// badRequest responds to invalid requests which are not sent to Binance
+func badRequest(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, errorResponse{Code: binance.CodeUnknown, Msg: err.Error()})
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Code: binance.CodeUnknown, Msg: "method not allowed"})
+	return false
+}
+
+// queryInt64 optional integer parameter, nil if it is missing
+func queryInt64(r *http.Request, name string) (*int64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an integer", name)
+	}
+	return &n, nil
+}
+
+// queryInt64s parses the integer parameters into the targets, zero if missing
+func queryInt64s(r *http.Request, targets map[string]*int64) error {
+	for name, target := range targets {
+		n, err := queryInt64(r, name)
+		if err != nil {
+			return err
+		}
+		if n != nil {
+			*target = *n
+		}
+	}
+	return nil
+}
+
+func (g *gateway) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"environment": g.cfg.Environment.Name,
+		"rateLimits":  g.rest.RateLimiter.Usage(),
+		"timeOffset":  g.rest.TimeOffset().Milliseconds(),
+		"streams":     g.streamStats(),
+	})
+}
+
+// handleDepth serves the local order book, the first request of a symbol starts syncing it
+func (g *gateway) handleDepth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+	if symbol == "" {
+		badRequest(w, errors.New("symbol is required"))
+		return
+	}
+	limit := int64(bookLevels)
+	if err := queryInt64s(r, map[string]*int64{"limit": &limit}); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	b := g.books.Book(symbol)
+	if b == nil {
+		var err error
+		if b, err = g.books.Subscribe(g.ctx, symbol); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	if !b.Synced() {
+		w.Header().Set("Retry-After", "1")
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Code: binance.CodeUnknown, Msg: fmt.Sprintf("book of %s is syncing", symbol)})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newDepthMessage(b, int(limit)))
+}
+
+func (g *gateway) handleTickerPrice(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	req := models.TickerRequest{Symbol: r.URL.Query().Get("symbol")}
+	if err := req.Validate(); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	prices, err := g.rest.GetTickerPrice(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, prices)
+}
+
+func (g *gateway) handleKlines(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	q := r.URL.Query()
+	req := models.KlinesRequest{Symbol: q.Get("symbol"), Interval: q.Get("interval"), TimeZone: q.Get("timeZone")}
+	var err error
+	if req.StartTime, err = queryInt64(r, "startTime"); err == nil {
+		req.EndTime, err = queryInt64(r, "endTime")
+	}
+	var limit int64
+	if err == nil {
+		err = queryInt64s(r, map[string]*int64{"limit": &limit})
+	}
+	req.Limit = int(limit)
+	if err == nil {
+		err = req.Validate()
+	}
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	klines, err := g.rest.GetKlines(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, klines)
+}
+
+// handleOrder places an order from JSON body, queries or cancels an order by query params.
+// Placed orders are tracked by the order manager, a missing newClientOrderId is generated before.
+func (g *gateway) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost, http.MethodGet, http.MethodDelete) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req models.OrderRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+			badRequest(w, fmt.Errorf("invalid order: %w", err))
+			return
+		}
+		if req.NewClientOrderID == "" {
+			req.NewClientOrderID = g.rest.ClientOrderIDs.Next()
+		}
+		if err := req.Validate(); err != nil {
+			badRequest(w, err)
+			return
+		}
+		if _, err := g.orders.Submit(req); err != nil {
+			badRequest(w, err)
+			return
+		}
+
+		order, err := g.rest.NewOrder(r.Context(), req)
+		if err != nil {
+			g.orders.Reject(req.NewClientOrderID, err)
+			writeError(w, err)
+			return
+		}
+		g.applyOrder(order.ClientOrderId, func() (oms.Order, error) { return g.orders.ApplyNewOrder(order) })
+		writeJSON(w, http.StatusOK, order)
+
+	case http.MethodGet:
+		req := models.GetOrderRequest{Symbol: r.URL.Query().Get("symbol"), OrigClientOrderID: r.URL.Query().Get("origClientOrderId")}
+		if err := queryInt64s(r, map[string]*int64{"orderId": &req.OrderID}); err != nil {
+			badRequest(w, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			badRequest(w, err)
+			return
+		}
+
+		order, err := g.rest.GetOrder(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		g.applyOrder(order.ClientOrderId, func() (oms.Order, error) { return g.orders.ApplyGetOrder(order) })
+		writeJSON(w, http.StatusOK, order)
+
+	case http.MethodDelete:
+		req := models.OrderCancelRequest{Symbol: r.URL.Query().Get("symbol"), OrigClientOrderID: r.URL.Query().Get("origClientOrderId")}
+		if err := queryInt64s(r, map[string]*int64{"orderId": &req.OrderID}); err != nil {
+			badRequest(w, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			badRequest(w, err)
+			return
+		}
+
+		order, err := g.rest.CancelOrder(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		g.applyOrder(order.OrigClientOrderId, func() (oms.Order, error) { return g.orders.ApplyCancel(order) })
+		writeJSON(w, http.StatusOK, order)
+	}
+}
+
+func (g *gateway) handleOpenOrders(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	orders, err := g.rest.GetOpenOrders(r.Context(), models.OpenOrdersRequest{Symbol: r.URL.Query().Get("symbol")})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (g *gateway) handleAccount(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	account, err := g.rest.GetAccount(r.Context(), models.AccountRequest{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, account)
+}
+
+// handleOmsOrders serves orders tracked by the order manager, open orders only with open=true
+func (g *gateway) handleOmsOrders(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	orders := g.orders.Orders(symbol)
+	if r.URL.Query().Get("open") == "true" {
+		orders = g.orders.OpenOrders(symbol)
+	}
+	if orders == nil {
+		orders = []oms.Order{}
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (g *gateway) handleOmsPositions(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+		writeJSON(w, http.StatusOK, g.orders.Position(symbol))
+		return
+	}
+	writeJSON(w, http.StatusOK, g.orders.Positions())
+}
+
+// handleReconcile runs a reconciliation in addition to the scheduled ones
+func (g *gateway) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if g.recon == nil {
+		badRequest(w, errors.New("reconciliation requires API keys"))
+		return
+	}
+
+	events, err := g.recon.Reconcile(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if events == nil {
+		events = []oms.Event{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (g *gateway) handleRisk(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	halted, reason := g.risk.Halted()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"halted":       halted,
+		"haltReason":   reason,
+		"limits":       g.risk.Limits,
+		"maxPositions": g.risk.MaxPositions,
+		"maxOrderRate": g.risk.MaxOrders,
+	})
+}
+
+// handleHalt turns the kill switch on, orders already placed are not canceled
+func (g *gateway) handleHalt(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "halted by " + r.RemoteAddr
+	}
+	g.risk.Halt(reason)
+	log.Warn().Msg(fmt.Sprintf("Trading halted: %s", reason))
+	g.handleRisk(w, r)
+}
+
+func (g *gateway) handleResume(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	g.risk.Resume()
+	log.Warn().Msg("Trading resumed")
+	g.handleRisk(w, r)
+}
+
+// handleWs subscribes the websocket to the streams, messages from the client are ignored
+func (g *gateway) handleWs(w http.ResponseWriter, r *http.Request) {
+	var streams []string
+	for _, stream := range strings.Split(r.URL.Query().Get("streams"), ",") {
+		if stream = strings.ToLower(strings.TrimSpace(stream)); stream != "" {
+			streams = append(streams, stream)
+		}
+	}
+	if len(streams) == 0 {
+		badRequest(w, errors.New("streams are required"))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := newClient(conn)
+	g.connect(c)
+	defer func() {
+		c.close()
+		g.disconnect(c)
+	}()
+
+	for _, stream := range streams {
+		if err := g.subscribe(c, stream); err != nil {
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()), time.Now().Add(writeTimeout))
+			return
+		}
+	}
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateaway/binance/hub"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// clientQueueSize messages of a stream buffered for a client before the stream policy applies
+	clientQueueSize = 1024
+	// writeTimeout limits writing of a message to a client
+	writeTimeout = 10 * time.Second
+	// pingInterval keeps idle client connections open through proxies
+	pingInterval = 30 * time.Second
+)
+
+// streamMessage is sent to clients in the format of Binance combined streams
+type streamMessage struct {
+	Stream string      `json:"stream"`
+	Data   interface{} `json:"data"`
+}
+
+// client northbound websocket connection, every stream is written by its own goroutine
+type client struct {
+	name    string
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	subscribers []*hub.Subscriber[interface{}]
+	closed      bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(conn *websocket.Conn) *client {
+	c := &client{
+		name: conn.RemoteAddr().String(),
+		conn: conn,
+		done: make(chan struct{}),
+	}
+	go c.pingLoop()
+	return c
+}
+
+// forward writes messages of the subscriber to the client, a client too slow for a disconnect policy is closed
+func (c *client) forward(stream string, sub *hub.Subscriber[interface{}]) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		sub.Close()
+		return
+	}
+	c.subscribers = append(c.subscribers, sub)
+	c.mu.Unlock()
+
+	go func() {
+		err := sub.Run(context.Background(), func(v interface{}) {
+			if err := c.write(streamMessage{Stream: stream, Data: v}); err != nil {
+				c.close()
+			}
+		})
+		if errors.Is(err, hub.ErrSlowConsumer) {
+			log.Warn().Msg(fmt.Sprintf("Client %s is too slow for %s stream, disconnecting", c.name, stream))
+		}
+		c.close()
+	}()
+}
+
+func (c *client) write(m streamMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.conn.WriteJSON(m)
+}
+
+func (c *client) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// close unsubscribes the client, sends a close frame and closes the connection
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		subscribers := c.subscribers
+		c.mu.Unlock()
+
+		close(c.done)
+		for _, sub := range subscribers {
+			sub.Close()
+		}
+		c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/hub"
+	"gateaway/binance/models"
+	"gateaway/binance/oms"
+	"gateaway/binance/orderbook"
+	"gateaway/binance/risk"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	wsmodels "gateaway/binance/ws/models"
+	"gateaway/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// ordersStream northbound stream of user data events
+	ordersStream = "orders"
+	// bookSuffix and tradeSuffix name northbound streams of a symbol, e.g. btcusdt@book
+	bookSuffix  = "@book"
+	tradeSuffix = "@trade"
+	// userDataRetryDelay delay before a failed user data stream is opened again
+	userDataRetryDelay = 5 * time.Second
+	// clientOrderIDPrefix of client order ids generated for orders placed without newClientOrderId
+	clientOrderIDPrefix = "gateway"
+	// avgPriceTimeout limits the average price request of a risk check made before the book is synced
+	avgPriceTimeout = 2 * time.Second
+)
+
+// gateway is the single Binance session shared by all northbound clients
+type gateway struct {
+	cfg    *config.Config
+	ctx    context.Context
+	rest   *v3.BinanceClient
+	stream *ws.BinanceWsClient
+	books  *orderbook.Manager
+	orders *oms.Manager    // orders placed through the gateway
+	recon  *oms.Reconciler // nil without API keys
+	risk   *risk.Engine    // checks orders before they are sent
+
+	mu       sync.Mutex
+	clients  map[*client]struct{}     // connected northbound clients
+	streams  map[string]*upstream     // northbound stream by name
+	starting map[string]chan struct{} // closed when the Binance stream of the northbound stream is started
+}
+
+// upstream Binance stream fanned out to the clients subscribed to its northbound stream
+type upstream struct {
+	hub  *hub.Hub[interface{}]
+	stop func()
+}
+
+// newGateway connects to Binance, private streams are opened only with API keys.
+// Binance streams and the user data stream are stopped when ctx is cancelled.
+func newGateway(ctx context.Context, cfg *config.Config) (*gateway, error) {
+	ids, err := binance.NewClientOrderIDGenerator(clientOrderIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey,
+		v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer), v3.WithClientOrderIDs(ids))
+
+	g := &gateway{
+		cfg:      cfg,
+		ctx:      ctx,
+		rest:     rest,
+		stream:   ws.NewBinanceWsClient(cfg.APIKey, cfg.SecretKey, ws.WithEnvironment(cfg.Environment)),
+		orders:   oms.NewManager(),
+		clients:  make(map[*client]struct{}),
+		streams:  make(map[string]*upstream),
+		starting: make(map[string]chan struct{}),
+	}
+	g.books = orderbook.NewManager(g.rest, g.stream)
+	g.books.OnChange(g.publishBook)
+
+	g.risk = risk.NewEngine()
+	g.risk.Limits = cfg.Risk
+	g.risk.MaxPositions = cfg.MaxPositions
+	g.risk.MaxOrders = cfg.MaxOrderRate
+	g.risk.Prices = risk.PriceFunc(g.price)
+	g.risk.Positions = risk.PositionFunc(g.position)
+	g.risk.Working = risk.WorkingFunc(g.working)
+	g.risk.BaseAsset = g.rest.Rules.BaseAsset
+	g.rest.Risk = g.risk
+
+	if err := g.rest.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("binance is not reachable: %w", err)
+	}
+	// Base assets of symbols are needed by position limits
+	if len(cfg.MaxPositions) > 0 {
+		if _, err := g.rest.GetExchangeInfo(ctx, models.ExchangeInfoRequest{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Signer != nil {
+		if _, err := g.rest.StartTimeSync(ctx); err != nil {
+			return nil, err
+		}
+		if err := g.startUserData(); err != nil {
+			return nil, err
+		}
+
+		g.recon = oms.NewReconciler(g.rest, g.orders)
+		g.recon.CancelOrphans = cfg.CancelOrphans
+		g.recon.OnEvent(func(e oms.Event) {
+			if e.Type == oms.EventAdopted && e.Err == nil {
+				log.Info().Msg(fmt.Sprintf("Reconciliation: %s", e))
+				return
+			}
+			log.Warn().Msg(fmt.Sprintf("Reconciliation: %s", e))
+		})
+		if _, err := g.recon.Start(ctx); err != nil {
+			return nil, fmt.Errorf("reconcile orders: %w", err)
+		}
+	}
+
+	return g, nil
+}
+
+// price reference price of risk checks, the mid of the symbol book. The first order of a symbol
+// subscribes its book, the average price is used until the book is synced.
+func (g *gateway) price(symbol string) (decimal.Decimal, bool) {
+	if mid, ok := g.books.MidPrice(symbol); ok {
+		return mid, true
+	}
+	if g.books.Book(symbol) == nil {
+		go func() {
+			if _, err := g.books.Subscribe(g.ctx, symbol); err != nil {
+				log.Warn().Msg(fmt.Sprintf("Book of %s for risk checks is not subscribed: %s", symbol, err))
+			}
+		}()
+	}
+
+	if avg, ok := g.rest.Rules.AvgPrice(symbol); ok {
+		return avg, true
+	}
+	ctx, cancel := context.WithTimeout(g.ctx, avgPriceTimeout)
+	defer cancel()
+	avg, err := g.rest.GetAvgPrice(ctx, models.AvgPriceRequest{Symbol: symbol})
+	if err != nil {
+		log.Warn().Msg(fmt.Sprintf("Average price of %s for risk checks: %s", symbol, err))
+		return decimal.Zero, false
+	}
+	return avg.Price, true
+}
+
+// working quantity of the asset on open orders placed through the gateway, except the order of clientOrderID
+func (g *gateway) working(asset, clientOrderID string) (buy, sell decimal.Decimal) {
+	for _, o := range g.orders.OpenOrders("") {
+		if o.ClientOrderID == clientOrderID {
+			continue
+		}
+		if base, ok := g.rest.Rules.BaseAsset(o.Symbol); !ok || base != asset {
+			continue
+		}
+		if o.Side == models.SideBuy {
+			buy = buy.Add(o.RemainingQty())
+		} else {
+			sell = sell.Add(o.RemainingQty())
+		}
+	}
+	return buy, sell
+}
+
+// position net position of the asset filled by orders placed through the gateway
+func (g *gateway) position(asset string) decimal.Decimal {
+	net := decimal.Zero
+	for _, p := range g.orders.Positions() {
+		if base, ok := g.rest.Rules.BaseAsset(p.Symbol); ok && base == asset {
+			net = net.Add(p.Net())
+		}
+	}
+	return net
+}
+
+// startUserData opens the user data stream, it is opened again when the listenKey expires
+func (g *gateway) startUserData() error {
+	listenKey, done, err := g.rest.StartUserDataStream(g.ctx)
+	if err != nil {
+		return err
+	}
+
+	var once sync.Once
+	restart := func() {
+		once.Do(func() {
+			close(done)
+			go g.restartUserData()
+		})
+	}
+
+	err, _ = g.stream.SubscribeUserData(g.ctx, listenKey, func(e *wsmodels.UserDataEvent) {
+		if e.ListenKeyExpired {
+			log.Warn().Msg("listenKey expired, opening a new user data stream")
+			restart()
+			return
+		}
+		if r := e.ExecutionReport; r != nil {
+			g.applyOrder(r.ClientOrderID, func() (oms.Order, error) { return g.orders.ApplyExecutionReport(r) })
+		}
+		if payload := userDataPayload(e); payload != nil {
+			g.publish(ordersStream, payload)
+		}
+	})
+	if err != nil {
+		close(done)
+		return err
+	}
+	return nil
+}
+
+// userDataPayload the event as sent by Binance, nil for unknown events
+func userDataPayload(e *wsmodels.UserDataEvent) interface{} {
+	switch {
+	case e.ExecutionReport != nil:
+		return e.ExecutionReport
+	case e.AccountPosition != nil:
+		return e.AccountPosition
+	case e.BalanceUpdate != nil:
+		return e.BalanceUpdate
+	case e.ListStatus != nil:
+		return e.ListStatus
+	}
+	return nil
+}
+
+// applyOrder updates the order state from a response or an execution report.
+// Orders placed outside the gateway are not tracked, a response may arrive after the execution report.
+func (g *gateway) applyOrder(clientOrderID string, apply func() (oms.Order, error)) {
+	_, err := apply()
+	switch {
+	case err == nil, errors.Is(err, oms.ErrUnknownOrder), errors.Is(err, oms.ErrStaleUpdate):
+	default:
+		log.Warn().Msg(fmt.Sprintf("Update of order %s not applied: %s", clientOrderID, err))
+	}
+}
+
+func (g *gateway) restartUserData() {
+	for {
+		err := g.startUserData()
+		if err == nil || g.ctx.Err() != nil {
+			return
+		}
+		log.Error().Msg(fmt.Sprintf("Failed to open user data stream: %s", err))
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-time.After(userDataRetryDelay):
+		}
+	}
+}
+
+// subscribe adds the client to the stream, the first subscriber starts its Binance stream
+func (g *gateway) subscribe(c *client, stream string) error {
+	if err := g.startStream(stream); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	up, ok := g.streams[stream]
+	if !ok {
+		return fmt.Errorf("stream %s stopped", stream)
+	}
+	c.forward(stream, up.hub.Subscribe(hub.Options{
+		Name:      c.name,
+		QueueSize: clientQueueSize,
+		Policy:    streamPolicy(stream),
+	}))
+	return nil
+}
+
+// startStream starts the Binance stream unless it is running. Concurrent subscribers wait for the one starting it,
+// so that only one of them owns the stop function.
+// Binance streams are started without the lock, subscribing waits for the reader which publishes events.
+func (g *gateway) startStream(stream string) error {
+	g.mu.Lock()
+	for {
+		if _, ok := g.streams[stream]; ok {
+			g.mu.Unlock()
+			return nil
+		}
+		starting, ok := g.starting[stream]
+		if !ok {
+			break
+		}
+		g.mu.Unlock()
+		<-starting
+		g.mu.Lock()
+	}
+	starting := make(chan struct{})
+	g.starting[stream] = starting
+	g.mu.Unlock()
+
+	stop, err := g.startUpstream(stream)
+
+	g.mu.Lock()
+	delete(g.starting, stream)
+	if err == nil {
+		g.streams[stream] = &upstream{hub: hub.NewHub[interface{}](), stop: stop}
+	}
+	g.mu.Unlock()
+	close(starting)
+	return err
+}
+
+// streamPolicy only the latest book matters, trades may be dropped, order updates may not
+func streamPolicy(stream string) hub.Policy {
+	switch {
+	case strings.HasSuffix(stream, bookSuffix):
+		return hub.Conflate
+	case strings.HasSuffix(stream, tradeSuffix):
+		return hub.DropOldest
+	}
+	return hub.Disconnect
+}
+
+func (g *gateway) connect(c *client) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.clients[c] = struct{}{}
+}
+
+// disconnect forgets the closed client, Binance streams without subscribers are stopped
+func (g *gateway) disconnect(c *client) {
+	g.mu.Lock()
+	delete(g.clients, c)
+	var stops []func()
+	for name, up := range g.streams {
+		if up.hub.Len() == 0 {
+			stops = append(stops, up.stop)
+			up.hub.Close()
+			delete(g.streams, name)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// streamStats metrics of the clients of every northbound stream
+func (g *gateway) streamStats() map[string][]hub.Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := make(map[string][]hub.Stats, len(g.streams))
+	for name, up := range g.streams {
+		stats[name] = up.hub.Stats()
+	}
+	return stats
+}
+
+// startUpstream starts the Binance stream feeding the northbound stream
+func (g *gateway) startUpstream(stream string) (func(), error) {
+	switch {
+	case stream == ordersStream:
+		if g.cfg.Signer == nil {
+			return nil, fmt.Errorf("%s stream requires API keys", ordersStream)
+		}
+		// The user data stream is open for the whole gateway lifetime
+		return func() {}, nil
+
+	case strings.HasSuffix(stream, bookSuffix):
+		symbol := strings.ToUpper(strings.TrimSuffix(stream, bookSuffix))
+		if b := g.books.Book(symbol); b != nil {
+			// The book is kept for REST depth requests as well
+			return func() {}, nil
+		}
+		if _, err := g.books.Subscribe(g.ctx, symbol); err != nil {
+			return nil, err
+		}
+		return func() { g.books.Unsubscribe(symbol) }, nil
+
+	case strings.HasSuffix(stream, tradeSuffix):
+		symbol := strings.TrimSuffix(stream, tradeSuffix)
+		err, done := g.stream.SubscribeTrade(g.ctx, symbol, func(e *wsmodels.TradeEvent) {
+			g.publish(stream, e)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return func() { close(done) }, nil
+	}
+
+	return nil, fmt.Errorf("unknown stream %s, use <symbol>%s, <symbol>%s or %s", stream, bookSuffix, tradeSuffix, ordersStream)
+}
+
+// publish queues the event to clients of the stream without waiting for them
+func (g *gateway) publish(stream string, data interface{}) {
+	g.mu.Lock()
+	up := g.streams[stream]
+	g.mu.Unlock()
+
+	if up != nil {
+		up.hub.Publish(data)
+	}
+}
+
+func (g *gateway) publishBook(b *orderbook.Book) {
+	if !b.Synced() {
+		return
+	}
+	g.publish(strings.ToLower(b.Symbol)+bookSuffix, newDepthMessage(b, bookLevels))
+}
+
+// close disconnects northbound clients and stops Binance streams
+func (g *gateway) close() {
+	g.mu.Lock()
+	clients := make([]*client, 0, len(g.clients))
+	for c := range g.clients {
+		clients = append(clients, c)
+	}
+	g.mu.Unlock()
+
+	for _, c := range clients {
+		c.close()
+	}
+	g.books.Close()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"gateaway/binance"
+	"gateaway/binance/binancetest"
+	"gateaway/config"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestGateway(t *testing.T, s *binancetest.Server, streamURL string) *httptest.Server {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cfg := &config.Config{Environment: binance.Environment{Name: "test", RESTURL: s.URL(), StreamURL: streamURL, DataOnly: true}}
+	g, err := newGateway(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(g.routes())
+	t.Cleanup(func() {
+		g.close()
+		server.Close()
+	})
+	return server
+}
+
+func dial(t *testing.T, server *httptest.Server, streams string) *websocket.Conn {
+	t.Helper()
+	address := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?streams=" + streams
+	conn, _, err := websocket.DefaultDialer.Dial(address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestConcurrentSubscribersShareTheBook(t *testing.T) {
+	s := binancetest.NewServer("key", "secret")
+	t.Cleanup(s.Close)
+	s.SetDepth("BTCUSDT", 1, [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})
+
+	// Binance stream connection is held until the second client subscribes while the first one starts the book
+	target, err := url.Parse("http" + strings.TrimPrefix(s.WsURL(), "ws"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	connecting := make(chan struct{}, 1)
+	release := make(chan struct{})
+	streams := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case connecting <- struct{}{}:
+		default:
+		}
+		<-release
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(streams.Close)
+	server := newTestGateway(t, s, "ws"+strings.TrimPrefix(streams.URL, "http"))
+
+	first := dial(t, server, "btcusdt@book")
+	select {
+	case <-connecting:
+	case <-time.After(time.Second):
+		t.Fatal("Binance stream is not connected")
+	}
+	second := dial(t, server, "btcusdt@book")
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	// Both clients receive the synced book and its updates
+	for _, conn := range []*websocket.Conn{first, second} {
+		read(t, conn, "btcusdt@book")
+	}
+	s.UpdateDepth("BTCUSDT", [][2]string{{"99.5", "2"}}, nil)
+	for _, conn := range []*websocket.Conn{first, second} {
+		read(t, conn, "btcusdt@book")
+	}
+}
+
+func read(t *testing.T, conn *websocket.Conn, stream string) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var m struct {
+		Stream string `json:"stream"`
+	}
+	if err := conn.ReadJSON(&m); err != nil {
+		t.Fatalf("no %s message: %v", stream, err)
+	}
+	if m.Stream != stream {
+		t.Fatalf("stream = %s, want %s", m.Stream, stream)
+	}
+}
@@ -0,0 +1,70 @@
+// Command gateway holds one Binance session and serves market data and order entry to local
+// strategies over HTTP/JSON and websockets.
+//
+// Configuration is read by config.LoadEnv from config/.env, GATEWAY_ADDR sets the listen address.
+// SIGINT and SIGTERM stop accepting requests, close websocket clients, the user data stream and
+// Binance streams before the process exits.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gateaway/config"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// shutdownTimeout limits waiting for in-flight requests on shutdown
+	shutdownTimeout = 10 * time.Second
+	// readHeaderTimeout drops connections which do not send request headers in time
+	readHeaderTimeout = 10 * time.Second
+)
+
+func main() {
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		log.Fatal().Msg(fmt.Sprintf("Failed to load config: %s", err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	g, err := newGateway(ctx, cfg)
+	if err != nil {
+		log.Fatal().Msg(fmt.Sprintf("Failed to start gateway: %s", err))
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.GatewayAddr,
+		Handler:           g.routes(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		log.Info().Msg(fmt.Sprintf("Gateway to %s listening on %s", cfg.Environment.Name, cfg.GatewayAddr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Msg(fmt.Sprintf("Gateway server failed: %s", err))
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("Shutting down gateway")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Websocket connections are hijacked, so Shutdown does not wait for them
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Msg(fmt.Sprintf("Failed to shut down gateway server: %s", err))
+	}
+	g.close()
+	log.Info().Msg("Gateway stopped")
+}
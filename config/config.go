@@ -2,22 +2,129 @@ package config
 
 import (
 	"fmt"
+	"gateaway/binance"
+	"gateaway/binance/risk"
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 	"os"
+	"strconv"
+	"strings"
 )
 
-func LoadEnv() (string, string, error) {
+const defaultGatewayAddr = ":8080"
+
+// Config settings read from config/.env and the process environment
+type Config struct {
+	APIKey        string
+	SecretKey     string
+	Environment   binance.Environment
+	Signer        binance.Signer             // nil for market data only environment
+	GatewayAddr   string                     // listen address of cmd/gateway
+	CancelOrphans bool                       // cmd/gateway cancels open orders which were not placed through it
+	Risk          risk.Limits                // limits of every order placed by cmd/gateway
+	MaxPositions  map[string]decimal.Decimal // absolute net position by base asset
+	MaxOrderRate  int                        // orders per second placed by cmd/gateway, zero is unlimited
+}
+
+// LoadEnv reads API keys and the Binance environment.
+// BINANCE_ENV selects prod (default), testnet, api1-api4 or data,
+// BINANCE_REST_URL, BINANCE_STREAM_URL and BINANCE_WS_API_URL override its URLs, e.g. for a proxy.
+// KEY_TYPE selects HMAC (default) signed with SECRET_KEY, RSA or ED25519 signed with PEM key of PRIVATE_KEY_PATH.
+// GATEWAY_ADDR is the address cmd/gateway listens on, :8080 by default.
+// CANCEL_ORPHANS=true makes cmd/gateway cancel open orders placed outside of it.
+// RISK_MAX_QUANTITY, RISK_MAX_NOTIONAL, RISK_PRICE_BAND, RISK_MAX_POSITIONS (e.g. BTC:1,ETH:10)
+// and RISK_MAX_ORDER_RATE limit orders of cmd/gateway, unset limits are disabled.
+func LoadEnv() (*Config, error) {
 	if err := godotenv.Load("config/.env"); err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	apiKey := os.Getenv("API_KEY")
-	secretKey := os.Getenv("SECRET_KEY")
+	cfg := &Config{
+		APIKey:        os.Getenv("API_KEY"),
+		SecretKey:     os.Getenv("SECRET_KEY"),
+		Environment:   binance.Production,
+		GatewayAddr:   os.Getenv("GATEWAY_ADDR"),
+		CancelOrphans: os.Getenv("CANCEL_ORPHANS") == "true",
+	}
+	if cfg.GatewayAddr == "" {
+		cfg.GatewayAddr = defaultGatewayAddr
+	}
+	if err := loadRisk(cfg); err != nil {
+		return nil, err
+	}
 
-	if apiKey == "" || secretKey == "" {
+	if name := os.Getenv("BINANCE_ENV"); name != "" {
+		env, err := binance.EnvironmentByName(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Environment = env
+	}
+	if url := os.Getenv("BINANCE_REST_URL"); url != "" {
+		cfg.Environment.Name = "custom"
+		cfg.Environment.RESTURL = url
+	}
+	if url := os.Getenv("BINANCE_STREAM_URL"); url != "" {
+		cfg.Environment.Name = "custom"
+		cfg.Environment.StreamURL = url
+	}
+	if url := os.Getenv("BINANCE_WS_API_URL"); url != "" {
+		cfg.Environment.Name = "custom"
+		cfg.Environment.WsAPIURL = url
+	}
 
-		return "", "", fmt.Errorf("API_KEY or SECRET_KEY not found in .env file")
+	// Market data only environment does not accept API keys, also when its URLs are overridden
+	if cfg.Environment.DataOnly {
+		return cfg, nil
 	}
 
-	return apiKey, secretKey, nil
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API_KEY not found in .env file")
+	}
+	signer, err := binance.NewSigner(os.Getenv("KEY_TYPE"), cfg.SecretKey, os.Getenv("PRIVATE_KEY_PATH"))
+	if err != nil {
+		return nil, fmt.Errorf("signer of KEY_TYPE %q: %w", os.Getenv("KEY_TYPE"), err)
+	}
+	cfg.Signer = signer
+
+	return cfg, nil
+}
+
+// loadRisk reads pre-trade risk limits
+func loadRisk(cfg *Config) error {
+	limits := map[string]*decimal.Decimal{
+		"RISK_MAX_QUANTITY": &cfg.Risk.MaxQuantity,
+		"RISK_MAX_NOTIONAL": &cfg.Risk.MaxNotional,
+		"RISK_PRICE_BAND":   &cfg.Risk.PriceBand,
+	}
+	for name, limit := range limits {
+		if value := os.Getenv(name); value != "" {
+			d, err := decimal.NewFromString(value)
+			if err != nil {
+				return fmt.Errorf("%s is not a decimal: %w", name, err)
+			}
+			*limit = d
+		}
+	}
+
+	if value := os.Getenv("RISK_MAX_POSITIONS"); value != "" {
+		cfg.MaxPositions = make(map[string]decimal.Decimal)
+		for _, pair := range strings.Split(value, ",") {
+			asset, limit, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			d, err := decimal.NewFromString(limit)
+			if !ok || err != nil {
+				return fmt.Errorf("RISK_MAX_POSITIONS %q is not ASSET:limit", pair)
+			}
+			cfg.MaxPositions[strings.ToUpper(asset)] = d
+		}
+	}
+
+	if value := os.Getenv("RISK_MAX_ORDER_RATE"); value != "" {
+		rate, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("RISK_MAX_ORDER_RATE is not an integer: %w", err)
+		}
+		cfg.MaxOrderRate = rate
+	}
+	return nil
 }
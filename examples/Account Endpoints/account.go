@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"gateaway/config"
+)
+
+func main() {
+	// Load config from ./config/.env
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
+
+	account, err := client.GetAccount(ctx, models.AccountRequest{OmitZeroBalances: true})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	for _, balance := range account.Balances {
+		fmt.Println(balance.Asset, balance.Free, balance.Locked)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+	"gateaway/config"
+)
+
+func main() {
+	// Load config from ./config/.env
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
+
+	trades, err := client.GetMyTrades(ctx, models.MyTradesRequest{
+		Symbol: "ETHUSDT",
+		Limit:  10,
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	for _, trade := range *trades {
+		fmt.Println(trade.Id, trade.Price, trade.Qty, trade.Commission, trade.CommissionAsset)
+	}
+}
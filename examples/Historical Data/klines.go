@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/history"
+	v3 "gateaway/binance/v3"
+	"time"
+)
+
+func main() {
+	// Klines do not require auth, trades need an API key
+	client := v3.NewBinanceClient("", "")
+	ctx := context.Background()
+
+	end := time.Now()
+	start := end.AddDate(0, -3, 0)
+
+	// Running it again after a failure continues after the last kline in the file
+	d := history.NewDownloader(client)
+	n, err := d.DownloadKlines(ctx, "BTCUSDT-1m.csv", "BTCUSDT", "1m", start, end)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	fmt.Println("Klines written:", n)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
@@ -8,8 +9,9 @@ import (
 
 func main() {
 	client := v3.NewBinanceClient("", "")
+	ctx := context.Background()
 
-	depth, err := client.GetDepth(models.DepthRequest{
+	depth, err := client.GetDepth(ctx, models.DepthRequest{
 		Symbol: "SOLUSDT",
 	})
 
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 )
 
 func main() {
 	// Endpoint does not require auth
 	client := v3.NewBinanceClient("", "")
+	ctx := context.Background()
 
-	response, err := client.GetExchangeInfo()
+	// Empty request returns all symbols
+	response, err := client.GetExchangeInfo(ctx, models.ExchangeInfoRequest{
+		Symbols: models.StringArray{"BTCUSDT", "ETHUSDT"},
+	})
 
 	if err != nil {
 		fmt.Println(err.Error())
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+)
+
+func main() {
+	// Endpoint does not require auth
+	client := v3.NewBinanceClient("", "")
+	ctx := context.Background()
+
+	klines, err := client.GetKlines(ctx, models.KlinesRequest{
+		Symbol:   "BTCUSDT",
+		Interval: "1h",
+		Limit:    24,
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	for _, k := range *klines {
+		fmt.Println(k.OpenTime, k.Open, k.High, k.Low, k.Close, k.Volume)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	v3 "gateaway/binance/v3"
+)
+
+func main() {
+	// Endpoint does not require auth
+	client := v3.NewBinanceClient("", "")
+	ctx := context.Background()
+
+	tickers, err := client.GetTicker24hr(ctx, models.Ticker24hrRequest{
+		Symbols: models.StringArray{"BTCUSDT", "ETHUSDT"},
+		Type:    "MINI",
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	for _, t := range *tickers {
+		fmt.Println(t.Symbol, t.LastPrice, t.Volume)
+	}
+
+	prices, err := client.GetTickerPrice(ctx, models.TickerRequest{Symbol: "BTCUSDT"})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Println(*prices)
+}
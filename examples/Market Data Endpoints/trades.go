@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
@@ -9,8 +10,9 @@ import (
 func main() {
 
 	client := v3.NewBinanceClient("", "")
+	ctx := context.Background()
 
-	trades, err := client.GetTrades(models.TradesRequest{
+	trades, err := client.GetTrades(ctx, models.TradesRequest{
 		Symbol: "SOLUSDT",
 		Limit:  3,
 	})
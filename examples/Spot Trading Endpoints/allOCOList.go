@@ -1,27 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
-	"time"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// All OCO list
-	canceledOrderList, err := client.AllOCOList(models.AllOCOListRequest{
-		Timestamp: time.Now().UnixMilli(),
-	})
+	canceledOrderList, err := client.AllOCOList(ctx, models.AllOCOListRequest{})
 
 	if err != nil {
 		fmt.Println(err.Error())
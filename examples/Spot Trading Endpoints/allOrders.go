@@ -1,27 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
-	"time"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// Get All Orders
-	cancelReplace, err := client.GetAllOrders(models.AllOpenOrdersRequest{
-		Symbol:    "SOLUSDT",
-		Timestamp: time.Now().UnixMilli(),
+	cancelReplace, err := client.GetAllOrders(ctx, models.AllOpenOrdersRequest{
+		Symbol: "SOLUSDT",
 	})
 
 	if err != nil {
@@ -1,33 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
+
+	"github.com/shopspring/decimal"
 	"time"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// Create limit order
-	order, err := client.NewOrder(models.OrderRequest{
+	order, err := client.NewOrder(ctx, models.OrderRequest{
 		Symbol:      "SOLUSDT",
-		Side:        "BUY",
-		Type:        "LIMIT",
-		Price:       20,
-		Quantity:    1,
+		Side:        models.SideBuy,
+		Type:        models.OrderTypeLimit,
+		Price:       decimal.RequireFromString("20"),
+		Quantity:    decimal.RequireFromString("1"),
 		RecvWindow:  10000,
-		Timestamp:   time.Now().UnixMilli(),
-		TimeInForce: "GTC",
+		TimeInForce: models.TimeInForceGTC,
 	})
 
 	if err != nil {
@@ -38,18 +41,17 @@ func main() {
 	time.Sleep(2 * time.Second)
 
 	// Cancel & Replace order
-	cancelReplace, err := client.CancelReplace(models.CancelReplaceRequest{
+	cancelReplace, err := client.CancelReplace(ctx, models.CancelReplaceRequest{
 		Symbol:             "SOLUSDT",
-		Side:               "BUY",
-		Type:               "LIMIT",
-		CancelReplaceMode:  "STOP_ON_FAILURE",
-		Timestamp:          time.Now().UnixMilli(),
+		Side:               models.SideBuy,
+		Type:               models.OrderTypeLimit,
+		CancelReplaceMode:  models.CancelReplaceStopOnFailure,
 		CancelOrderId:      order.OrderId,
-		Price:              22,
-		Quantity:           1,
+		Price:              decimal.RequireFromString("22"),
+		Quantity:           decimal.RequireFromString("1"),
 		RecvWindow:         10000,
-		TimeInForce:        "GTC",
-		CancelRestrictions: "ONLY_NEW",
+		TimeInForce:        models.TimeInForceGTC,
+		CancelRestrictions: models.CancelOnlyNew,
 	})
 
 	if err != nil {
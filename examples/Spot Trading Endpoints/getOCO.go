@@ -1,34 +1,37 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
+
+	"github.com/shopspring/decimal"
 	"time"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// New OCO
-	stopLimit := 22.5
-	newOCO, err := client.NewOCO(models.NewOCORequest{
+	stopLimit := decimal.RequireFromString("22.5")
+	newOCO, err := client.NewOCO(ctx, models.NewOCORequest{
 		Symbol:               "SOLUSDT",
-		Side:                 "BUY",
-		Price:                20,
-		Quantity:             1,
-		StopPrice:            40,
-		Timestamp:            time.Now().UnixMilli(),
+		Side:                 models.SideBuy,
+		Price:                decimal.RequireFromString("20"),
+		Quantity:             decimal.RequireFromString("1"),
+		StopPrice:            decimal.RequireFromString("40"),
 		StopLimitPrice:       &stopLimit,
-		StopLimitTimeInForce: "GTC",
+		StopLimitTimeInForce: models.TimeInForceGTC,
 	})
 
 	if err != nil {
@@ -39,8 +42,7 @@ func main() {
 	time.Sleep(2 * time.Second)
 
 	// Get OCO
-	canceledOrderList, err := client.GetOCO(models.GetOCORequest{
-		Timestamp:   time.Now().UnixMilli(),
+	canceledOrderList, err := client.GetOCO(ctx, models.GetOCORequest{
 		OrderListID: &newOCO.OrderListId,
 	})
 
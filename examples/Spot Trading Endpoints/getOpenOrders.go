@@ -1,34 +1,34 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
-	"time"
 )
 
 // TODO: Change models to * if omittempty
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// Create limit order
-	//order, err := client.NewOrder(models.OrderRequest{
+	//order, err := client.NewOrder(ctx, models.OrderRequest{
 	//	Symbol:      "SOLUSDT",
-	//	Side:        "BUY",
-	//	Type:        "LIMIT",
-	//	Price:       20,
-	//	Quantity:    1,
+	//	Side:        models.SideBuy,
+	//	Type:        models.OrderTypeLimit,
+	//	Price:       decimal.RequireFromString("20"),
+	//	Quantity:    decimal.RequireFromString("1"),
 	//	RecvWindow:  10000,
-	//	Timestamp:   time.Now().UnixMilli(),
-	//	TimeInForce: "GTC",
+	//	TimeInForce: models.TimeInForceGTC,
 	//})
 	//
 	//if err != nil {
@@ -39,9 +39,8 @@ func main() {
 	//time.Sleep(2 * time.Second)
 
 	// Cancel & Replace order
-	cancelReplace, err := client.GetOpenOrders(models.OpenOrdersRequest{
-		Symbol:    "SOLUSDT",
-		Timestamp: time.Now().UnixMilli(),
+	cancelReplace, err := client.GetOpenOrders(ctx, models.OpenOrdersRequest{
+		Symbol: "SOLUSDT",
 	})
 
 	if err != nil {
@@ -1,33 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
+
+	"github.com/shopspring/decimal"
 	"time"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// Create limit order
-	order, err := client.NewOrder(models.OrderRequest{
+	order, err := client.NewOrder(ctx, models.OrderRequest{
 		Symbol:      "SOLUSDT",
-		Side:        "BUY",
-		Type:        "LIMIT",
-		Price:       20,
-		Quantity:    1,
+		Side:        models.SideBuy,
+		Type:        models.OrderTypeLimit,
+		Price:       decimal.RequireFromString("20"),
+		Quantity:    decimal.RequireFromString("1"),
 		RecvWindow:  10000,
-		Timestamp:   time.Now().UnixMilli(),
-		TimeInForce: "GTC",
+		TimeInForce: models.TimeInForceGTC,
 	})
 
 	if err != nil {
@@ -38,10 +41,9 @@ func main() {
 	time.Sleep(10 * time.Second)
 
 	// Get order
-	orderInfo, err := client.GetOrder(models.GetOrderRequest{
-		Symbol:    "SOLUSDT",
-		OrderID:   order.OrderId,
-		Timestamp: time.Now().UnixMilli(),
+	orderInfo, err := client.GetOrder(ctx, models.GetOrderRequest{
+		Symbol:  "SOLUSDT",
+		OrderID: order.OrderId,
 	})
 
 	if err != nil {
@@ -1,32 +1,34 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
-	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
 	// New SOR
-	newSOR, err := client.NewSOR(models.NewSORRequest{
+	newSOR, err := client.NewSOR(ctx, models.NewSORRequest{
 		Symbol:      "BNBFDUSD",
-		Side:        "BUY",
-		Type:        "LIMIT",
-		Price:       22.5,
-		Quantity:    1,
-		Timestamp:   time.Now().UnixMilli(),
-		TimeInForce: "GTC",
+		Side:        models.SideBuy,
+		Type:        models.OrderTypeLimit,
+		Price:       decimal.RequireFromString("22.5"),
+		Quantity:    decimal.RequireFromString("1"),
+		TimeInForce: models.TimeInForceGTC,
 	})
 
 	if err != nil {
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	"gateaway/binance/oms"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	wsmodels "gateaway/binance/ws/models"
+	"gateaway/config"
+	"os"
+	"os/signal"
+
+	"github.com/shopspring/decimal"
+)
+
+func main() {
+	// Load config from ./config/.env
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	wsClient := ws.NewBinanceWsClient(cfg.APIKey, cfg.SecretKey, ws.WithEnvironment(cfg.Environment))
+
+	// Interrupt by CTRL+C cancels the context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	orders := oms.NewManager()
+	orders.OnUpdate(func(o oms.Order) {
+		fmt.Println(o.ClientOrderID, o.Status, o.ExecutedQty, orders.Position(o.Symbol).Net())
+	})
+
+	// Execution reports drive the orders after the REST response
+	listenKey, _, err := client.StartUserDataStream(ctx)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	err, _ = wsClient.SubscribeUserData(ctx, listenKey, func(e *wsmodels.UserDataEvent) {
+		if e.ExecutionReport != nil {
+			if _, err := orders.ApplyExecutionReport(e.ExecutionReport); err != nil {
+				fmt.Println(err.Error())
+			}
+		}
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	request := models.OrderRequest{
+		Symbol:           "SOLUSDT",
+		Side:             models.SideBuy,
+		Type:             models.OrderTypeLimit,
+		TimeInForce:      models.TimeInForceGTC,
+		Price:            decimal.RequireFromString("20"),
+		Quantity:         decimal.RequireFromString("1"),
+		NewClientOrderID: "example-1",
+	}
+
+	// Order is tracked before it is sent
+	if _, err := orders.Submit(request); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	response, err := client.NewOrder(ctx, request)
+	if err != nil {
+		orders.Reject(request.NewClientOrderID, err)
+		fmt.Println(err.Error())
+		return
+	}
+	orders.ApplyNewOrder(response)
+
+	fmt.Println(orders.OpenOrders("SOLUSDT"))
+
+	<-ctx.Done() // Graceful shutdown closing subscription
+}
@@ -1,30 +1,33 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/models"
 	v3 "gateaway/binance/v3"
 	"gateaway/config"
-	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 func main() {
 	// Load config from ./config/.env
-	apiKey, secretKey, err := config.LoadEnv()
+	cfg, err := config.LoadEnv()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	client := v3.NewBinanceClient(apiKey, secretKey)
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	ctx := context.Background()
 
-	order, err := client.NewOrderTest(models.OrderRequest{
+	order, err := client.NewOrderTest(ctx, models.OrderRequest{
 		Symbol:     "ETHUSDT",
-		Side:       "BUY",
-		Type:       "MARKET",
-		Quantity:   0.1,
+		Side:       models.SideBuy,
+		Type:       models.OrderTypeMarket,
+		Quantity:   decimal.RequireFromString("0.1"),
 		RecvWindow: 10000,
-		Timestamp:  time.Now().UnixMilli()})
+	})
 
 	if err != nil {
 		fmt.Println(err.Error())
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/models"
+	"gateaway/binance/wsapi"
+	"gateaway/config"
+
+	"github.com/shopspring/decimal"
+)
+
+func main() {
+	// Load config from ./config/.env
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := wsapi.NewClient(cfg.APIKey, cfg.SecretKey, wsapi.WithEnvironment(cfg.Environment), wsapi.WithSigner(cfg.Signer))
+	defer client.Close()
+	ctx := context.Background()
+
+	// Requests are sent without waiting for each other, responses are matched by request id
+	place := client.PlaceOrder(ctx, models.OrderRequest{
+		Symbol:           "ETHUSDT",
+		Side:             models.SideBuy,
+		Type:             models.OrderTypeLimit,
+		TimeInForce:      models.TimeInForceGTC,
+		Quantity:         decimal.RequireFromString("0.01"),
+		Price:            decimal.RequireFromString("1000"),
+		NewClientOrderID: "ws-api-example",
+	})
+	account := client.Account(ctx, models.AccountRequest{OmitZeroBalances: true})
+
+	order, err := place.Wait()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(order)
+
+	balances, err := account.Wait()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(balances.Balances)
+
+	canceled, err := client.CancelOrder(ctx, models.OrderCancelRequest{
+		Symbol:            "ETHUSDT",
+		OrigClientOrderID: "ws-api-example",
+	}).Wait()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(canceled)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gateaway/binance/ws"
 	"gateaway/binance/ws/models"
@@ -12,19 +13,19 @@ func main() {
 	// Endpoint does not require auth
 	client := ws.NewBinanceWsClient("", "")
 
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
+	// Interrupt by CTRL+C cancels the context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	dataHandler := func(e *models.DepthEvent) {
 		fmt.Println(e)
 	}
 
-	err, done := client.SubscribeDepth("btcusdt", dataHandler)
-
+	err, _ := client.SubscribeDepth(ctx, "btcusdt", dataHandler)
 	if err != nil {
-		fmt.Errorf(err.Error())
+		fmt.Println(err.Error())
+		return
 	}
 
-	<-interrupt        // Interrupt by CTRL+C
-	done <- struct{}{} // Graceful shutdown closing subscription
+	<-ctx.Done() // Graceful shutdown closing subscription
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/hub"
+	"gateaway/binance/ws"
+	"gateaway/binance/ws/models"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func main() {
+	// Endpoint does not require auth
+	client := ws.NewBinanceWsClient("", "")
+
+	// Interrupt by CTRL+C cancels the context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// One Binance stream feeds every subscriber of the hub
+	trades := hub.NewHub[*models.TradeEvent]()
+	err, _ := client.SubscribeTrade(ctx, "btcusdt", trades.Publish)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fast := trades.Subscribe(hub.Options{Name: "fast", Policy: hub.Disconnect})
+	go fast.Run(ctx, func(e *models.TradeEvent) {
+		fmt.Println("fast", e.Price, e.Quantity)
+	})
+
+	// A slow subscriber only sees the latest trade, it does not delay the fast one
+	slow := trades.Subscribe(hub.Options{Name: "slow", QueueSize: 1, Policy: hub.Conflate})
+	go slow.Run(ctx, func(e *models.TradeEvent) {
+		fmt.Println("slow", e.Price, e.Quantity)
+		time.Sleep(time.Second)
+	})
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done(): // Graceful shutdown closing subscription
+			trades.Close()
+			return
+		case <-ticker.C:
+			for _, s := range trades.Stats() {
+				fmt.Printf("%s: lag %d, dropped %d, conflated %d\n", s.Name, s.Lag, s.Dropped, s.Conflated)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/ws"
+	"gateaway/binance/ws/models"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	// Endpoint does not require auth
+	client := ws.NewBinanceWsClient("", "")
+
+	// Interrupt by CTRL+C cancels the context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	klineHandler := func(e *models.KlineEvent) {
+		fmt.Println(e.Symbol, e.Kline.Interval, e.Kline.Open, e.Kline.Close, e.Kline.IsFinal)
+	}
+
+	err, _ := client.SubscribeKline(ctx, "btcusdt", "1m", klineHandler)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	<-ctx.Done() // Graceful shutdown closing subscription
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gateaway/binance/orderbook"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	// Endpoints do not require auth
+	manager := orderbook.NewManager(v3.NewBinanceClient("", ""), ws.NewBinanceWsClient("", ""))
+
+	// Interrupt by CTRL+C cancels the context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	manager.OnChange(func(b *orderbook.Book) {
+		bid, _ := b.BestBid()
+		ask, _ := b.BestAsk()
+		fmt.Println(b.Symbol, b.LastUpdateID(), bid.Price, ask.Price, b.Asks(5))
+	})
+
+	if _, err := manager.Subscribe(ctx, "BTCUSDT"); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	<-ctx.Done() // Graceful shutdown closing subscriptions
+}
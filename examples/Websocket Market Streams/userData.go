@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	v3 "gateaway/binance/v3"
+	"gateaway/binance/ws"
+	"gateaway/binance/ws/models"
+	"gateaway/config"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	// Load config from ./config/.env
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := v3.NewBinanceClient(cfg.APIKey, cfg.SecretKey, v3.WithEnvironment(cfg.Environment), v3.WithSigner(cfg.Signer))
+	wsClient := ws.NewBinanceWsClient(cfg.APIKey, cfg.SecretKey, ws.WithEnvironment(cfg.Environment))
+
+	// Interrupt by CTRL+C cancels the context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// listenKey is kept alive in background and closed when ctx is cancelled
+	listenKey, _, err := client.StartUserDataStream(ctx)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	dataHandler := func(e *models.UserDataEvent) {
+		if e.ExecutionReport != nil {
+			fmt.Println(e.ExecutionReport.Symbol, e.ExecutionReport.ExecutionType, e.ExecutionReport.Status)
+		}
+	}
+
+	err, _ = wsClient.SubscribeUserData(ctx, listenKey, dataHandler)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	<-ctx.Done() // Graceful shutdown closing subscription
+}
@@ -7,6 +7,8 @@ require github.com/gorilla/websocket v1.5.0
 require (
 	github.com/google/go-querystring v1.1.0
 	github.com/joho/godotenv v1.5.1
+	github.com/rs/zerolog v1.31.0
+	github.com/shopspring/decimal v1.3.1
 )
 
 require (
@@ -21,7 +23,5 @@ require (
 	github.com/muesli/reflow v0.3.0 // indirect
 	github.com/muesli/termenv v0.15.2 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/rs/zerolog v1.31.0 // indirect
-	github.com/shopspring/decimal v1.3.1 // indirect
 	golang.org/x/sys v0.13.0 // indirect
 )